@@ -1,4 +1,3 @@
-require.context("../favicon", true, /.*/);
 require.context("../images", true, /.*/);
 
 require('../css/main.scss');
\ No newline at end of file
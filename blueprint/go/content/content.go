@@ -10,25 +10,31 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/s12chung/gostatic/go/app"
+	"github.com/s12chung/gostatic/go/lib/favicon"
 	"github.com/s12chung/gostatic/go/lib/html"
 	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/urls"
 	"github.com/s12chung/gostatic/go/lib/webpack"
 )
 
 // Content represents contains the logic/routing for the content of your site
 type Content struct {
 	Settings *Settings
+	Profile  app.Profile
 	Log      logrus.FieldLogger
 
 	HTMLRenderer *html.Renderer
 	Webpack      *webpack.Webpack
+	Favicon      *favicon.Favicon
 }
 
-// NewContent returns Content with default config
-func NewContent(generatedPath string, settings *Settings, log logrus.FieldLogger) *Content {
-	w := webpack.NewWebpack(generatedPath, settings.Webpack, log)
-	htmlRenderer := html.NewRenderer(settings.HTML, []html.Plugin{w}, log)
-	return &Content{settings, log, htmlRenderer, w}
+// NewContent returns Content with default config, the profile is the active settings profile (ex. "staging")
+// and the urls give the base path and asset host of the site
+func NewContent(generatedPath string, settings *Settings, profile app.Profile, u *urls.URLs, log logrus.FieldLogger) *Content {
+	w := webpack.NewWebpack(generatedPath, settings.Webpack, u, log)
+	f := favicon.NewFavicon(settings.Favicon, u, log)
+	htmlRenderer := html.NewRenderer(settings.HTML, []html.Plugin{w, f, profile, u}, log)
+	return &Content{settings, profile, log, htmlRenderer, w, f}
 }
 
 // AssetsURL is the URL path prefix of all your assets.
@@ -57,6 +63,7 @@ func (content *Content) SetRoutes(r router.Router, tracker *app.Tracker) error {
 	r.GetRootHTML(content.getRoot)
 	r.GetHTML("/404.html", content.get404)
 	r.Get("/robots.txt", content.getRobots)
+	content.Favicon.SetRoutes(r)
 	return nil
 }
 
@@ -1,6 +1,8 @@
 package content
 
 import (
+	"github.com/s12chung/gostatic/go/lib/assets"
+	"github.com/s12chung/gostatic/go/lib/favicon"
 	"github.com/s12chung/gostatic/go/lib/html"
 	"github.com/s12chung/gostatic/go/lib/webpack"
 )
@@ -12,6 +14,10 @@ import (
 type Settings struct {
 	HTML    *html.Settings    `json:"html,omitempty"`
 	Webpack *webpack.Settings `json:"webpack,omitempty"`
+	// Assets builds the assets in Go instead of Webpack, when its source_path is set
+	Assets *assets.Settings `json:"assets,omitempty"`
+	// Favicon generates the favicons, web manifest and browserconfig from its source_path
+	Favicon *favicon.Settings `json:"favicon,omitempty"`
 }
 
 // DefaultSettings is the default settings of your App, when JSON data is not given
@@ -19,5 +25,13 @@ func DefaultSettings() *Settings {
 	return &Settings{
 		html.DefaultSettings(),
 		webpack.DefaultSettings(),
+		assets.DefaultSettings(),
+		defaultFaviconSettings(),
 	}
 }
+
+func defaultFaviconSettings() *favicon.Settings {
+	settings := favicon.DefaultSettings()
+	settings.SourcePath = "./assets/favicon.png"
+	return settings
+}
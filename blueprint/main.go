@@ -7,6 +7,8 @@ import (
 
 	"github.com/s12chung/gostatic/go/app"
 	"github.com/s12chung/gostatic/go/cli"
+	"github.com/s12chung/gostatic/go/lib/assets"
+	"github.com/s12chung/gostatic/go/lib/urls"
 )
 
 func main() {
@@ -15,10 +17,33 @@ func main() {
 	settings := app.DefaultSettings()
 	contentSettings := content.DefaultSettings()
 	settings.Content = contentSettings
-	app.SettingsFromFile("./settings.json", settings, log)
+	overrides, err := cli.SettingsOverrides(cli.DefaultArgs())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = app.LoadSettings("./settings.json", settings, overrides, log); err != nil {
+		log.Fatal(err)
+	}
+	closeLog, err := app.ConfigureLog(log, settings)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := closeLog(); err != nil {
+			log.Error(err)
+		}
+	}()
 
-	theContent := content.NewContent(settings.GeneratedPath, contentSettings, log)
-	err := cli.RunDefault(app.NewApp(theContent, settings, log))
+	theContent := content.NewContent(settings.GeneratedPath, contentSettings, settings.ActiveProfile(), urls.NewURLs(settings.URLs), log)
+	if err = theContent.Webpack.GenerateImages(); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = assets.NewAssets(theContent.GeneratedAssetsPath(), contentSettings.Assets, log).Build(); err != nil {
+		log.Fatal(err)
+	}
+	theApp := app.NewApp(theContent, settings, log)
+	theApp.RouteAround(theContent.Webpack.CriticalCSSAround)
+	err = cli.RunDefault(theApp)
 	if err != nil {
 		log.Fatal(err)
 		os.Exit(1)
@@ -1,5 +1,7 @@
 module github.com/s12chung/gostatic
 
+go 1.27.1
+
 require (
 	github.com/golang/mock v1.2.0
 	github.com/google/go-cmp v0.2.0
@@ -7,5 +9,21 @@ require (
 	github.com/s12chung/gostatic-packages v0.0.0-20181001003527-6c8d3836483b
 	github.com/sirupsen/logrus v1.3.0
 	github.com/spf13/cobra v0.0.3
+	github.com/srwiley/oksvg v0.0.0-20200311192757-870daf9aa564
+	github.com/srwiley/rasterx v0.0.0-20200120212402-85cb7272f5e9
+	golang.org/x/image v0.25.0
+	golang.org/x/net v0.50.0
+	golang.org/x/sys v0.41.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.3 // indirect
+	github.com/stretchr/objx v0.1.1 // indirect
+	github.com/stretchr/testify v1.2.2 // indirect
+	golang.org/x/crypto v0.48.0 // indirect
+	golang.org/x/term v0.40.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
 )
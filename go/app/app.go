@@ -4,7 +4,9 @@ Package app glues your routes together to generate files concurrently or host th
 package app
 
 import (
+	"io"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -13,7 +15,7 @@ import (
 )
 
 // DefaultLog returns the default log used for the App
-func DefaultLog() logrus.FieldLogger {
+func DefaultLog() *logrus.Logger {
 	return &logrus.Logger{
 		Out: os.Stderr,
 		Formatter: &logrus.TextFormatter{
@@ -34,6 +36,9 @@ type App struct {
 	settings *Settings
 	log      logrus.FieldLogger
 	arounds  []AroundHandler
+	// routeArounds are the router.AroundHandlers of the routes when generating
+	routeArounds []router.AroundHandler
+	out          io.Writer
 }
 
 // NewApp returns a new instance of App
@@ -43,12 +48,14 @@ func NewApp(setter Setter, settings *Settings, log logrus.FieldLogger) *App {
 		settings,
 		log,
 		nil,
+		nil,
+		os.Stdout,
 	}
 }
 
 // RunFileServer runs the server to host the generated files of the static web page
 func (app *App) RunFileServer() error {
-	return router.RunFileServer(app.settings.GeneratedPath, app.settings.FileServerPort, app.log)
+	return router.RunFileServer(app.settings.GeneratedPath, app.basePath(), app.settings.FileServerPort, app.settings.ServerSettings, app.log)
 }
 
 // FileServerPort returns the port of the file server
@@ -63,8 +70,12 @@ func (app *App) GeneratedPath() string {
 
 // Host runs a web application server that computes the route responses in real time
 func (app *App) Host() error {
-	r := router.NewWebRouter(app.settings.ServerPort, app.log)
+	r := router.NewWebRouter(app.settings.ServerPort, app.settings.ServerSettings, app.log)
+	r.SetBasePath(app.basePath())
 	r.FileServe(app.AssetsURL(), app.GeneratedAssetsPath())
+	if app.settings.MetricsURL != "" {
+		r.ServeMetrics(app.settings.MetricsURL)
+	}
 
 	if err := app.SetRoutes(r); err != nil {
 		return err
@@ -72,6 +83,14 @@ func (app *App) Host() error {
 	return r.Run()
 }
 
+// basePath returns the urls.Settings.BasePath, which the servers serve under
+func (app *App) basePath() string {
+	if app.settings.URLs == nil {
+		return ""
+	}
+	return app.settings.URLs.BasePath
+}
+
 // ServerPort returns the port of the web application server
 func (app *App) ServerPort() int {
 	return app.settings.ServerPort
@@ -79,18 +98,38 @@ func (app *App) ServerPort() int {
 
 // Generate generates the static web pages concurrently.
 //
-// Generated concurrently in the batches, in the order given by Setter.URLBatches()
+// Generated concurrently in the batches, in the order given by Setter.URLBatches().
+// Afterwards, a BuildReport is written as JSON and summarized.
 func (app *App) Generate() error {
+	return app.GenerateMatching(nil)
+}
+
+// GenerateMatching generates only the static web pages with URLs that match, leaving the other generated files untouched.
+// All the static web pages are generated if match is nil.
+//
+// Setter.URLBatches() is given all the routes. As batches may depend on the routes of previous batches,
+// the routes of the batches before the last batch with a match are still requested, but not written.
+//
+// If GeneratorSettings.Atomic, the pages are generated into a staging directory, which is swapped
+// with the generated path only if all the routes succeed (see generateAtomic).
+func (app *App) GenerateMatching(match func(url string) bool) error {
 	return callArounds(app.arounds, func() error {
-		if err := utils.MkdirAll(app.settings.GeneratedPath); err != nil {
+		r := router.NewGenerateRouter(app.log)
+		if err := app.SetRoutes(r); err != nil {
 			return err
 		}
+		for _, around := range app.routeArounds {
+			r.Around(around)
+		}
+		if app.settings.GeneratorSettings.Atomic {
+			return app.generateAtomic(r, match)
+		}
 
-		r := router.NewGenerateRouter(app.log)
-		if err := app.SetRoutes(r); err != nil {
+		if err := utils.MkdirAll(app.settings.GeneratedPath); err != nil {
 			return err
 		}
-		return app.requestRoutes(r)
+		_, err := app.requestRoutes(r, match, app.settings.GeneratedPath)
+		return err
 	})
 }
 
@@ -99,20 +138,85 @@ func (app *App) Around(handler func(handler func() error) error) {
 	app.arounds = append(app.arounds, handler)
 }
 
+// RouteAround adds the handler around the routes when generating, not when hosting (ex. post-processing the responses).
+// It's called inside the Around handlers set by Setter.SetRoutes.
+func (app *App) RouteAround(handler router.AroundHandler) {
+	app.routeArounds = append(app.routeArounds, handler)
+}
+
 // Log returns the log of the App
 func (app *App) Log() logrus.FieldLogger {
 	return app.log
 }
 
-func (app *App) requestRoutes(r router.Router) error {
+// requestRoutes writes the route responses into the generatedPath, returning the number of routes that failed
+func (app *App) requestRoutes(r router.Router, match func(url string) bool, generatedPath string) (int, error) {
 	urlBatches, err := app.URLBatches(r)
 	if err != nil {
+		return 0, err
+	}
+
+	generateBatches := urlBatches
+	dependencyBatches := make([][]string, len(urlBatches))
+	if match != nil {
+		generateBatches, dependencyBatches = partialURLBatches(urlBatches, match)
+		app.log.Infof("Partial build, generating %v routes", countURLs(generateBatches))
+	}
+
+	start := time.Now()
+	generator := newGenerator(generatedPath, r.Requester(), app.settings.GeneratorSettings, app.log)
+	for i, urlBatch := range generateBatches {
+		generator.generate(i, urlBatch, dependencyBatches[i])
+	}
+	if err := app.writeReport(newBuildReport(start, generator.reports)); err != nil {
+		return 0, err
+	}
+	return generator.failedCount, app.writeManifestAndClean(generatedPath, generator.writtenFiles(), urlBatches)
+}
+
+// writeManifestAndClean writes the generation manifest and handles the stale files of the previous manifest not in it
+func (app *App) writeManifestAndClean(generatedPath string, writtenFiles map[string]bool, urlBatches [][]string) error {
+	previousFiles, err := readManifest(generatedPath)
+	if err != nil {
+		app.log.Warnf("Not cleaning stale files, error reading the previous generation manifest - %v", err)
+	}
+
+	files := manifestFiles(generatedPath, writtenFiles, urlBatches)
+	if err = writeManifest(generatedPath, files); err != nil {
 		return err
 	}
+	return app.clean(generatedPath, previousFiles, files)
+}
+
+// partialURLBatches splits the URL batches into the URLs that match and the dependency URLs,
+// which don't match and are in the batches before the last batch with a match
+func partialURLBatches(urlBatches [][]string, match func(url string) bool) ([][]string, [][]string) {
+	matchBatches := make([][]string, len(urlBatches))
+	dependencyBatches := make([][]string, len(urlBatches))
+	lastMatchIndex := -1
+	for i, urlBatch := range urlBatches {
+		for _, url := range urlBatch {
+			if match(url) {
+				matchBatches[i] = append(matchBatches[i], url)
+				lastMatchIndex = i
+			} else {
+				dependencyBatches[i] = append(dependencyBatches[i], url)
+			}
+		}
+	}
+
+	matchBatches = matchBatches[:lastMatchIndex+1]
+	dependencyBatches = dependencyBatches[:lastMatchIndex+1]
+	if lastMatchIndex >= 0 {
+		dependencyBatches[lastMatchIndex] = nil
+	}
+	return matchBatches, dependencyBatches
+}
 
-	generator := newGenerator(app.settings.GeneratedPath, r.Requester(), app.settings.GeneratorSettings, app.log)
+func countURLs(urlBatches [][]string) int {
+	count := 0
 	for _, urlBatch := range urlBatches {
-		generator.generate(urlBatch)
+		count += len(urlBatch)
 	}
-	return nil
+	return count
 }
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
@@ -24,7 +25,9 @@ func defaultApp(setter Setter, generatedPath string) (*App, logrus.FieldLogger,
 	settings := DefaultSettings()
 	settings.GeneratedPath = generatedPath
 	log, hook := logTest.NewNullLogger()
-	return NewApp(setter, settings, log), log, hook
+	app := NewApp(setter, settings, log)
+	app.out = ioutil.Discard
+	return app, log, hook
 }
 
 func runGenerate(t *testing.T, setter Setter, callback func(generatedPath string)) {
@@ -41,6 +44,7 @@ func TestApp_Generate(t *testing.T) {
 	defer controller.Finish()
 
 	setter := mocks.NewMockSetter(controller)
+	setter.EXPECT().GeneratedAssetsPath().AnyTimes()
 	setter.EXPECT().SetRoutes(gomock.Any()).Do(func(r router.Router) error {
 		handler := func(ctx router.Context) error {
 			ctx.Respond([]byte(ctx.URL()))
@@ -66,7 +70,7 @@ func TestApp_Generate(t *testing.T) {
 		func(generatedPath string) {
 			var generatedFiles []string
 			err := filepath.Walk(generatedPath, func(path string, info os.FileInfo, err error) error {
-				if info.IsDir() {
+				if info.IsDir() || info.Name() == ManifestFilename {
 					return nil
 				}
 				generatedFiles = append(generatedFiles, path)
@@ -123,6 +127,7 @@ func TestApp_Generate_Order(t *testing.T) {
 		}
 
 		setter := mocks.NewMockSetter(controller)
+		setter.EXPECT().GeneratedAssetsPath().AnyTimes()
 		setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
 			r.GetRootHTML(handler)
 
@@ -186,6 +191,145 @@ func TestApp_Generate_Order(t *testing.T) {
 	}
 }
 
+func TestApp_GenerateMatching(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	var requested []string
+	requestedChan := make(chan string)
+	handler := func(ctx router.Context) error {
+		requestedChan <- ctx.URL()
+		ctx.Respond([]byte(ctx.URL()))
+		return nil
+	}
+
+	setter := mocks.NewMockSetter(controller)
+	setter.EXPECT().GeneratedAssetsPath().AnyTimes()
+	setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
+		r.GetRootHTML(handler)
+		r.GetHTML("/posts/a", handler)
+		r.GetHTML("/posts/b", handler)
+		r.GetHTML("/about", handler)
+		r.Get("/feed.xml", handler)
+		return nil
+	})
+	setter.EXPECT().URLBatches(gomock.Any()).Return([][]string{{"/posts/a", "/posts/b"}, {"/about"}, {router.RootURL, "/feed.xml"}}, nil)
+
+	generatedPath, clean := testfile.SandboxDir(t, "generated")
+	defer clean()
+	test.AssertError(t, os.MkdirAll(generatedPath, 0750), "os.MkdirAll")
+	untouchedPath := filepath.Join(generatedPath, "feed.xml")
+	test.AssertError(t, ioutil.WriteFile(untouchedPath, []byte("untouched"), 0600), "ioutil.WriteFile")
+
+	done := make(chan bool)
+	go func() {
+		for url := range requestedChan {
+			requested = append(requested, url)
+		}
+		done <- true
+	}()
+
+	app, _, _ := defaultApp(setter, generatedPath)
+	test.AssertError(t, app.GenerateMatching(func(url string) bool { return url == "/posts/a" || url == "/about" }), "app.GenerateMatching")
+	close(requestedChan)
+	<-done
+
+	sort.Strings(requested)
+	test.AssertArray(t, "requested", requested, []string{"/about", "/posts/a", "/posts/b"})
+
+	var generatedFiles []string
+	err := filepath.Walk(generatedPath, func(path string, info os.FileInfo, err error) error {
+		if !info.IsDir() {
+			generatedFiles = append(generatedFiles, strings.TrimPrefix(path, generatedPath))
+		}
+		return nil
+	})
+	test.AssertError(t, err, "filepath.Walk")
+	test.AssertArray(t, "generatedFiles", generatedFiles, []string{"/" + ManifestFilename, "/about", "/feed.xml", "/posts/a"})
+
+	bytes, err := ioutil.ReadFile(untouchedPath)
+	test.AssertError(t, err, "ioutil.ReadFile")
+	test.AssertLabel(t, "untouched", string(bytes), "untouched")
+}
+
+func TestPartialURLBatches(t *testing.T) {
+	testCases := []struct {
+		urlBatches    [][]string
+		matches       []string
+		expMatch      [][]string
+		expDependency [][]string
+	}{
+		{[][]string{{"/a", "/b"}}, []string{"/a"}, [][]string{{"/a"}}, [][]string{nil}},
+		{[][]string{{"/a", "/b"}, {"/c"}}, []string{"/c"}, [][]string{nil, {"/c"}}, [][]string{{"/a", "/b"}, nil}},
+		{[][]string{{"/a", "/b"}, {"/c"}}, []string{"/b"}, [][]string{{"/b"}}, [][]string{nil}},
+		{[][]string{{"/a"}, {"/b"}, {"/c"}}, []string{"/a", "/c"}, [][]string{{"/a"}, nil, {"/c"}}, [][]string{nil, {"/b"}, nil}},
+		{[][]string{{"/a"}, {"/b"}}, nil, [][]string{}, [][]string{}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"urlBatches": tc.urlBatches,
+			"matches":    tc.matches,
+		})
+
+		match := func(url string) bool {
+			for _, m := range tc.matches {
+				if url == m {
+					return true
+				}
+			}
+			return false
+		}
+		gotMatch, gotDependency := partialURLBatches(tc.urlBatches, match)
+		context.AssertArray("match", gotMatch, tc.expMatch)
+		context.AssertArray("dependency", gotDependency, tc.expDependency)
+	}
+}
+
+func TestApp_RouteAround(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	setter := mocks.NewMockSetter(controller)
+	setter.EXPECT().GeneratedAssetsPath().AnyTimes()
+	setter.EXPECT().SetRoutes(gomock.Any()).Do(func(r router.Router) error {
+		r.Around(func(ctx router.Context, handler router.ContextHandler) error {
+			err := handler(ctx)
+			ctx.Respond(append(ctx.(router.ResponseContext).Response(), []byte(" setter")...))
+			return err
+		})
+		r.GetRootHTML(func(ctx router.Context) error {
+			ctx.Respond([]byte("root"))
+			return nil
+		})
+		return nil
+	}).AnyTimes()
+	setter.EXPECT().URLBatches(gomock.Any()).DoAndReturn(func(r router.Router) ([][]string, error) {
+		return [][]string{r.URLs()}, nil
+	}).AnyTimes()
+
+	generatedPath, clean := testfile.SandboxDir(t, "generated")
+	defer clean()
+	app, _, _ := defaultApp(setter, generatedPath)
+	app.RouteAround(func(ctx router.Context, handler router.ContextHandler) error {
+		err := handler(ctx)
+		ctx.Respond(append(ctx.(router.ResponseContext).Response(), []byte(" route")...))
+		return err
+	})
+
+	test.AssertError(t, app.Generate(), "app.Generate()")
+	bytes, err := ioutil.ReadFile(filepath.Join(generatedPath, "index.html"))
+	test.AssertError(t, err, "ioutil.ReadFile")
+	test.AssertLabel(t, "generated", string(bytes), "root route setter")
+
+	r := router.NewWebRouter(0, router.DefaultServerSettings(), app.log)
+	test.AssertError(t, app.SetRoutes(r), "app.SetRoutes")
+	response, err := r.MuxRequester().Get(router.RootURL)
+	test.AssertError(t, err, "MuxRequester.Get")
+	test.AssertLabel(t, "hosted", string(response.Body), "root setter")
+}
+
 func TestApp_Around(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -227,6 +371,7 @@ func TestApp_Around(t *testing.T) {
 		})
 
 		setter := mocks.NewMockSetter(controller)
+		setter.EXPECT().GeneratedAssetsPath().AnyTimes()
 		setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
 			got = append(got, "call")
 			return nil
@@ -15,15 +15,15 @@ const LogRouteType = "routes"
 func SetDefaultRouterAroundHandlers(r router.Router) {
 	r.Around(func(ctx router.Context, handler router.ContextHandler) error {
 		ctx.SetLog(ctx.Log().WithFields(logrus.Fields{
-			"type": LogRouteType,
-			"URL":  ctx.URL(),
+			LogFieldType: LogRouteType,
+			LogFieldURL:  ctx.URL(),
 		}))
 		ctx.Log().Infof("Running route")
 
 		var err error
 		start := time.Now()
 		defer func() {
-			log := ctx.Log().WithField("duration", time.Since(start))
+			log := ctx.Log().WithField(LogFieldDuration, time.Since(start))
 
 			ending := " for route"
 			if err != nil {
@@ -31,7 +31,7 @@ func SetDefaultRouterAroundHandlers(r router.Router) {
 				return
 			}
 
-			log.Infof("Success" + ending)
+			log.Info("Success" + ending)
 		}()
 
 		err = handler(ctx)
@@ -83,13 +83,13 @@ func TestSetDefaultRouterAroundHandlers(t *testing.T) {
 		for i, entryTc := range entryTestCases {
 			entry := hook.AllEntries()[i]
 			context.Assert(fmt.Sprintf("Log.Entry[%v].Data", i), len(entry.Data), entryTc.dataLength)
-			context.Assert(fmt.Sprintf("Log.Entry[%v].Data.type", i), entry.Data["type"], LogRouteType)
-			context.Assert(fmt.Sprintf("Log.Entry[%v].Data.URL", i), entry.Data["URL"], url)
+			context.Assert(fmt.Sprintf("Log.Entry[%v].Data.type", i), entry.Data[LogFieldType], LogRouteType)
+			context.Assert(fmt.Sprintf("Log.Entry[%v].Data.url", i), entry.Data[LogFieldURL], url)
 		}
 
 		context.Assert("Log.Entry[1].Message", hook.AllEntries()[1].Message, message)
 
-		_, exists := hook.AllEntries()[2].Data["duration"]
+		_, exists := hook.AllEntries()[2].Data[LogFieldDuration]
 		context.Assert("Log.Entry[2].Data.duration.exists", exists, true)
 	}
 }
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/utils"
+)
+
+const previousBuildTimeFormat = "20060102T150405.000000000"
+
+// StagingPath returns the sibling staging directory of the generatedPath, used when GeneratorSettings.Atomic
+func StagingPath(generatedPath string) string {
+	return filepath.Clean(generatedPath) + ".staging"
+}
+
+// PreviousBuildPaths returns the sibling rollback copies of the generatedPath, from oldest to newest
+func PreviousBuildPaths(generatedPath string) ([]string, error) {
+	paths, err := filepath.Glob(previousBuildPath(generatedPath, "*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func previousBuildPath(generatedPath, suffix string) string {
+	return filepath.Clean(generatedPath) + ".previous-" + suffix
+}
+
+// generateAtomic generates into the staging directory, which starts as a hard-linked (or copied) tree
+// of the generated path, so the existing assets and untouched files of partial builds remain.
+// If all routes succeed, the staging directory is swapped with the generated path (see swapBuild)
+// and the previous build is kept as a rollback copy. Otherwise, the staging directory is removed
+// and the generated path is left as is.
+func (app *App) generateAtomic(r router.Router, match func(url string) bool) error {
+	generatedPath := app.settings.GeneratedPath
+	stagingPath := StagingPath(generatedPath)
+
+	// a staging directory from an interrupted Generate
+	if err := os.RemoveAll(stagingPath); err != nil {
+		return err
+	}
+	if err := linkTree(generatedPath, stagingPath); err != nil {
+		return err
+	}
+
+	failedCount, err := app.requestRoutes(r, match, stagingPath)
+	if err == nil && failedCount > 0 {
+		err = fmt.Errorf("%v routes failed, keeping the current build in %v", failedCount, generatedPath)
+	}
+	if err != nil {
+		if removeErr := os.RemoveAll(stagingPath); removeErr != nil {
+			app.log.Errorf("Error removing staging directory %v - %v", stagingPath, removeErr)
+		}
+		return err
+	}
+	return app.swapBuild(stagingPath)
+}
+
+// swapBuild swaps the stagingPath into the generated path, moving the current build to a previous build.
+//
+// On Linux, the paths are exchanged atomically with renameat2(RENAME_EXCHANGE), so the generated path
+// is always a full build. Otherwise (or if the filesystem doesn't support it), the generated path is renamed
+// to a previous build, then the stagingPath is renamed to the generated path, so there is a brief gap between
+// the renames where the generated path does not exist.
+func (app *App) swapBuild(stagingPath string) error {
+	generatedPath := filepath.Clean(app.settings.GeneratedPath)
+	previousPath := previousBuildPath(generatedPath, time.Now().Format(previousBuildTimeFormat))
+
+	if _, err := os.Stat(generatedPath); os.IsNotExist(err) {
+		if err = os.Rename(stagingPath, generatedPath); err != nil {
+			return err
+		}
+	} else if err = exchangePaths(stagingPath, generatedPath); err == nil {
+		// the stagingPath is now the current build
+		if err = os.Rename(stagingPath, previousPath); err != nil {
+			return err
+		}
+	} else {
+		app.log.Debugf("Swapping builds with renames, atomic exchange failed - %v", err)
+		if err = app.renameBuild(stagingPath, previousPath); err != nil {
+			return err
+		}
+	}
+	app.log.Infof("Swapped new build into %v", generatedPath)
+	return app.prunePreviousBuilds()
+}
+
+// renameBuild renames the generated path to the previousPath, then the stagingPath to the generated path,
+// rolling back the first rename if the second fails
+func (app *App) renameBuild(stagingPath, previousPath string) error {
+	generatedPath := filepath.Clean(app.settings.GeneratedPath)
+	if err := os.Rename(generatedPath, previousPath); err != nil {
+		return err
+	}
+	if err := os.Rename(stagingPath, generatedPath); err != nil {
+		if rollbackErr := os.Rename(previousPath, generatedPath); rollbackErr != nil {
+			app.log.Errorf("Error rolling back to %v - %v", previousPath, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// prunePreviousBuilds removes the oldest previous builds, keeping GeneratorSettings.KeepBuilds
+func (app *App) prunePreviousBuilds() error {
+	paths, err := PreviousBuildPaths(app.settings.GeneratedPath)
+	if err != nil {
+		return err
+	}
+
+	keep := app.settings.GeneratorSettings.KeepBuilds
+	if keep < 0 {
+		keep = 0
+	}
+	for i := 0; i < len(paths)-keep; i++ {
+		app.log.Infof("Removing previous build %v", paths[i])
+		if err := os.RemoveAll(paths[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkTree recreates the directories of src in dst, hard-linking the files, or copying them if linking fails.
+// dst is created empty if src does not exist.
+func linkTree(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return utils.MkdirAll(dst)
+	}
+
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		dstPath := filepath.Join(dst, relativePath)
+
+		if info.IsDir() {
+			return utils.MkdirAll(dstPath)
+		}
+		if err := os.Link(path, dstPath); err != nil {
+			return utils.CopyFile(path, dstPath)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/mocks"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func atomicSetter(controller *gomock.Controller, generatedPath, body string, fail bool) *mocks.MockSetter {
+	handler := func(ctx router.Context) error {
+		if fail && ctx.URL() == "/fail" {
+			return fmt.Errorf("failed")
+		}
+		ctx.Respond([]byte(body))
+		return nil
+	}
+
+	setter := mocks.NewMockSetter(controller)
+	setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
+		r.GetRootHTML(handler)
+		r.GetHTML("/fail", handler)
+		return nil
+	})
+	setter.EXPECT().URLBatches(gomock.Any()).Return([][]string{{router.RootURL, "/fail"}}, nil)
+	setter.EXPECT().GeneratedAssetsPath().Return(filepath.Join(generatedPath, "assets")).AnyTimes()
+	return setter
+}
+
+func readGeneratedFile(t *testing.T, filePath string) string {
+	bytes, err := ioutil.ReadFile(filePath)
+	test.AssertError(t, err, "ioutil.ReadFile")
+	return string(bytes)
+}
+
+func TestApp_Generate_Atomic(t *testing.T) {
+	testCases := []struct {
+		keepBuilds  int
+		builds      int
+		expPrevious []string
+	}{
+		{0, 1, nil},
+		{0, 3, nil},
+		{1, 1, []string{"old"}},
+		{1, 2, []string{"build 0"}},
+		{1, 3, []string{"build 1"}},
+		{2, 3, []string{"build 0", "build 1"}},
+		{5, 3, []string{"old", "build 0", "build 1"}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"keepBuilds": tc.keepBuilds,
+			"builds":     tc.builds,
+		})
+
+		controller := gomock.NewController(t)
+		generatedPath, clean := testfile.SandboxDir(t, "generated")
+		writeGeneratedFiles(t, generatedPath, []string{"index.html", "assets/main.js"})
+
+		for i := 0; i < tc.builds; i++ {
+			app, _, _ := defaultApp(atomicSetter(controller, generatedPath, fmt.Sprintf("build %v", i), false), generatedPath)
+			app.settings.GeneratorSettings.Atomic = true
+			app.settings.GeneratorSettings.KeepBuilds = tc.keepBuilds
+			context.AssertError(app.Generate(), "app.Generate")
+		}
+
+		context.Assert("index.html", readGeneratedFile(t, filepath.Join(generatedPath, "index.html")), fmt.Sprintf("build %v", tc.builds-1))
+		context.Assert("assets/main.js", readGeneratedFile(t, filepath.Join(generatedPath, "assets", "main.js")), "old")
+		_, err := os.Stat(StagingPath(generatedPath))
+		context.Assert("staging exists", os.IsNotExist(err), true)
+
+		previousPaths, err := PreviousBuildPaths(generatedPath)
+		context.AssertError(err, "PreviousBuildPaths")
+		var gotPrevious []string
+		for _, previousPath := range previousPaths {
+			gotPrevious = append(gotPrevious, readGeneratedFile(t, filepath.Join(previousPath, "index.html")))
+			context.Assert("previous assets/main.js", readGeneratedFile(t, filepath.Join(previousPath, "assets", "main.js")), "old")
+		}
+		context.AssertArray("previous builds", gotPrevious, tc.expPrevious)
+
+		clean()
+		controller.Finish()
+	}
+}
+
+func TestApp_Generate_AtomicFailure(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	generatedPath, clean := testfile.SandboxDir(t, "generated")
+	defer clean()
+
+	app, _, _ := defaultApp(atomicSetter(controller, generatedPath, "good", false), generatedPath)
+	app.settings.GeneratorSettings.Atomic = true
+	test.AssertError(t, app.Generate(), "app.Generate")
+
+	// an interrupted Generate
+	writeGeneratedFiles(t, StagingPath(generatedPath), []string{"leftover"})
+
+	app, _, _ = defaultApp(atomicSetter(controller, generatedPath, "bad", true), generatedPath)
+	app.settings.GeneratorSettings.Atomic = true
+	if app.Generate() == nil {
+		t.Error("expected error for failed route")
+	}
+
+	test.AssertLabel(t, "index.html", readGeneratedFile(t, filepath.Join(generatedPath, "index.html")), "good")
+	test.AssertLabel(t, "fail", readGeneratedFile(t, filepath.Join(generatedPath, "fail")), "good")
+	_, err := os.Stat(StagingPath(generatedPath))
+	test.AssertLabel(t, "staging exists", os.IsNotExist(err), true)
+
+	previousPaths, err := PreviousBuildPaths(generatedPath)
+	test.AssertError(t, err, "PreviousBuildPaths")
+	test.AssertLabel(t, "len(previousPaths)", len(previousPaths), 0)
+}
+
+func TestApp_Generate_NotAtomic(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	generatedPath, clean := testfile.SandboxDir(t, "generated")
+	defer clean()
+
+	app, _, _ := defaultApp(atomicSetter(controller, generatedPath, "not atomic", true), generatedPath)
+	test.AssertError(t, app.Generate(), "app.Generate")
+
+	test.AssertLabel(t, "index.html", readGeneratedFile(t, filepath.Join(generatedPath, "index.html")), "not atomic")
+	_, err := os.Stat(StagingPath(generatedPath))
+	test.AssertLabel(t, "staging exists", os.IsNotExist(err), true)
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFilename is the filename of the generation manifest in Settings.GeneratedPath,
+// which lists every file written by Generate
+const ManifestFilename = ".gostatic-manifest.json"
+
+// Clean modes of GeneratorSettings.Clean, for the stale files in Settings.GeneratedPath,
+// which are in the previous generation manifest, but not in the current one (or in Setter.GeneratedAssetsPath)
+const (
+	// CleanRemove removes the stale files
+	CleanRemove = "remove"
+	// CleanReport only logs the stale files
+	CleanReport = "report"
+	// CleanNone ignores the stale files
+	CleanNone = "none"
+)
+
+type manifest struct {
+	Files []string `json:"files"`
+}
+
+func manifestPath(generatedPath string) string {
+	return filepath.Join(generatedPath, ManifestFilename)
+}
+
+func writeManifest(generatedPath string, files map[string]bool) error {
+	m := &manifest{Files: make([]string, 0, len(files))}
+	for file := range files {
+		m.Files = append(m.Files, file)
+	}
+	sort.Strings(m.Files)
+
+	bytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeNewFile(manifestPath(generatedPath), bytes)
+}
+
+// manifestFiles returns the files to put in the manifest: the written files and the existing files
+// of the routes that were not written (not matched by a partial build or errored)
+func manifestFiles(generatedPath string, writtenFiles map[string]bool, urlBatches [][]string) map[string]bool {
+	files := map[string]bool{}
+	for file := range writtenFiles {
+		files[file] = true
+	}
+	for _, urlBatch := range urlBatches {
+		for _, url := range urlBatch {
+			file := generatedRelativePath(url)
+			if files[file] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(generatedPath, file)); err == nil {
+				files[file] = true
+			}
+		}
+	}
+	return files
+}
+
+// readManifest returns the files of the generation manifest in the generatedPath, nil if there is no manifest
+func readManifest(generatedPath string) (map[string]bool, error) {
+	bytes, err := ioutil.ReadFile(manifestPath(generatedPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest{}
+	if err = json.Unmarshal(bytes, m); err != nil {
+		return nil, fmt.Errorf("error parsing %v - %v", manifestPath(generatedPath), err)
+	}
+	files := make(map[string]bool, len(m.Files))
+	for _, file := range m.Files {
+		files[file] = true
+	}
+	return files, nil
+}
+
+// staleFiles returns the files of the previous manifest that are not in the current manifest files
+func staleFiles(previousFiles, files map[string]bool) []string {
+	var stale []string
+	for file := range previousFiles {
+		if !files[file] {
+			stale = append(stale, file)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// clean handles the stale files of the generatedPath given GeneratorSettings.Clean.
+// Only the files written by the previous Generate are stale (listed in its manifest), so files from
+// other sources (ex. CNAME or .well-known/) are never touched. Without a previous manifest, there are none.
+func (app *App) clean(generatedPath string, previousFiles, files map[string]bool) error {
+	mode := app.settings.GeneratorSettings.Clean
+	if mode == CleanNone {
+		return nil
+	}
+
+	assetsPath := app.assetsPathIn(generatedPath)
+	for _, file := range staleFiles(previousFiles, files) {
+		path := filepath.Join(generatedPath, filepath.FromSlash(file))
+		if !isInDir(generatedPath, path) || (assetsPath != "" && isInDir(assetsPath, path)) {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+
+		if mode == CleanReport {
+			app.log.Warnf("Stale file not in the generation manifest: %v", path)
+			continue
+		}
+		app.log.Infof("Removing stale file: %v", path)
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removeEmptyDirs(generatedPath, filepath.Dir(path))
+	}
+	return nil
+}
+
+// isInDir returns true if the path is inside the dir
+func isInDir(dir, path string) bool {
+	relativePath, err := filepath.Rel(dir, path)
+	return err == nil && relativePath != "." && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+// assetsPathIn returns Setter.GeneratedAssetsPath moved from Settings.GeneratedPath to the generatedPath,
+// which differ when generating into a staging directory
+func (app *App) assetsPathIn(generatedPath string) string {
+	assetsPath := app.GeneratedAssetsPath()
+	if assetsPath == "" {
+		return ""
+	}
+	relativePath, err := filepath.Rel(app.settings.GeneratedPath, assetsPath)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return assetsPath
+	}
+	return filepath.Join(generatedPath, relativePath)
+}
+
+// removeEmptyDirs removes dir and its parents while they are empty, stopping at the generatedPath
+func removeEmptyDirs(generatedPath, dir string) {
+	generatedPath = filepath.Clean(generatedPath)
+	for dir = filepath.Clean(dir); dir != generatedPath && strings.HasPrefix(dir, generatedPath); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
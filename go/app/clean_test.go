@@ -0,0 +1,167 @@
+package app
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/utils"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/mocks"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func writeGeneratedFiles(t *testing.T, generatedPath string, files []string) {
+	for _, file := range files {
+		filePath := filepath.Join(generatedPath, file)
+		test.AssertError(t, utils.MkdirAll(filepath.Dir(filePath)), "utils.MkdirAll")
+		test.AssertError(t, utils.WriteFile(filePath, []byte("old")), "utils.WriteFile")
+	}
+}
+
+func walkGeneratedFiles(t *testing.T, generatedPath string) []string {
+	var files []string
+	err := filepath.Walk(generatedPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath := strings.TrimPrefix(path, generatedPath+"/")
+		if info.IsDir() {
+			relativePath += "/"
+		}
+		if path != generatedPath {
+			files = append(files, relativePath)
+		}
+		return nil
+	})
+	test.AssertError(t, err, "filepath.Walk")
+	sort.Strings(files)
+	return files
+}
+
+func writePreviousManifest(t *testing.T, generatedPath string, files []string) {
+	bytes, err := json.Marshal(&manifest{files})
+	test.AssertError(t, err, "json.Marshal")
+	test.AssertError(t, utils.WriteFile(filepath.Join(generatedPath, ManifestFilename), bytes), "utils.WriteFile")
+}
+
+func TestApp_Generate_Clean(t *testing.T) {
+	otherFiles := []string{".well-known/", ".well-known/security.txt", "CNAME"}
+	keptFiles := append(append([]string{ManifestFilename}, otherFiles...), "assets/", "assets/main.js", "blog", "index.html", "kept/", "kept/page")
+	allFiles := append(append([]string{ManifestFilename}, otherFiles...), "assets/", "assets/main.js", "blog", "deleted/", "deleted/deep/", "deleted/deep/page", "index.html", "kept/", "kept/page", "old.html")
+	sort.Strings(keptFiles)
+	sort.Strings(allFiles)
+
+	testCases := []struct {
+		clean            string
+		previousManifest bool
+		exp              []string
+	}{
+		{CleanRemove, true, keptFiles},
+		{"", true, keptFiles},
+		{CleanRemove, false, allFiles},
+		{CleanReport, true, allFiles},
+		{CleanNone, true, allFiles},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":            testCaseIndex,
+			"clean":            tc.clean,
+			"previousManifest": tc.previousManifest,
+		})
+
+		controller := gomock.NewController(t)
+		generatedPath, clean := testfile.SandboxDir(t, "generated")
+		writeGeneratedFiles(t, generatedPath, []string{"old.html", "deleted/deep/page", "kept/page", "assets/main.js", "CNAME", ".well-known/security.txt"})
+		if tc.previousManifest {
+			writePreviousManifest(t, generatedPath, []string{"../outside.html", "assets/main.js", "deleted/deep/page", "index.html", "kept/page", "missing.html", "old.html"})
+		}
+
+		handler := func(ctx router.Context) error {
+			ctx.Respond([]byte(ctx.URL()))
+			return nil
+		}
+		setter := mocks.NewMockSetter(controller)
+		setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
+			r.GetRootHTML(handler)
+			r.GetHTML("/blog", handler)
+			r.GetHTML("/kept/page", handler)
+			return nil
+		})
+		setter.EXPECT().URLBatches(gomock.Any()).Return([][]string{{router.RootURL, "/blog", "/kept/page"}}, nil)
+		setter.EXPECT().GeneratedAssetsPath().Return(filepath.Join(generatedPath, "assets")).AnyTimes()
+
+		app, _, hook := defaultApp(setter, generatedPath)
+		app.settings.GeneratorSettings.Clean = tc.clean
+		context.AssertError(app.Generate(), "app.Generate")
+
+		context.AssertArray("files", walkGeneratedFiles(t, generatedPath), tc.exp)
+		context.Assert("SafeLogEntries", test.SafeLogEntries(hook), tc.clean != CleanReport || !tc.previousManifest)
+
+		bytes, err := ioutil.ReadFile(filepath.Join(generatedPath, ManifestFilename))
+		context.AssertError(err, "ioutil.ReadFile")
+		m := &manifest{}
+		context.AssertError(json.Unmarshal(bytes, m), "json.Unmarshal")
+		context.AssertArray("manifest.Files", m.Files, []string{"blog", "index.html", "kept/page"})
+
+		clean()
+		controller.Finish()
+	}
+}
+
+func TestApp_GenerateMatching_Clean(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	generatedPath, clean := testfile.SandboxDir(t, "generated")
+	defer clean()
+	writeGeneratedFiles(t, generatedPath, []string{"index.html", "old.html", "CNAME"})
+	writePreviousManifest(t, generatedPath, []string{"index.html", "old.html"})
+
+	handler := func(ctx router.Context) error {
+		ctx.Respond([]byte(ctx.URL()))
+		return nil
+	}
+	setter := mocks.NewMockSetter(controller)
+	setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
+		r.GetRootHTML(handler)
+		r.GetHTML("/blog", handler)
+		r.GetHTML("/about", handler)
+		return nil
+	})
+	setter.EXPECT().URLBatches(gomock.Any()).Return([][]string{{router.RootURL, "/blog", "/about"}}, nil)
+	setter.EXPECT().GeneratedAssetsPath().Return(filepath.Join(generatedPath, "assets")).AnyTimes()
+
+	app, _, _ := defaultApp(setter, generatedPath)
+	test.AssertError(t, app.GenerateMatching(func(url string) bool { return url == "/blog" }), "app.GenerateMatching")
+
+	test.AssertArray(t, "files", walkGeneratedFiles(t, generatedPath), []string{ManifestFilename, "CNAME", "blog", "index.html"})
+}
+
+func TestGeneratedRelativePath(t *testing.T) {
+	testCases := []struct {
+		url string
+		exp string
+	}{
+		{router.RootURL, "index.html"},
+		{"/blog", "blog"},
+		{"/fold/deeper/in.txt", "fold/deeper/in.txt"},
+		{"/fold/", "fold/index.html"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"url":   tc.url,
+		})
+		context.Assert("result", generatedRelativePath(tc.url), tc.exp)
+	}
+}
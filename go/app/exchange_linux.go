@@ -0,0 +1,10 @@
+package app
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// exchangePaths atomically exchanges the existing paths with renameat2(RENAME_EXCHANGE)
+func exchangePaths(path1, path2 string) error {
+	return unix.Renameat2(unix.AT_FDCWD, path1, unix.AT_FDCWD, path2, unix.RENAME_EXCHANGE)
+}
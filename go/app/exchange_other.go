@@ -0,0 +1,12 @@
+//go:build !linux
+
+package app
+
+import (
+	"fmt"
+)
+
+// exchangePaths atomically exchanges the existing paths, which is only supported on Linux
+func exchangePaths(path1, path2 string) error {
+	return fmt.Errorf("atomically exchanging paths is not supported on this OS")
+}
@@ -3,7 +3,9 @@ package app
 import (
 	"os"
 	"path"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -20,6 +22,11 @@ type generator struct {
 
 	dirs      map[string]bool
 	dirsMutex *sync.RWMutex
+
+	written      map[string]bool
+	reports      []*RouteReport
+	resultsMutex *sync.Mutex
+	failedCount  int
 }
 
 func newGenerator(generatedPath string, requester router.Requester, settings *GeneratorSettings, log logrus.FieldLogger) *generator {
@@ -30,49 +37,136 @@ func newGenerator(generatedPath string, requester router.Requester, settings *Ge
 		log,
 		map[string]bool{},
 		&sync.RWMutex{},
+		map[string]bool{},
+		nil,
+		&sync.Mutex{},
+		0,
 	}
 }
 
-func (gen *generator) generate(urls []string) {
-	tasks := gen.urlsToTasks(urls)
+// generate writes the responses of the urls of the batch concurrently,
+// with the dependencyURLs requested (but not written) concurrently with them
+func (gen *generator) generate(batch int, urls, dependencyURLs []string) {
+	tasks := gen.urlsToTasks(batch, urls)
+	for _, url := range dependencyURLs {
+		tasks = append(tasks, gen.getDependencyURLTask(batch, url))
+	}
 	gen.runTasks(tasks)
 }
 
-func (gen *generator) urlsToTasks(urls []string) []*pool.Task {
+func (gen *generator) urlsToTasks(batch int, urls []string) []*pool.Task {
 	tasks := make([]*pool.Task, len(urls))
 	for i, url := range urls {
-		tasks[i] = gen.getURLTask(url)
+		tasks[i] = gen.getURLTask(batch, url)
 	}
 	return tasks
 }
 
-func (gen *generator) getURLTask(url string) *pool.Task {
+func (gen *generator) getDependencyURLTask(batch int, url string) *pool.Task {
 	log := gen.log.WithFields(logrus.Fields{
-		"type": "task",
-		"url":  url,
+		LogFieldType: LogTaskType,
+		LogFieldURL:  url,
 	})
 
-	return pool.NewTask(log, func() error {
-		response, err := gen.requester.Get(url)
+	return pool.NewTask(log, func() (err error) {
+		report := &RouteReport{URL: url, Batch: batch, Dependency: true}
+		defer func() { gen.addReport(report, err) }()
+
+		log.Info("Requesting dependency route, not writing it")
+		_, err = gen.request(url, report)
+		return err
+	})
+}
+
+func (gen *generator) getURLTask(batch int, url string) *pool.Task {
+	log := gen.log.WithFields(logrus.Fields{
+		LogFieldType: LogTaskType,
+		LogFieldURL:  url,
+	})
+
+	return pool.NewTask(log, func() (err error) {
+		report := &RouteReport{URL: url, Batch: batch}
+		defer func() { gen.addReport(report, err) }()
+
+		response, err := gen.request(url, report)
 		if err != nil {
 			return err
 		}
 
-		filename := url
-		if url == router.RootURL {
-			filename = "index.html"
-		}
-
-		generatedFilePath := path.Join(gen.generatedPath, filename)
+		generatedFilePath := generatedFilePath(gen.generatedPath, url)
 		if err := gen.generateDirIfNeeded(generatedFilePath); err != nil {
 			return err
 		}
 
 		log.Infof("Writing response into %v", generatedFilePath)
-		return utils.WriteFile(generatedFilePath, response.Body)
+		if err := writeNewFile(generatedFilePath, response.Body); err != nil {
+			return err
+		}
+
+		gen.resultsMutex.Lock()
+		gen.written[generatedRelativePath(url)] = true
+		gen.resultsMutex.Unlock()
+		return nil
 	})
 }
 
+// request gets the response of the url, setting the render duration, size and content type of the report
+func (gen *generator) request(url string, report *RouteReport) (*router.Response, error) {
+	start := time.Now()
+	response, err := gen.requester.Get(url)
+	report.Duration = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	report.Size = len(response.Body)
+	report.ContentType = response.MimeType
+	return response, nil
+}
+
+func (gen *generator) addReport(report *RouteReport, err error) {
+	if err != nil {
+		report.Error = err.Error()
+	}
+	gen.resultsMutex.Lock()
+	gen.reports = append(gen.reports, report)
+	gen.resultsMutex.Unlock()
+}
+
+// writeNewFile replaces the file instead of writing through it,
+// because the file may be hard-linked to a previous build (see generateAtomic)
+func writeNewFile(filePath string, bytes []byte) error {
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return utils.WriteFile(filePath, bytes)
+}
+
+// writtenFiles returns the files written, relative to the generatedPath
+func (gen *generator) writtenFiles() map[string]bool {
+	gen.resultsMutex.Lock()
+	defer gen.resultsMutex.Unlock()
+
+	files := make(map[string]bool, len(gen.written))
+	for file := range gen.written {
+		files[file] = true
+	}
+	return files
+}
+
+// generatedFilePath returns the file path that the response of the url is written to
+func generatedFilePath(generatedPath, url string) string {
+	return path.Join(generatedPath, generatedRelativePath(url))
+}
+
+// generatedRelativePath returns the file path that the response of the url is written to, relative to the generatedPath.
+// URLs ending in "/", such as router.RootURL, are written to the index.html of the directory.
+func generatedRelativePath(url string) string {
+	if strings.HasSuffix(url, "/") {
+		url = path.Join(url, "index.html")
+	}
+	return strings.TrimPrefix(path.Clean(url), "/")
+}
+
 func (gen *generator) generateDirIfNeeded(generatedFilePath string) error {
 	generatedDir := path.Dir(generatedFilePath)
 
@@ -99,6 +193,7 @@ func (gen *generator) runTasks(tasks []*pool.Task) {
 	p := pool.NewPool(tasks, gen.settings.Concurrency)
 	p.Run()
 	p.EachError(func(task *pool.Task) {
+		gen.failedCount++
 		task.Log.Errorf("Error for task - %v", task.Error)
 	})
 }
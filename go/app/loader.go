@@ -0,0 +1,340 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SettingsEnvPrefix is the prefix of the environment variables read by LoadSettings
+const SettingsEnvPrefix = "GOSTATIC_"
+
+// SettingsEnvAliases are the unprefixed environment variables read by LoadSettings when the
+// prefixed environment variable is not set, and by SettingsFromFile, for backward compatibility
+var SettingsEnvAliases = map[string]string{
+	SettingsEnvPrefix + "GENERATED_PATH": "GENERATED_PATH",
+	SettingsEnvPrefix + "ASSETS_PATH":    "ASSETS_PATH",
+}
+
+// LoadSettings loads the settings in layers, each overriding the last:
+//
+//  1. the defaults, already in settings
+//  2. the JSON file at path, if it exists
+//  3. the active profile of the JSON file's "profiles", deep merged
+//  4. environment variables, see SettingsEnvKeys and SettingsEnvAliases
+//  5. overrides, keyed by the dotted JSON path of the setting, ex. "content.html.website_title" (see cli.SettingsOverrides)
+//
+// The active profile is overrides[ProfileKey], then the GOSTATIC_ENV environment variable, then the "profile" of the file.
+//
+// Nested settings, such as Settings.Content, are loaded when they are pointers to structs.
+// Unlike SettingsFromFile, a file that can't be read or parsed is an error, which gives the line and column for malformed JSON.
+// Unknown fields are errors too, then the settings are checked via ValidateSettings.
+func LoadSettings(path string, settings interface{}, overrides map[string]string, log logrus.FieldLogger) error {
+	profile := overrides[ProfileKey]
+	if profile == "" {
+		profile = os.Getenv(SettingsEnvPrefix + "ENV")
+	}
+	if err := loadSettingsFile(path, settings, profile, log); err != nil {
+		return err
+	}
+
+	fields := settingsFields(settings)
+	for _, field := range fields {
+		envKey, value, has := lookupSettingsEnv(field.envKey)
+		if !has {
+			continue
+		}
+		if err := field.set(value); err != nil {
+			return fmt.Errorf("error setting %v from environment - %v", envKey, err)
+		}
+	}
+
+	fieldMap := make(map[string]*settingsField, len(fields))
+	for _, field := range fields {
+		fieldMap[field.key] = field
+	}
+	for key, value := range overrides {
+		field, has := fieldMap[key]
+		if !has {
+			return fmt.Errorf("unknown setting: %v", key)
+		}
+		if err := field.set(value); err != nil {
+			return fmt.Errorf("error setting %v - %v", key, err)
+		}
+	}
+	return ValidateSettings(settings)
+}
+
+// SettingsEnvKeys returns the keys (dotted JSON paths) of the settings mapped to their environment variable.
+// The environment variable is SettingsEnvPrefix and the `env` struct tag of the field, or
+// the JSON path joined by "_" in upper case, ex. GOSTATIC_CONTENT_HTML_WEBSITE_TITLE.
+func SettingsEnvKeys(settings interface{}) map[string]string {
+	envKeys := map[string]string{}
+	for _, field := range settingsFields(settings) {
+		envKeys[field.key] = field.envKey
+	}
+	return envKeys
+}
+
+// lookupSettingsEnv looks up the envKey, then its alias in SettingsEnvAliases, returning the key that was found
+func lookupSettingsEnv(envKey string) (string, string, bool) {
+	if value, has := os.LookupEnv(envKey); has {
+		return envKey, value, true
+	}
+	alias, hasAlias := SettingsEnvAliases[envKey]
+	if !hasAlias {
+		return envKey, "", false
+	}
+	value, has := os.LookupEnv(alias)
+	return alias, value, has
+}
+
+// setSettingsEnvAliases sets the settings of the environment variables of SettingsEnvAliases, logging the errors
+func setSettingsEnvAliases(settings interface{}, log logrus.FieldLogger) {
+	for _, field := range settingsFields(settings) {
+		alias, hasAlias := SettingsEnvAliases[field.envKey]
+		if !hasAlias {
+			continue
+		}
+		value, has := os.LookupEnv(alias)
+		if !has {
+			continue
+		}
+		if err := field.set(value); err != nil {
+			log.Warnf("error setting %v from environment - %v", alias, err)
+		}
+	}
+}
+
+func loadSettingsFile(path string, settings interface{}, profile string, log logrus.FieldLogger) error {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Warnf("%v not found, using defaults...", path)
+			return nil
+		}
+		return err
+	}
+
+	if err := decodeStrict(bytes, settings); err != nil {
+		return jsonError(path, bytes, 0, err)
+	}
+	return loadSettingsProfile(path, bytes, settings, profile)
+}
+
+// loadSettingsProfile unmarshals the profile over the settings, which deep merges it as
+// json.Unmarshal keeps the existing struct fields, pointers and map keys that aren't in the JSON.
+// The inactive profiles are checked too.
+func loadSettingsProfile(path string, bytes []byte, settings interface{}, profile string) error {
+	file := struct {
+		Profile  string                     `json:"profile"`
+		Profiles map[string]json.RawMessage `json:"profiles"`
+	}{}
+	if err := json.Unmarshal(bytes, &file); err != nil {
+		return jsonError(path, bytes, 0, err)
+	}
+
+	if profile == "" {
+		profile = file.Profile
+	}
+	if _, has := file.Profiles[profile]; profile != "" && !has {
+		return fmt.Errorf("settings profile not found in %v: %v", path, profile)
+	}
+
+	// the inactive profiles are decoded into empty settings to check them too
+	names := make([]string, 0, len(file.Profiles))
+	for name := range file.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		target := settings
+		if name != profile {
+			target = emptySettings(reflect.ValueOf(settings)).Interface()
+		}
+		if err := decodeStrict(file.Profiles[name], target); err != nil {
+			return fmt.Errorf("profile %v: %v", name, jsonError(path, bytes, profileOffset(bytes, file.Profiles[name]), err))
+		}
+	}
+	return nil
+}
+
+func profileOffset(bytes, profileBytes []byte) int {
+	offset := strings.Index(string(bytes), string(profileBytes))
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// emptySettings returns empty settings of the same type as value, keeping the types of the nested settings
+// (pointers to structs and interfaces), so decoding into it checks the fields like decoding into value
+func emptySettings(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		empty := reflect.New(value.Type().Elem())
+		empty.Elem().Set(emptySettings(value.Elem()))
+		return empty
+	case reflect.Interface:
+		empty := reflect.New(value.Type()).Elem()
+		if !value.IsNil() {
+			empty.Set(emptySettings(value.Elem()))
+		}
+		return empty
+	case reflect.Struct:
+		empty := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if (field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface) && empty.Field(i).CanSet() {
+				empty.Field(i).Set(emptySettings(field))
+			}
+		}
+		return empty
+	}
+	return reflect.Zero(value.Type())
+}
+
+// trailingDataError is the error for data after the JSON value
+type trailingDataError struct {
+	offset int
+}
+
+func (err *trailingDataError) Error() string {
+	return "invalid data after the JSON value"
+}
+
+var unknownFieldRegex = regexp.MustCompile(`^json: unknown field "(.*)"$`)
+
+// jsonError adds the line and column to JSON errors, bytes[baseOffset:] being the JSON decoded
+func jsonError(path string, bytes []byte, baseOffset int, err error) error {
+	offset := -1
+	switch jsonErr := err.(type) {
+	case *json.SyntaxError:
+		// the offset is after the byte with the error
+		offset = baseOffset + int(jsonErr.Offset) - 1
+	case *json.UnmarshalTypeError:
+		offset = baseOffset + int(jsonErr.Offset) - 1
+	case *trailingDataError:
+		offset = baseOffset + jsonErr.offset
+	default:
+		if match := unknownFieldRegex.FindStringSubmatch(err.Error()); match != nil {
+			offset = keyOffset(bytes, baseOffset, match[1])
+		}
+	}
+	if offset < 0 || offset > len(bytes) {
+		return fmt.Errorf("error parsing %v - %v", path, err)
+	}
+
+	line, column := 1, 1
+	for _, b := range bytes[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+	return fmt.Errorf("error parsing %v at line %v, column %v - %v", path, line, column, err)
+}
+
+// keyOffset returns the offset of the first JSON object key in bytes[baseOffset:], or -1
+func keyOffset(bytes []byte, baseOffset int, key string) int {
+	regex := regexp.MustCompile(`"` + regexp.QuoteMeta(key) + `"\s*:`)
+	location := regex.FindIndex(bytes[baseOffset:])
+	if location == nil {
+		return -1
+	}
+	return baseOffset + location[0]
+}
+
+// decodeStrict decodes the JSON into v, returning an error for fields that are not in v and data after the JSON value
+func decodeStrict(bytes []byte, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(string(bytes)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	rest := string(bytes[decoder.InputOffset():])
+	if trimmed := strings.TrimLeft(rest, " \t\r\n"); trimmed != "" {
+		return &trailingDataError{len(bytes) - len(trimmed)}
+	}
+	return nil
+}
+
+type settingsField struct {
+	key    string
+	envKey string
+	value  reflect.Value
+}
+
+func (field *settingsField) set(value string) error {
+	if field.value.Kind() == reflect.String {
+		field.value.SetString(value)
+		return nil
+	}
+	return json.Unmarshal([]byte(value), field.value.Addr().Interface())
+}
+
+// settingsFields returns the settable fields of the settings, which are not structs
+func settingsFields(settings interface{}) []*settingsField {
+	return appendSettingsFields(nil, reflect.ValueOf(settings), nil)
+}
+
+func appendSettingsFields(fields []*settingsField, value reflect.Value, path []string) []*settingsField {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return fields
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fields
+	}
+
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "-" || structField.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		fieldPath := append(append([]string{}, path...), name)
+
+		fieldValue := value.Field(i)
+		if isNestedSettings(fieldValue) {
+			fields = appendSettingsFields(fields, fieldValue, fieldPath)
+			continue
+		}
+
+		envKey := structField.Tag.Get("env")
+		if envKey == "" {
+			envKey = strings.ToUpper(strings.Join(fieldPath, "_"))
+		}
+		fields = append(fields, &settingsField{strings.Join(fieldPath, "."), SettingsEnvPrefix + envKey, fieldValue})
+	}
+	return fields
+}
+
+func isNestedSettings(value reflect.Value) bool {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value.Kind() == reflect.Ptr && value.Type().Elem().Kind() == reflect.Struct
+		}
+		value = value.Elem()
+	}
+	return value.Kind() == reflect.Struct
+}
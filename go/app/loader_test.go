@@ -0,0 +1,209 @@
+package app
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/html"
+	"github.com/s12chung/gostatic/go/lib/webpack"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+type contentSettings struct {
+	HTML    *html.Settings    `json:"html,omitempty"`
+	Webpack *webpack.Settings `json:"webpack,omitempty"`
+}
+
+func layeredSettings() (*Settings, *contentSettings) {
+	settings := DefaultSettings()
+	content := &contentSettings{html.DefaultSettings(), webpack.DefaultSettings()}
+	settings.Content = content
+	return settings, content
+}
+
+func setEnvs(t *testing.T, envs map[string]string) func() {
+	for key, value := range envs {
+		test.AssertError(t, os.Setenv(key, value), "os.Setenv")
+	}
+	return func() {
+		for key := range envs {
+			test.AssertError(t, os.Unsetenv(key), "os.Unsetenv")
+		}
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	unsetEnvs := setEnvs(t, map[string]string{
+		"GOSTATIC_SERVER_PORT":                "3333",
+		"GOSTATIC_FILE_SERVER_PORT":           "4444",
+		"GOSTATIC_CONTENT_HTML_WEBSITE_TITLE": "Env Title",
+		"GOSTATIC_ASSETS_PATH":                "env_assets",
+		"GOSTATIC_SERVER_SETTINGS_HOST":       "env_host",
+	})
+	defer unsetEnvs()
+
+	settings, content := layeredSettings()
+	log, _ := logTest.NewNullLogger()
+	overrides := map[string]string{
+		"file_server_port":               "5555",
+		"content.html.website_title":     "Flag Title",
+		"generator_settings.atomic":      "true",
+		"server_settings.read_timeout":   "99",
+		"generator_settings.report_path": "flag_report.json",
+	}
+	err := LoadSettings(path.Join(testfile.FixturePath, "layered_settings.json"), settings, overrides, log)
+	test.AssertError(t, err, "LoadSettings")
+
+	// file
+	test.AssertLabel(t, "GeneratedPath", settings.GeneratedPath, "file_path")
+	// env
+	test.AssertLabel(t, "ServerPort", settings.ServerPort, 3333)
+	test.AssertLabel(t, "Webpack.AssetsPath", content.Webpack.AssetsPath, "env_assets")
+	test.AssertLabel(t, "ServerSettings.Host", settings.ServerSettings.Host, "env_host")
+	// flags
+	test.AssertLabel(t, "FileServerPort", settings.FileServerPort, 5555)
+	test.AssertLabel(t, "HTML.WebsiteTitle", content.HTML.WebsiteTitle, "Flag Title")
+	test.AssertLabel(t, "GeneratorSettings.Atomic", settings.GeneratorSettings.Atomic, true)
+	test.AssertLabel(t, "ServerSettings.ReadTimeout", settings.ServerSettings.ReadTimeout, 99)
+	test.AssertLabel(t, "GeneratorSettings.ReportPath", settings.GeneratorSettings.ReportPath, "flag_report.json")
+	// defaults
+	test.AssertLabel(t, "HTML.TemplateExt", content.HTML.TemplateExt, ".gohtml")
+	test.AssertLabel(t, "GeneratorSettings.Concurrency", settings.GeneratorSettings.Concurrency, 10)
+}
+
+func TestLoadSettings_Errors(t *testing.T) {
+	testCases := []struct {
+		path      string
+		overrides map[string]string
+		envs      map[string]string
+		exp       string
+	}{
+		{"does not exist", nil, nil, ""},
+		{"malformed_settings.json", nil, nil, "error parsing testdata/malformed_settings.json at line 4, column 22 - invalid character '3' after object key"},
+		{"type_error_settings.json", nil, nil, "error parsing testdata/type_error_settings.json at line 3, column 23 - json: cannot unmarshal string into Go struct field Settings.server_port of type int"},
+		{"does not exist", map[string]string{"blah": "1"}, nil, "unknown setting: blah"},
+		{"unknown_field_settings.json", nil, nil, `error parsing testdata/unknown_field_settings.json at line 5, column 7 - json: unknown field "website_titel"`},
+		{"unknown_profile_field_settings.json", map[string]string{ProfileKey: "staging"}, nil, `profile staging: error parsing testdata/unknown_profile_field_settings.json at line 5, column 7 - json: unknown field "server_prot"`},
+		{"unknown_profile_field_settings.json", nil, nil, `profile staging: error parsing testdata/unknown_profile_field_settings.json at line 5, column 7 - json: unknown field "server_prot"`},
+		{"inactive_profile_field_settings.json", map[string]string{ProfileKey: "staging"}, nil, `profile production: error parsing testdata/inactive_profile_field_settings.json at line 8, column 7 - json: unknown field "server_prot"`},
+		{"trailing_data_settings.json", nil, nil, "error parsing testdata/trailing_data_settings.json at line 4, column 1 - invalid data after the JSON value"},
+		{"invalid_settings.json", nil, nil, "invalid settings of generator_settings - concurrency must be greater than 0: 0"},
+		{"does not exist", map[string]string{"server_port": "70000"}, nil, "invalid settings - server_port must be between 0 and 65535: 70000"},
+		{"does not exist", map[string]string{"server_port": "abc"}, nil, "error setting server_port - invalid character 'a' looking for beginning of value"},
+		{"does not exist", nil, map[string]string{"GOSTATIC_SERVER_PORT": "abc"}, "error setting GOSTATIC_SERVER_PORT from environment - invalid character 'a' looking for beginning of value"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"path":  tc.path,
+		})
+
+		unsetEnvs := setEnvs(t, tc.envs)
+		settings, _ := layeredSettings()
+		log, _ := logTest.NewNullLogger()
+		err := LoadSettings(path.Join(testfile.FixturePath, tc.path), settings, tc.overrides, log)
+		unsetEnvs()
+
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		context.Assert("error", got, tc.exp)
+	}
+}
+
+func TestLoadSettings_EnvAliases(t *testing.T) {
+	testCases := []struct {
+		envs             map[string]string
+		expGeneratedPath string
+		expAssetsPath    string
+	}{
+		{nil, "./generated", "assets"},
+		{map[string]string{"GENERATED_PATH": "alias_path", "ASSETS_PATH": "alias_assets"}, "alias_path", "alias_assets"},
+		{map[string]string{"GENERATED_PATH": "alias_path", "GOSTATIC_GENERATED_PATH": "env_path"}, "env_path", "assets"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"envs":  tc.envs,
+		})
+
+		unsetEnvs := setEnvs(t, tc.envs)
+		settings, content := layeredSettings()
+		log, _ := logTest.NewNullLogger()
+		err := LoadSettings("does not exist", settings, nil, log)
+		unsetEnvs()
+
+		context.AssertError(err, "LoadSettings")
+		context.Assert("GeneratedPath", settings.GeneratedPath, tc.expGeneratedPath)
+		context.Assert("Webpack.AssetsPath", content.Webpack.AssetsPath, tc.expAssetsPath)
+	}
+}
+
+func TestSettingsEnvKeys(t *testing.T) {
+	settings, _ := layeredSettings()
+	envKeys := SettingsEnvKeys(settings)
+
+	for key, exp := range map[string]string{
+		"generated_path":                 "GOSTATIC_GENERATED_PATH",
+		"generator_settings.concurrency": "GOSTATIC_GENERATOR_SETTINGS_CONCURRENCY",
+		"server_settings.tls.cert_file":  "GOSTATIC_SERVER_SETTINGS_TLS_CERT_FILE",
+		"content.html.website_title":     "GOSTATIC_CONTENT_HTML_WEBSITE_TITLE",
+		"content.webpack.assets_path":    "GOSTATIC_ASSETS_PATH",
+	} {
+		test.AssertLabel(t, key, envKeys[key], exp)
+	}
+}
+
+func TestLoadSettings_Profile(t *testing.T) {
+	testCases := []struct {
+		overrides  map[string]string
+		env        string
+		expProfile string
+		expPath    string
+		expPort    int
+		expTitle   string
+		expHost    string
+		expError   string
+	}{
+		{nil, "", "", "base_path", 1000, "Base Title", "", ""},
+		{map[string]string{ProfileKey: "staging"}, "", "staging", "base_path", 2000, "Staging Title", "", ""},
+		{nil, "production", "production", "production_path", 1000, "Base Title", "0.0.0.0", ""},
+		{map[string]string{ProfileKey: "staging"}, "production", "staging", "base_path", 2000, "Staging Title", "", ""},
+		{map[string]string{ProfileKey: "blah"}, "", "", "", 0, "", "", "settings profile not found in testdata/profile_settings.json: blah"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":     testCaseIndex,
+			"overrides": tc.overrides,
+			"env":       tc.env,
+		})
+
+		unsetEnvs := setEnvs(t, map[string]string{"GOSTATIC_ENV": tc.env})
+		settings, content := layeredSettings()
+		log, _ := logTest.NewNullLogger()
+		err := LoadSettings(path.Join(testfile.FixturePath, "profile_settings.json"), settings, tc.overrides, log)
+		unsetEnvs()
+
+		if tc.expError != "" {
+			context.Assert("error", err.Error(), tc.expError)
+			continue
+		}
+		context.AssertError(err, "LoadSettings")
+		context.Assert("ActiveProfile", settings.ActiveProfile(), Profile(tc.expProfile))
+		context.Assert("GeneratedPath", settings.GeneratedPath, tc.expPath)
+		context.Assert("ServerPort", settings.ServerPort, tc.expPort)
+		context.Assert("HTML.WebsiteTitle", content.HTML.WebsiteTitle, tc.expTitle)
+		context.Assert("ServerSettings.Host", settings.ServerSettings.Host, tc.expHost)
+		// deep merged, not replaced
+		context.Assert("HTML.TemplateExt", content.HTML.TemplateExt, ".base")
+		context.Assert("ServerSettings.ReadTimeout", settings.ServerSettings.ReadTimeout, 10)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log fields set on the logs of routes and generate tasks, kept consistent for structured (JSON) logs
+const (
+	LogFieldType     = "type"
+	LogFieldURL      = "url"
+	LogFieldDuration = "duration"
+)
+
+// LogTaskType is the value set for LogFieldType in the logs of generate tasks
+const LogTaskType = "task"
+
+// Log formats of Settings.LogFormat
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// ConfigureLog configures the log given Settings.LogLevel, Settings.LogFormat and Settings.LogFile.
+// The returned function closes the log file, if any.
+func ConfigureLog(log *logrus.Logger, settings *Settings) (func() error, error) {
+	closeLog := func() error { return nil }
+
+	if settings.LogLevel != "" {
+		level, err := logrus.ParseLevel(settings.LogLevel)
+		if err != nil {
+			return closeLog, err
+		}
+		log.SetLevel(level)
+	}
+
+	toFile := settings.LogFile != ""
+	switch settings.LogFormat {
+	case "", LogFormatText:
+		log.Formatter = &logrus.TextFormatter{
+			ForceColors:   !toFile,
+			DisableColors: toFile,
+		}
+	case LogFormatJSON:
+		log.Formatter = &logrus.JSONFormatter{}
+	default:
+		return closeLog, fmt.Errorf("unknown log format: %v", settings.LogFormat)
+	}
+
+	if toFile {
+		file, err := os.OpenFile(settings.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return closeLog, err
+		}
+		log.Out = file
+		closeLog = file.Close
+	}
+	return closeLog, nil
+}
+
+// SetLogLevel sets the level of the App's log
+func (app *App) SetLogLevel(level logrus.Level) {
+	switch log := app.log.(type) {
+	case *logrus.Logger:
+		log.SetLevel(level)
+	case *logrus.Entry:
+		log.Logger.SetLevel(level)
+	default:
+		app.log.Warnf("Can not set the log level of %T", app.log)
+	}
+}
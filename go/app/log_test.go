@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func TestConfigureLog(t *testing.T) {
+	testCases := []struct {
+		level     string
+		format    string
+		expLevel  logrus.Level
+		expFormat string
+		hasError  bool
+	}{
+		{"", "", logrus.InfoLevel, "*logrus.TextFormatter", false},
+		{"debug", LogFormatText, logrus.DebugLevel, "*logrus.TextFormatter", false},
+		{"warn", LogFormatJSON, logrus.WarnLevel, "*logrus.JSONFormatter", false},
+		{"blah", "", logrus.InfoLevel, "", true},
+		{"", "xml", logrus.InfoLevel, "", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"level":  tc.level,
+			"format": tc.format,
+		})
+
+		log := DefaultLog()
+		settings := DefaultSettings()
+		settings.LogLevel = tc.level
+		settings.LogFormat = tc.format
+
+		closeLog, err := ConfigureLog(log, settings)
+		context.AssertError(closeLog(), "closeLog")
+		if tc.hasError {
+			context.Assert("error", err != nil, true)
+			continue
+		}
+		context.AssertError(err, "ConfigureLog")
+		context.Assert("Level", log.Level, tc.expLevel)
+		context.Assert("Formatter", fmt.Sprintf("%T", log.Formatter), tc.expFormat)
+	}
+}
+
+func TestConfigureLog_File(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "logs")
+	defer clean()
+	test.AssertError(t, os.MkdirAll(dir, 0750), "os.MkdirAll")
+
+	log := DefaultLog()
+	settings := DefaultSettings()
+	settings.LogFormat = LogFormatJSON
+	settings.LogFile = filepath.Join(dir, "app.log")
+
+	closeLog, err := ConfigureLog(log, settings)
+	test.AssertError(t, err, "ConfigureLog")
+	log.WithFields(logrus.Fields{LogFieldType: LogRouteType, LogFieldURL: "/"}).Info("logged")
+	test.AssertError(t, closeLog(), "closeLog")
+
+	bytes, err := ioutil.ReadFile(settings.LogFile)
+	test.AssertError(t, err, "ioutil.ReadFile")
+	entry := map[string]interface{}{}
+	test.AssertError(t, json.Unmarshal([]byte(strings.TrimSpace(string(bytes))), &entry), "json.Unmarshal")
+	test.AssertLabel(t, "msg", entry["msg"], "logged")
+	test.AssertLabel(t, "type", entry[LogFieldType], LogRouteType)
+	test.AssertLabel(t, "url", entry[LogFieldURL], "/")
+}
+
+func TestApp_SetLogLevel(t *testing.T) {
+	log, _ := logTest.NewNullLogger()
+	testCases := []struct {
+		log logrus.FieldLogger
+	}{
+		{log},
+		{log.WithField("a", "b")},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+		})
+
+		log.SetLevel(logrus.InfoLevel)
+		app := NewApp(nil, DefaultSettings(), tc.log)
+		app.SetLogLevel(logrus.DebugLevel)
+		context.Assert("Level", log.Level, logrus.DebugLevel)
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"html/template"
+)
+
+// ProfileKey is the key of Settings.Profile, for settings overrides (cli.SettingsOverrides gives it for -env)
+const ProfileKey = "profile"
+
+// Profile is the name of the active settings profile (see LoadSettings), ex. "staging" or "production".
+// Use it in handlers and add it as a html.Plugin for templates.
+type Profile string
+
+// Is returns true if the profile is any of the names
+func (profile Profile) Is(names ...string) bool {
+	for _, name := range names {
+		if string(profile) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateFuncs returns the template functions for the profile, for html.Plugin
+func (profile Profile) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"profile":   func() string { return string(profile) },
+		"isProfile": profile.Is,
+	}
+}
+
+// ActiveProfile returns the active settings profile
+func (settings *Settings) ActiveProfile() Profile {
+	return Profile(settings.Profile)
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestProfile_TemplateFuncs(t *testing.T) {
+	testCases := []struct {
+		profile Profile
+		exp     string
+	}{
+		{"", ":"},
+		{"staging", "staging:noindex"},
+		{"preview", "preview:noindex"},
+		{"production", "production:"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":   testCaseIndex,
+			"profile": tc.profile,
+		})
+
+		tmpl, err := template.New("").Funcs(tc.profile.TemplateFuncs()).Parse(`{{profile}}:{{if isProfile "staging" "preview"}}noindex{{end}}`)
+		context.AssertError(err, "template.Parse")
+		buffer := &bytes.Buffer{}
+		context.AssertError(tmpl.Execute(buffer, nil), "tmpl.Execute")
+		context.Assert("result", buffer.String(), tc.exp)
+	}
+}
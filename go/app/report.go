@@ -0,0 +1,165 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/utils"
+)
+
+// SlowestRoutesCount is the number of routes in BuildReport.Slowest
+const SlowestRoutesCount = 10
+
+// RouteReport is the report of a route requested by Generate
+type RouteReport struct {
+	URL   string `json:"url"`
+	Batch int    `json:"batch"`
+	// Dependency is true for routes that are requested, but not written, by partial builds
+	Dependency bool `json:"dependency,omitempty"`
+	// Duration is the time to render the route response
+	Duration    time.Duration `json:"duration_ns"`
+	Size        int           `json:"size"`
+	ContentType string        `json:"content_type,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// BuildReport is the report of Generate, with the totals and the RouteReport of each route
+type BuildReport struct {
+	Start       time.Time      `json:"start"`
+	Duration    time.Duration  `json:"duration_ns"`
+	RouteCount  int            `json:"route_count"`
+	FailedCount int            `json:"failed_count"`
+	TotalSize   int            `json:"total_size"`
+	Slowest     []*RouteReport `json:"slowest"`
+	Routes      []*RouteReport `json:"routes"`
+}
+
+// BuildReportPath returns the default path of the JSON BuildReport, a sibling of the generatedPath
+func BuildReportPath(generatedPath string) string {
+	return filepath.Clean(generatedPath) + ".report.json"
+}
+
+func newBuildReport(start time.Time, routes []*RouteReport) *BuildReport {
+	report := &BuildReport{
+		Start:    start,
+		Duration: time.Since(start),
+		Routes:   append([]*RouteReport{}, routes...),
+	}
+	sort.Slice(report.Routes, func(i, j int) bool {
+		a, b := report.Routes[i], report.Routes[j]
+		if a.Batch != b.Batch {
+			return a.Batch < b.Batch
+		}
+		return a.URL < b.URL
+	})
+
+	for _, route := range report.Routes {
+		report.RouteCount++
+		report.TotalSize += route.Size
+		if route.Error != "" {
+			report.FailedCount++
+		}
+	}
+
+	report.Slowest = append([]*RouteReport{}, report.Routes...)
+	sort.SliceStable(report.Slowest, func(i, j int) bool {
+		return report.Slowest[i].Duration > report.Slowest[j].Duration
+	})
+	if len(report.Slowest) > SlowestRoutesCount {
+		report.Slowest = report.Slowest[:SlowestRoutesCount]
+	}
+	return report
+}
+
+// WriteJSON writes the report as JSON to the file path
+func (report *BuildReport) WriteJSON(filePath string) error {
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFile(filePath, bytes)
+}
+
+// WriteSummary writes a human readable summary of the report to w
+func (report *BuildReport) WriteSummary(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	lines := []string{
+		fmt.Sprintf("Build report: %v routes, %v failed, %v bytes in %v", report.RouteCount, report.FailedCount, report.TotalSize, report.Duration),
+		"",
+		"SLOWEST\tDURATION\tSIZE\tBATCH",
+	}
+	for _, route := range report.Slowest {
+		lines = append(lines, fmt.Sprintf("%v\t%v\t%v\t%v", route.URL, route.Duration, route.Size, route.Batch))
+	}
+	if report.FailedCount > 0 {
+		// the empty line ends the tabwriter columns of the slowest table
+		lines = append(lines, "", "FAILED\tBATCH\tERROR")
+		for _, route := range report.failedRoutes() {
+			lines = append(lines, fmt.Sprintf("%v\t%v\t%v", route.URL, route.Batch, route.Error))
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(tw, line); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// LogSummary logs the summary of the report as fields, for structured logs
+func (report *BuildReport) LogSummary(log logrus.FieldLogger) {
+	failedURLs := []string{}
+	for _, route := range report.failedRoutes() {
+		failedURLs = append(failedURLs, route.URL)
+	}
+	slowestURLs := make([]string, len(report.Slowest))
+	for i, route := range report.Slowest {
+		slowestURLs[i] = route.URL
+	}
+
+	log.WithFields(logrus.Fields{
+		"route_count":  report.RouteCount,
+		"failed_count": report.FailedCount,
+		"total_size":   report.TotalSize,
+		"duration_ns":  report.Duration,
+		"slowest":      slowestURLs,
+		"failed":       failedURLs,
+	}).Info("Build report")
+}
+
+func (report *BuildReport) failedRoutes() []*RouteReport {
+	var routes []*RouteReport
+	for _, route := range report.Routes {
+		if route.Error != "" {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// writeReport writes the report as JSON to GeneratorSettings.ReportPath (or BuildReportPath) and the summary to the output,
+// or to the log if Settings.LogFormat is LogFormatJSON
+func (app *App) writeReport(report *BuildReport) error {
+	reportPath := app.settings.GeneratorSettings.ReportPath
+	if reportPath == "" {
+		reportPath = BuildReportPath(app.settings.GeneratedPath)
+	}
+	if err := report.WriteJSON(reportPath); err != nil {
+		return err
+	}
+	app.log.Infof("Build report written to %v", reportPath)
+
+	if app.settings.LogFormat == LogFormatJSON {
+		report.LogSummary(app.log)
+		return nil
+	}
+	return report.WriteSummary(app.out)
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/mocks"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func TestNewBuildReport(t *testing.T) {
+	var routes []*RouteReport
+	for i := 0; i < SlowestRoutesCount+2; i++ {
+		routes = append(routes, &RouteReport{URL: fmt.Sprintf("/%02d", i), Batch: i % 2, Duration: time.Duration(i), Size: 10})
+	}
+	routes[3].Error = "failed"
+
+	report := newBuildReport(time.Now(), routes)
+	test.AssertLabel(t, "RouteCount", report.RouteCount, SlowestRoutesCount+2)
+	test.AssertLabel(t, "FailedCount", report.FailedCount, 1)
+	test.AssertLabel(t, "TotalSize", report.TotalSize, (SlowestRoutesCount+2)*10)
+	test.AssertLabel(t, "len(Slowest)", len(report.Slowest), SlowestRoutesCount)
+	test.AssertLabel(t, "Slowest[0]", report.Slowest[0].URL, "/11")
+	test.AssertLabel(t, "Slowest[last]", report.Slowest[SlowestRoutesCount-1].URL, "/02")
+	test.AssertLabel(t, "Routes[0]", report.Routes[0].URL, "/00")
+	test.AssertLabel(t, "Routes[1]", report.Routes[1].URL, "/02")
+	test.AssertLabel(t, "Routes[last]", report.Routes[len(report.Routes)-1].URL, "/11")
+}
+
+func TestBuildReport_WriteSummary(t *testing.T) {
+	report := newBuildReport(time.Now(), []*RouteReport{
+		{URL: "/fast", Duration: time.Millisecond, Size: 5},
+		{URL: "/slow", Batch: 1, Duration: time.Second, Size: 7},
+		{URL: "/failed", Duration: time.Microsecond, Error: "bad route"},
+	})
+
+	buffer := &bytes.Buffer{}
+	test.AssertError(t, report.WriteSummary(buffer), "report.WriteSummary")
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+
+	test.AssertLabel(t, "len(lines)", len(lines), 9)
+	test.AssertLabel(t, "totals", strings.HasPrefix(lines[0], "Build report: 3 routes, 1 failed, 12 bytes in "), true)
+	test.AssertArray(t, "slowest", strings.Fields(lines[3]), []string{"/slow", "1s", "7", "1"})
+	test.AssertArray(t, "failed header", strings.Fields(lines[7]), []string{"FAILED", "BATCH", "ERROR"})
+	test.AssertArray(t, "failed", strings.Fields(lines[8]), []string{"/failed", "0", "bad", "route"})
+	test.AssertLabel(t, "failed columns", strings.Index(lines[8], "0"), strings.Index(lines[7], "BATCH"))
+}
+
+func TestBuildReport_LogSummary(t *testing.T) {
+	report := newBuildReport(time.Now(), []*RouteReport{
+		{URL: "/fast", Duration: time.Millisecond, Size: 5},
+		{URL: "/failed", Duration: time.Microsecond, Error: "bad route"},
+	})
+
+	log, hook := logTest.NewNullLogger()
+	report.LogSummary(log)
+
+	test.AssertLabel(t, "len(entries)", len(hook.AllEntries()), 1)
+	entry := hook.LastEntry()
+	test.AssertLabel(t, "Message", entry.Message, "Build report")
+	test.AssertLabel(t, "route_count", entry.Data["route_count"], 2)
+	test.AssertLabel(t, "failed_count", entry.Data["failed_count"], 1)
+	test.AssertLabel(t, "total_size", entry.Data["total_size"], 5)
+	test.AssertArray(t, "slowest", entry.Data["slowest"], []string{"/fast", "/failed"})
+	test.AssertArray(t, "failed", entry.Data["failed"], []string{"/failed"})
+}
+
+func TestApp_Generate_Report(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	generatedPath, clean := testfile.SandboxDir(t, "generated")
+	defer clean()
+
+	handler := func(ctx router.Context) error {
+		if ctx.URL() == "/failed" {
+			return fmt.Errorf("bad route")
+		}
+		ctx.Respond([]byte(ctx.URL()))
+		return nil
+	}
+	setter := mocks.NewMockSetter(controller)
+	setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
+		r.GetRootHTML(handler)
+		r.GetHTML("/failed", handler)
+		r.Get("/feed.xml", handler)
+		return nil
+	})
+	setter.EXPECT().URLBatches(gomock.Any()).Return([][]string{{router.RootURL, "/failed"}, {"/feed.xml"}}, nil)
+	setter.EXPECT().GeneratedAssetsPath().AnyTimes()
+
+	app, _, hook := defaultApp(setter, generatedPath)
+	buffer := &bytes.Buffer{}
+	app.out = buffer
+	test.AssertError(t, app.Generate(), "app.Generate")
+
+	bytes, err := ioutil.ReadFile(BuildReportPath(generatedPath))
+	test.AssertError(t, err, "ioutil.ReadFile")
+	report := &BuildReport{}
+	test.AssertError(t, json.Unmarshal(bytes, report), "json.Unmarshal")
+
+	test.AssertLabel(t, "RouteCount", report.RouteCount, 3)
+	test.AssertLabel(t, "FailedCount", report.FailedCount, 1)
+	test.AssertLabel(t, "TotalSize", report.TotalSize, len("/")+len("/feed.xml"))
+
+	html := "text/html; charset=utf-8"
+	for i, exp := range []*RouteReport{
+		{URL: "/", Batch: 0, Size: 1, ContentType: html},
+		{URL: "/failed", Batch: 0, Error: "bad route"},
+		{URL: "/feed.xml", Batch: 1, Size: len("/feed.xml"), ContentType: "text/xml; charset=utf-8"},
+	} {
+		got := *report.Routes[i]
+		got.Duration = 0
+		test.AssertLabel(t, fmt.Sprintf("Routes[%v]", i), got, *exp)
+	}
+	test.AssertLabel(t, "summary", strings.HasPrefix(buffer.String(), "Build report: 3 routes, 1 failed"), true)
+
+	buffer.Reset()
+	app.settings.LogFormat = LogFormatJSON
+	test.AssertError(t, app.writeReport(report), "app.writeReport")
+	test.AssertLabel(t, "json summary output", buffer.String(), "")
+	test.AssertArray(t, "json summary log", hook.LastEntry().Data["failed"], []string{"/failed"})
+}
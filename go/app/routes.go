@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+)
+
+// NoBatch is the Route.Batch of routes that are not in any of the Setter.URLBatches, so they are not generated
+const NoBatch = -1
+
+// Route represents a route set by Setter.SetRoutes
+type Route struct {
+	URL string `json:"url"`
+	// ContentType is the default Content-Type of the route, handlers may change it via router.Context
+	ContentType string `json:"content_type"`
+	// Batch is the index of the batch in Setter.URLBatches, or NoBatch
+	Batch    int    `json:"batch"`
+	FilePath string `json:"file_path"`
+}
+
+// Routes returns the routes set by Setter.SetRoutes sorted by batch, then URL
+func (app *App) Routes() ([]*Route, error) {
+	r := router.NewGenerateRouter(app.log)
+	if err := app.SetRoutes(r); err != nil {
+		return nil, err
+	}
+	urlBatches, err := app.URLBatches(r)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := map[string]int{}
+	for index, urlBatch := range urlBatches {
+		for _, url := range urlBatch {
+			batches[url] = index
+		}
+	}
+
+	var routes []*Route
+	for _, url := range r.URLs() {
+		batch, has := batches[url]
+		if !has {
+			batch = NoBatch
+		}
+		routes = append(routes, &Route{url, r.ContentType(url), batch, generatedFilePath(app.settings.GeneratedPath, url)})
+	}
+	sortRoutes(routes)
+	return routes, nil
+}
+
+func sortRoutes(routes []*Route) {
+	sort.Slice(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.Batch != b.Batch {
+			if a.Batch == NoBatch || b.Batch == NoBatch {
+				return b.Batch == NoBatch
+			}
+			return a.Batch < b.Batch
+		}
+		return a.URL < b.URL
+	})
+}
+
+// PrintRoutes prints the Routes to w in the given format, "table" or "json"
+func (app *App) PrintRoutes(w io.Writer, format string) error {
+	routes, err := app.Routes()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "", "table":
+		return printRoutesTable(w, routes)
+	case "json":
+		return printJSON(w, routes)
+	}
+	return fmt.Errorf("unknown routes format: %v", format)
+}
+
+func printRoutesTable(w io.Writer, routes []*Route) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "URL\tCONTENT TYPE\tBATCH\tFILE PATH"); err != nil {
+		return err
+	}
+	for _, route := range routes {
+		batch := strconv.Itoa(route.Batch)
+		if route.Batch == NoBatch {
+			batch = "-"
+		}
+		if _, err := fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", route.URL, route.ContentType, batch, route.FilePath); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/mocks"
+)
+
+func routesSetter(controller *gomock.Controller) *mocks.MockSetter {
+	handler := func(ctx router.Context) error {
+		return nil
+	}
+
+	setter := mocks.NewMockSetter(controller)
+	setter.EXPECT().SetRoutes(gomock.Any()).DoAndReturn(func(r router.Router) error {
+		r.GetRootHTML(handler)
+		r.GetHTML("/blog", handler)
+		r.GetHTML("/about", handler)
+		r.Get("/feed.xml", handler)
+		r.GetHTML("/drafts", handler)
+		return nil
+	})
+	setter.EXPECT().URLBatches(gomock.Any()).Return([][]string{{"/blog", router.RootURL, "/about"}, {"/feed.xml"}}, nil)
+	return setter
+}
+
+func TestApp_Routes(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	app, _, _ := defaultApp(routesSetter(controller), "generated")
+	got, err := app.Routes()
+	test.AssertError(t, err, "app.Routes")
+
+	html := "text/html; charset=utf-8"
+	exp := []*Route{
+		{router.RootURL, html, 0, path.Join("generated", "index.html")},
+		{"/about", html, 0, path.Join("generated", "about")},
+		{"/blog", html, 0, path.Join("generated", "blog")},
+		{"/feed.xml", "text/xml; charset=utf-8", 1, path.Join("generated", "feed.xml")},
+		{"/drafts", html, NoBatch, path.Join("generated", "drafts")},
+	}
+	test.AssertArray(t, "Routes", got, exp)
+}
+
+func TestApp_PrintRoutes(t *testing.T) {
+	testCases := []struct {
+		format   string
+		hasError bool
+	}{
+		{"", false},
+		{"table", false},
+		{"json", false},
+		{"yaml", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"format": tc.format,
+		})
+
+		controller := gomock.NewController(t)
+		app, _, _ := defaultApp(routesSetter(controller), "generated")
+
+		buffer := &bytes.Buffer{}
+		err := app.PrintRoutes(buffer, tc.format)
+		controller.Finish()
+		if tc.hasError {
+			context.Assert("error", err != nil, true)
+			continue
+		}
+		context.AssertError(err, "app.PrintRoutes")
+
+		if tc.format == "json" {
+			var routes []*Route
+			context.AssertError(json.Unmarshal(buffer.Bytes(), &routes), "json.Unmarshal")
+			context.Assert("len(routes)", len(routes), 5)
+			continue
+		}
+
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		context.Assert("len(lines)", len(lines), 6)
+		context.Assert("header", strings.Fields(lines[0])[0], "URL")
+		context.Assert("unbatched", strings.Fields(lines[5])[3], "-")
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// SettingsSchemaURL is the JSON Schema draft of SettingsSchema
+const SettingsSchemaURL = "http://json-schema.org/draft-07/schema#"
+
+// SettingsSchema returns a JSON Schema of the settings generated from the types and JSON tags, with the
+// values of settings as the defaults. Fields holding interface{}, such as Settings.Content, use the type of their value.
+// Maps with the `schema:"root"` tag, such as Settings.Profiles, have values with the root schema.
+func SettingsSchema(settings interface{}) map[string]interface{} {
+	schema := valueSchema(reflect.ValueOf(settings), reflect.TypeOf(settings))
+	schema["$schema"] = SettingsSchemaURL
+	return schema
+}
+
+func valueSchema(value reflect.Value, valueType reflect.Type) map[string]interface{} {
+	switch valueType.Kind() {
+	case reflect.Interface:
+		if value.IsValid() && !value.IsNil() {
+			return valueSchema(value.Elem(), value.Elem().Type())
+		}
+		return map[string]interface{}{}
+	case reflect.Ptr:
+		if value.IsValid() && !value.IsNil() {
+			return valueSchema(value.Elem(), valueType.Elem())
+		}
+		return valueSchema(reflect.Value{}, valueType.Elem())
+	case reflect.Struct:
+		return structSchema(value, valueType)
+	case reflect.Slice, reflect.Array:
+		return withDefault(map[string]interface{}{"type": "array", "items": valueSchema(reflect.Value{}, valueType.Elem())}, value)
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": valueSchema(reflect.Value{}, valueType.Elem())}
+	case reflect.String:
+		return withDefault(map[string]interface{}{"type": "string"}, value)
+	case reflect.Bool:
+		return withDefault(map[string]interface{}{"type": "boolean"}, value)
+	case reflect.Float32, reflect.Float64:
+		return withDefault(map[string]interface{}{"type": "number"}, value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return withDefault(map[string]interface{}{"type": "integer"}, value)
+	}
+	return map[string]interface{}{}
+}
+
+func structSchema(value reflect.Value, valueType reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "-" || structField.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+
+		if structField.Tag.Get("schema") == "root" {
+			properties[name] = map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"$ref": "#"}}
+			continue
+		}
+
+		var fieldValue reflect.Value
+		if value.IsValid() {
+			fieldValue = value.Field(i)
+		}
+		properties[name] = valueSchema(fieldValue, structField.Type)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func withDefault(schema map[string]interface{}, value reflect.Value) map[string]interface{} {
+	if value.IsValid() && !(value.Kind() == reflect.Slice && value.IsNil()) {
+		schema["default"] = value.Interface()
+	}
+	return schema
+}
+
+// PrintSettings prints the effective settings as JSON to w
+func (app *App) PrintSettings(w io.Writer) error {
+	return printJSON(w, app.settings)
+}
+
+// PrintSettingsSchema prints the JSON Schema of the settings to w, see SettingsSchema
+func (app *App) PrintSettingsSchema(w io.Writer) error {
+	return printJSON(w, SettingsSchema(app.settings))
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/lib/html"
+	"github.com/s12chung/gostatic/go/lib/webpack"
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func schemaAt(t *testing.T, schema map[string]interface{}, path ...string) map[string]interface{} {
+	for _, name := range path {
+		properties, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("no properties for %v", name)
+		}
+		schema, ok = properties[name].(map[string]interface{})
+		if !ok {
+			t.Fatalf("no property %v", name)
+		}
+	}
+	return schema
+}
+
+func TestSettingsSchema(t *testing.T) {
+	settings := DefaultSettings()
+	settings.Content = &contentSettings{html.DefaultSettings(), webpack.DefaultSettings()}
+	schema := SettingsSchema(settings)
+
+	test.AssertLabel(t, "$schema", schema["$schema"], SettingsSchemaURL)
+	test.AssertLabel(t, "additionalProperties", schema["additionalProperties"], false)
+
+	testCases := []struct {
+		path       []string
+		expType    string
+		expDefault interface{}
+	}{
+		{[]string{"generated_path"}, "string", settings.GeneratedPath},
+		{[]string{"server_port"}, "integer", 8080},
+		{[]string{"generator_settings", "atomic"}, "boolean", false},
+		{[]string{"generator_settings"}, "object", nil},
+		{[]string{"server_settings", "tls", "hosts"}, "array", []string{"localhost", "127.0.0.1", "::1"}},
+		{[]string{"content", "html", "website_title"}, "string", "Your Website Title"},
+		{[]string{"content", "webpack", "assets_path"}, "string", "assets"},
+		{[]string{"profiles"}, "object", nil},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"path":  tc.path,
+		})
+
+		got := schemaAt(t, schema, tc.path...)
+		context.Assert("type", got["type"], tc.expType)
+		context.AssertArray("default", got["default"], tc.expDefault)
+	}
+
+	test.AssertArray(t, "profiles", schemaAt(t, schema, "profiles")["additionalProperties"], map[string]interface{}{"$ref": "#"})
+	test.AssertLabel(t, "items", schemaAt(t, schema, "server_settings", "tls", "hosts")["items"].(map[string]interface{})["type"], "string")
+}
+
+func TestApp_PrintSettings(t *testing.T) {
+	app, _, _ := defaultApp(nil, "generated")
+	buffer := &bytes.Buffer{}
+	test.AssertError(t, app.PrintSettings(buffer), "app.PrintSettings")
+
+	got := &Settings{}
+	test.AssertError(t, json.Unmarshal(buffer.Bytes(), got), "json.Unmarshal")
+	test.AssertLabel(t, "GeneratedPath", got.GeneratedPath, "generated")
+	test.AssertLabel(t, "Concurrency", got.GeneratorSettings.Concurrency, 10)
+
+	buffer = &bytes.Buffer{}
+	test.AssertError(t, app.PrintSettingsSchema(buffer), "app.PrintSettingsSchema")
+	schema := map[string]interface{}{}
+	test.AssertError(t, json.Unmarshal(buffer.Bytes(), &schema), "json.Unmarshal")
+	test.AssertLabel(t, "$schema", schema["$schema"], SettingsSchemaURL)
+}
@@ -6,14 +6,34 @@ import (
 	"os"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/urls"
 )
 
 // Settings represents the settings of App
 type Settings struct {
-	GeneratedPath     string             `json:"generated_path,omitempty"`
-	ServerPort        int                `json:"server_port,omitempty"`
-	FileServerPort    int                `json:"file_server_port,omitempty"`
-	GeneratorSettings *GeneratorSettings `json:"generator_settings,omitempty"`
+	GeneratedPath     string                 `json:"generated_path,omitempty" env:"GENERATED_PATH"`
+	ServerPort        int                    `json:"server_port,omitempty"`
+	FileServerPort    int                    `json:"file_server_port,omitempty"`
+	GeneratorSettings *GeneratorSettings     `json:"generator_settings,omitempty"`
+	ServerSettings    *router.ServerSettings `json:"server_settings,omitempty"`
+	// MetricsURL is the URL of the Prometheus metrics endpoint when hosting, no endpoint if empty
+	MetricsURL string `json:"metrics_url,omitempty"`
+	// URLs are the base URL, base path and asset host of the site, the servers serve under the base path
+	URLs *urls.Settings `json:"urls,omitempty"`
+
+	// LogLevel is the level of the log: panic, fatal, error, warn, info, debug
+	LogLevel string `json:"log_level,omitempty"`
+	// LogFormat is the format of the log: LogFormatText or LogFormatJSON
+	LogFormat string `json:"log_format,omitempty"`
+	// LogFile is the file path the log is appended to, instead of stderr
+	LogFile string `json:"log_file,omitempty"`
+
+	// Profile is the name of the active profile of Profiles, see LoadSettings
+	Profile string `json:"profile,omitempty" env:"ENV"`
+	// Profiles are the named settings that are deep merged over the base settings of the file, when active
+	Profiles map[string]interface{} `json:"profiles,omitempty" schema:"root"`
 
 	Content interface{} `json:"content,omitempty"`
 }
@@ -21,27 +41,50 @@ type Settings struct {
 // GeneratorSettings represents the settings for Generating files
 type GeneratorSettings struct {
 	Concurrency int `json:"concurrency,omitempty"`
+	// Clean is how stale files of the generated path are handled after generating: CleanRemove, CleanReport or CleanNone
+	Clean string `json:"clean,omitempty"`
+	// Atomic generates into a sibling staging directory, which is swapped with the generated path on success.
+	// The swap is atomic on Linux, elsewhere the generated path is briefly missing between two renames.
+	Atomic bool `json:"atomic,omitempty"`
+	// KeepBuilds is the number of previous builds kept as sibling rollback copies, when Atomic
+	KeepBuilds int `json:"keep_builds,omitempty"`
+	// ReportPath is the file path of the JSON BuildReport, BuildReportPath by default
+	ReportPath string `json:"report_path,omitempty"`
 }
 
-// DefaultSettings returns the default settings of the App
+// DefaultSettings returns the default settings of the App.
+// The GENERATED_PATH environment variable is read by LoadSettings and SettingsFromFile, not here.
 func DefaultSettings() *Settings {
-	generatedPath := os.Getenv("GENERATED_PATH")
-	if generatedPath == "" {
-		generatedPath = "./generated"
-	}
 	return &Settings{
-		generatedPath,
+		"./generated",
 		8080,
 		3000,
 		&GeneratorSettings{
 			10,
+			CleanRemove,
+			false,
+			1,
+			"",
 		},
+		router.DefaultServerSettings(),
+		"",
+		urls.DefaultSettings(),
+		"info",
+		LogFormatText,
+		"",
+		"",
+		nil,
 		nil,
 	}
 }
 
-// SettingsFromFile loads settings from the given file path into the given Settings
+// SettingsFromFile loads settings from the given file path into the given Settings, using the defaults on any error.
+// The unprefixed environment variables of SettingsEnvAliases (ex. GENERATED_PATH) are set before the file,
+// as defaults, which DefaultSettings no longer reads.
+// See LoadSettings to also load from the prefixed environment variables and CLI flags.
 func SettingsFromFile(path string, settings interface{}, log logrus.FieldLogger) {
+	setSettingsEnvAliases(settings, log)
+
 	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
 		log.Warnf("%v not found, using defaults...", path)
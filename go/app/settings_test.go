@@ -7,14 +7,14 @@ import (
 	logTest "github.com/sirupsen/logrus/hooks/test"
 
 	"github.com/s12chung/gostatic/go/test"
-	"github.com/s12chung/gostatic/go/test/settings"
 	"github.com/s12chung/gostatic/go/test/testfile"
 )
 
 func TestDefaultSettings(t *testing.T) {
-	settings.EnvSetting(t, "GENERATED_PATH", "./generated", func() string {
-		return DefaultSettings().GeneratedPath
-	})
+	unsetEnvs := setEnvs(t, map[string]string{"GENERATED_PATH": "env_path"})
+	defer unsetEnvs()
+	// the environment is read by LoadSettings and SettingsFromFile
+	test.AssertLabel(t, "GeneratedPath", DefaultSettings().GeneratedPath, "./generated")
 }
 
 type basicSetting struct {
@@ -64,6 +64,37 @@ func TestSettingsFromFile(t *testing.T) {
 	}
 }
 
+func TestSettingsFromFile_EnvAliases(t *testing.T) {
+	aliasEnvs := map[string]string{"GENERATED_PATH": "alias_path", "ASSETS_PATH": "alias_assets"}
+	testCases := []struct {
+		path             string
+		envs             map[string]string
+		expGeneratedPath string
+		expAssetsPath    string
+	}{
+		{"does not exist", nil, "./generated", "assets"},
+		{"does not exist", aliasEnvs, "alias_path", "alias_assets"},
+		{"layered_settings.json", aliasEnvs, "file_path", "file_assets"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"path":  tc.path,
+			"envs":  tc.envs,
+		})
+
+		unsetEnvs := setEnvs(t, tc.envs)
+		settings, content := layeredSettings()
+		log, _ := logTest.NewNullLogger()
+		SettingsFromFile(path.Join(testfile.FixturePath, tc.path), settings, log)
+		unsetEnvs()
+
+		context.Assert("GeneratedPath", settings.GeneratedPath, tc.expGeneratedPath)
+		context.Assert("Webpack.AssetsPath", content.Webpack.AssetsPath, tc.expAssetsPath)
+	}
+}
+
 func testSettingStruct(t *testing.T, context *test.Context, setting interface{}, structName string) {
 	switch structName {
 	case "basicSetting":
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Validator is implemented by settings to check their values, called by LoadSettings for the settings and the nested settings
+type Validator interface {
+	Validate() error
+}
+
+// ValidateSettings calls Validate on the settings and nested settings that implement Validator,
+// returning the first error prefixed with the JSON path of the settings
+func ValidateSettings(settings interface{}) error {
+	return validateValue(reflect.ValueOf(settings), nil)
+}
+
+func validateValue(value reflect.Value, path []string) error {
+	for value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		if validator, ok := value.Interface().(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				if len(path) == 0 {
+					return fmt.Errorf("invalid settings - %v", err)
+				}
+				return fmt.Errorf("invalid settings of %v - %v", strings.Join(path, "."), err)
+			}
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "-" || structField.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		if err := validateValue(value.Field(i), append(append([]string{}, path...), name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePort(name string, port int) error {
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("%v must be between 0 and 65535: %v", name, port)
+	}
+	return nil
+}
+
+// Validate returns an error if the settings are invalid
+func (settings *Settings) Validate() error {
+	if settings.GeneratedPath == "" {
+		return fmt.Errorf("generated_path is required")
+	}
+	if err := validatePort("server_port", settings.ServerPort); err != nil {
+		return err
+	}
+	if err := validatePort("file_server_port", settings.FileServerPort); err != nil {
+		return err
+	}
+	if settings.LogLevel != "" {
+		if _, err := logrus.ParseLevel(settings.LogLevel); err != nil {
+			return err
+		}
+	}
+	if settings.LogFormat != "" && settings.LogFormat != LogFormatText && settings.LogFormat != LogFormatJSON {
+		return fmt.Errorf("unknown log_format: %v", settings.LogFormat)
+	}
+	return nil
+}
+
+// Validate returns an error if the settings are invalid
+func (settings *GeneratorSettings) Validate() error {
+	if settings.Concurrency <= 0 {
+		return fmt.Errorf("concurrency must be greater than 0: %v", settings.Concurrency)
+	}
+	switch settings.Clean {
+	case "", CleanRemove, CleanReport, CleanNone:
+	default:
+		return fmt.Errorf("unknown clean mode: %v", settings.Clean)
+	}
+	if settings.KeepBuilds < 0 {
+		return fmt.Errorf("keep_builds must be 0 or greater: %v", settings.KeepBuilds)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/s12chung/gostatic/go/lib/html"
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/webpack"
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestValidateSettings(t *testing.T) {
+	testCases := []struct {
+		update func(settings *Settings, content *contentSettings)
+		exp    string
+	}{
+		{func(settings *Settings, content *contentSettings) {}, ""},
+		{func(settings *Settings, content *contentSettings) { settings.ServerPort = 0 }, ""},
+		{func(settings *Settings, content *contentSettings) { settings.GeneratedPath = "" }, "invalid settings - generated_path is required"},
+		{func(settings *Settings, content *contentSettings) { settings.FileServerPort = -1 }, "invalid settings - file_server_port must be between 0 and 65535: -1"},
+		{func(settings *Settings, content *contentSettings) { settings.LogLevel = "loud" }, `invalid settings - not a valid logrus Level: "loud"`},
+		{func(settings *Settings, content *contentSettings) { settings.LogFormat = "xml" }, "invalid settings - unknown log_format: xml"},
+		{func(settings *Settings, content *contentSettings) { settings.GeneratorSettings.Concurrency = -2 }, "invalid settings of generator_settings - concurrency must be greater than 0: -2"},
+		{func(settings *Settings, content *contentSettings) { settings.GeneratorSettings.Clean = "blah" }, "invalid settings of generator_settings - unknown clean mode: blah"},
+		{func(settings *Settings, content *contentSettings) { settings.GeneratorSettings.KeepBuilds = -1 }, "invalid settings of generator_settings - keep_builds must be 0 or greater: -1"},
+		{func(settings *Settings, content *contentSettings) { settings.ServerSettings.ReadTimeout = -1 }, "invalid settings of server_settings - timeouts must be 0 or greater"},
+		{func(settings *Settings, content *contentSettings) { settings.ServerSettings.TLS.CertFile = "cert.pem" }, "invalid settings of server_settings.tls - cert_file and key_file must be given together"},
+		{func(settings *Settings, content *contentSettings) { settings.ServerSettings.TLS = nil }, ""},
+		{func(settings *Settings, content *contentSettings) { content.HTML.TemplateExt = "" }, "invalid settings of content.html - template_path and template_ext are required"},
+		{func(settings *Settings, content *contentSettings) { content.Webpack.AssetsPath = "" }, "invalid settings of content.webpack - assets_path is required"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+		})
+
+		settings := DefaultSettings()
+		content := &contentSettings{html.DefaultSettings(), webpack.DefaultSettings()}
+		settings.Content = content
+		tc.update(settings, content)
+
+		got := ""
+		if err := ValidateSettings(settings); err != nil {
+			got = err.Error()
+		}
+		context.Assert("error", got, tc.exp)
+	}
+}
+
+func TestValidateSettings_Nil(t *testing.T) {
+	var settings *Settings
+	test.AssertError(t, ValidateSettings(settings), "ValidateSettings(nil)")
+	test.AssertError(t, ValidateSettings(&router.ServerSettings{}), "ValidateSettings(ServerSettings)")
+}
@@ -6,6 +6,7 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -20,8 +21,16 @@ type App interface {
 	Host() error
 	// Generate generate the static web pages
 	Generate() error
+	// GenerateMatching generates only the static web pages with URLs that match
+	GenerateMatching(match func(url string) bool) error
 	// Around is a callback/handler that is called around Generate
 	Around(handler func(handler func() error) error)
+	// PrintRoutes prints all the routes to w in the given format ("table" or "json")
+	PrintRoutes(w io.Writer, format string) error
+	// PrintSettings prints the effective settings as JSON to w
+	PrintSettings(w io.Writer) error
+	// PrintSettingsSchema prints the JSON Schema of the settings to w
+	PrintSettingsSchema(w io.Writer) error
 
 	// GeneratedPath returns the path of the generates files of the static web page
 	GeneratedPath() string
@@ -32,6 +41,8 @@ type App interface {
 
 	// Log returns the log of the App
 	Log() logrus.FieldLogger
+	// SetLogLevel sets the level of the log of the App
+	SetLogLevel(level logrus.Level)
 }
 
 // DefaultName returns the name of the executable from the Args
@@ -55,18 +66,59 @@ func Run(name string, application App, args []string) error {
 
 	fileServerPtr := f.Bool("file-server", false, fmt.Sprintf("Serves, but not generates, files in %v on localhost:%v", application.GeneratedPath(), application.FileServerPort()))
 	serverPtr := f.Bool("server", false, fmt.Sprintf("Hosts server on localhost:%v", application.ServerPort()))
+	routesPtr := f.Bool("routes", false, "Prints all the routes with their content type, batch and generated file path")
+	formatPtr := f.String("format", "table", "Output format of -routes: table or json")
+	printSettingsPtr := f.Bool("print-settings", false, "Prints the effective settings as JSON")
+	settingsSchemaPtr := f.Bool("settings-schema", false, "Prints the JSON Schema of the settings, for editor autocompletion")
+	verbosePtr := f.Bool("v", false, "Verbose, logs at the debug level")
+	quietPtr := f.Bool("q", false, "Quiet, logs only errors")
+	var only, onlyRegex, settingsOverrides stringsFlag
+	f.Var(&settingsOverrides, setFlagName, setFlagUsage)
+	f.String(envFlagName, "", envFlagUsage)
+	f.Var(&only, "only", "Generates only the URLs matching the glob (* within a path segment, ** across), can be repeated")
+	f.Var(&onlyRegex, "only-regex", "Generates only the URLs matching the regex, can be repeated")
 	err := f.Parse(args)
 	if err != nil {
 		return nil
 	}
 
+	setLogLevel(application, *verbosePtr, *quietPtr)
+
 	if *fileServerPtr {
 		return application.RunFileServer()
 	}
 	if *serverPtr {
 		return application.Host()
 	}
-	return application.Generate()
+	if *routesPtr {
+		return application.PrintRoutes(os.Stdout, *formatPtr)
+	}
+	if *printSettingsPtr {
+		return application.PrintSettings(os.Stdout)
+	}
+	if *settingsSchemaPtr {
+		return application.PrintSettingsSchema(os.Stdout)
+	}
+	return generate(application, only, onlyRegex)
+}
+
+func setLogLevel(application App, verbose, quiet bool) {
+	if verbose {
+		application.SetLogLevel(logrus.DebugLevel)
+	} else if quiet {
+		application.SetLogLevel(logrus.ErrorLevel)
+	}
+}
+
+func generate(application App, only, onlyRegex []string) error {
+	if len(only) == 0 && len(onlyRegex) == 0 {
+		return application.Generate()
+	}
+	match, err := urlMatcher(only, onlyRegex)
+	if err != nil {
+		return err
+	}
+	return application.GenerateMatching(match)
 }
 
 // SetDefaultAppARoundHandlers adds default around handlers for the App
@@ -23,6 +23,18 @@ func TestRunDefault(t *testing.T) {
 		{[]string{"-file-server"}, "RunFileServer"},
 		{[]string{"-server"}, "Host"},
 		{[]string{"-file-server", "-server"}, "RunFileServer"},
+		{[]string{"-routes"}, "PrintRoutes"},
+		{[]string{"-routes", "-format", "json"}, "PrintRoutesJSON"},
+		{[]string{"-server", "-routes"}, "Host"},
+		{[]string{"-only", "/posts/*"}, "GenerateMatching"},
+		{[]string{"-only", "/posts/*", "-only-regex", "^/about$"}, "GenerateMatching"},
+		{[]string{"-routes", "-only", "/posts/*"}, "PrintRoutes"},
+		{[]string{"-v"}, "Generate"},
+		{[]string{"-set", "generated_path=./out"}, "Generate"},
+		{[]string{"-env", "staging"}, "Generate"},
+		{[]string{"-print-settings"}, "PrintSettings"},
+		{[]string{"-settings-schema"}, "PrintSettingsSchema"},
+		{[]string{"-q", "-server"}, "Host"},
 		{[]string{"-blah"}, ""},
 		{[]string{"-file-server", "-blah"}, ""},
 	}
@@ -46,8 +58,32 @@ func TestRunDefault(t *testing.T) {
 			"Generate":      expect.Generate,
 			"RunFileServer": expect.RunFileServer,
 			"Host":          expect.Host,
+			"PrintRoutes": func() *gomock.Call {
+				return expect.PrintRoutes(gomock.Any(), "table")
+			},
+			"GenerateMatching": func() *gomock.Call {
+				return expect.GenerateMatching(gomock.Any())
+			},
+			"PrintSettings": func() *gomock.Call {
+				return expect.PrintSettings(gomock.Any())
+			},
+			"PrintSettingsSchema": func() *gomock.Call {
+				return expect.PrintSettingsSchema(gomock.Any())
+			},
+			"PrintRoutesJSON": func() *gomock.Call {
+				return expect.PrintRoutes(gomock.Any(), "json")
+			},
 		}[tc.functionName]()
 
+		for _, arg := range tc.args {
+			switch arg {
+			case "-v":
+				expect.SetLogLevel(logrus.DebugLevel)
+			case "-q":
+				expect.SetLogLevel(logrus.ErrorLevel)
+			}
+		}
+
 		context.AssertError(Run("random name", app, tc.args), "Run")
 
 		t.Log(context.FieldsString())
@@ -55,6 +91,55 @@ func TestRunDefault(t *testing.T) {
 	}
 }
 
+func TestRun_OnlyRegexError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	app := mocks.NewMockApp(controller)
+	expect := app.EXPECT()
+	expect.GeneratedPath().Return("the_generated")
+	expect.FileServerPort().Return(999)
+	expect.ServerPort().Return(100)
+
+	if Run("random name", app, []string{"-only-regex", "("}) == nil {
+		t.Error("expected error for invalid -only-regex")
+	}
+}
+
+func TestUrlMatcher(t *testing.T) {
+	testCases := []struct {
+		globs   []string
+		regexes []string
+		url     string
+		exp     bool
+	}{
+		{[]string{"/posts/*"}, nil, "/posts/a", true},
+		{[]string{"/posts/*"}, nil, "/posts/a/b", false},
+		{[]string{"/posts/*"}, nil, "/posts", false},
+		{[]string{"/posts/**"}, nil, "/posts/a/b", true},
+		{[]string{"/post?"}, nil, "/posts", true},
+		{[]string{"/post?"}, nil, "/post/", false},
+		{[]string{"/feed.xml"}, nil, "/feedaxml", false},
+		{[]string{"/about", "/posts/*"}, nil, "/about", true},
+		{nil, []string{"^/posts/"}, "/posts/a/b", true},
+		{nil, []string{"^/posts/"}, "/about", false},
+		{[]string{"/about"}, []string{"^/posts/"}, "/posts/a", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":   testCaseIndex,
+			"globs":   tc.globs,
+			"regexes": tc.regexes,
+			"url":     tc.url,
+		})
+
+		match, err := urlMatcher(tc.globs, tc.regexes)
+		context.AssertError(err, "urlMatcher")
+		context.Assert("match", match(tc.url), tc.exp)
+	}
+}
+
 func TestSetDefaultAppARoundHandlers(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+)
+
+// stringsFlag is a flag.Value that can be given multiple times
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// globToRegex converts a URL glob to a regex: `*` matches within a path segment,
+// `**` matches across path segments and `?` matches one character within a path segment
+func globToRegex(glob string) string {
+	var builder strings.Builder
+	builder.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			builder.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			builder.WriteString("[^/]*")
+		case glob[i] == '?':
+			builder.WriteString("[^/]")
+		default:
+			builder.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	builder.WriteString("$")
+	return builder.String()
+}
+
+// urlMatcher returns a function that returns true if the URL matches any of the globs or regexes
+func urlMatcher(globs, regexes []string) (func(url string) bool, error) {
+	patterns := make([]string, 0, len(globs)+len(regexes))
+	for _, glob := range globs {
+		patterns = append(patterns, globToRegex(glob))
+	}
+	patterns = append(patterns, regexes...)
+
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = regex
+	}
+
+	return func(url string) bool {
+		for _, regex := range compiled {
+			if regex.MatchString(url) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+const setFlagName = "set"
+
+const setFlagUsage = "Overrides a setting given its JSON path, ex. -set content.html.website_title=Title, can be repeated"
+
+const envFlagName = "env"
+
+const envFlagUsage = "Name of the settings profile to use, ex. staging (or set GOSTATIC_ENV)"
+
+// profileKey is the settings key of the profile, the same as app.ProfileKey
+const profileKey = "profile"
+
+// SettingsOverrides returns the settings overrides from the -set flags of the args, keyed by the JSON path of the setting,
+// to be given to app.LoadSettings before Run. The -env flag is given as the "profile" override.
+func SettingsOverrides(args []string) (map[string]string, error) {
+	overrides := map[string]string{}
+	for i := 0; i < len(args) && args[i] != "--"; i++ {
+		name, value, hasValue := splitFlag(args[i])
+		if name != setFlagName && name != envFlagName {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag needs an argument: -%v", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		if name == envFlagName {
+			overrides[profileKey] = value
+			continue
+		}
+		split := strings.SplitN(value, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("invalid -%v, expected key=value: %v", setFlagName, value)
+		}
+		overrides[split[0]] = split[1]
+	}
+	return overrides, nil
+}
+
+// splitFlag splits `-name=value` or `--name=value` into the name and value, or returns the name of `-name`
+func splitFlag(arg string) (string, string, bool) {
+	name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	if name == arg {
+		return "", "", false
+	}
+	split := strings.SplitN(name, "=", 2)
+	if len(split) == 1 {
+		return name, "", false
+	}
+	return split[0], split[1], true
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestSettingsOverrides(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		exp      map[string]string
+		hasError bool
+	}{
+		{nil, map[string]string{}, false},
+		{[]string{"-server"}, map[string]string{}, false},
+		{[]string{"-set", "a=1"}, map[string]string{"a": "1"}, false},
+		{[]string{"--set", "a.b=x=y", "-v"}, map[string]string{"a.b": "x=y"}, false},
+		{[]string{"-set=a=1", "-server", "-set", "b="}, map[string]string{"a": "1", "b": ""}, false},
+		{[]string{"-set", "a=1", "-set", "a=2"}, map[string]string{"a": "2"}, false},
+		{[]string{"--", "-set", "a=1"}, map[string]string{}, false},
+		{[]string{"-env", "staging", "-set", "a=1"}, map[string]string{"profile": "staging", "a": "1"}, false},
+		{[]string{"--env=production"}, map[string]string{"profile": "production"}, false},
+		{[]string{"-env"}, nil, true},
+		{[]string{"-set"}, nil, true},
+		{[]string{"-set", "a"}, nil, true},
+		{[]string{"-set", "=1"}, nil, true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"args":  tc.args,
+		})
+
+		got, err := SettingsOverrides(tc.args)
+		if tc.hasError {
+			context.Assert("error", err != nil, true)
+			continue
+		}
+		context.AssertError(err, "SettingsOverrides")
+		context.AssertArray("overrides", got, tc.exp)
+	}
+}
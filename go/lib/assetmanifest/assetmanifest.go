@@ -0,0 +1,149 @@
+/*
+Package assetmanifest reads the asset manifests of bundlers (webpack, Vite and esbuild) through the Manifest interface,
+and adds template functions to emit the tags of the entries.
+
+Plugin implements github.com/s12chung/gostatic/go/lib/html.Plugin
+*/
+package assetmanifest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/s12chung/gostatic/go/lib/urls"
+)
+
+// The Settings.Type of each Manifest adapter
+const (
+	TypeWebpack = "webpack"
+	TypeVite    = "vite"
+	TypeEsbuild = "esbuild"
+)
+
+// The default filenames of the manifests in the generated Settings.AssetsPath, see Settings.ManifestPath
+const (
+	DefaultManifestFilename = "manifest.json"
+	DefaultMetafileFilename = "meta.json"
+)
+
+// Manifest maps the source asset keys of a bundler to the generated files
+type Manifest interface {
+	// URL returns the URL of the generated file of the key
+	URL(key string) (string, error)
+	// Entry returns the Entry of the entry point key
+	Entry(key string) (*Entry, error)
+}
+
+// Entry is an entry point of the bundle with the URLs needed to load it
+type Entry struct {
+	// File is the URL of the entry's JS (or CSS) file
+	File string
+	// Module is true if File is an ES module, loaded with <script type="module">
+	Module bool
+	// Imports are the URLs of the statically imported chunks of File, preloaded with <link rel="modulepreload">
+	Imports []string
+	// CSS are the URLs of the stylesheets of File and its imports
+	CSS []string
+}
+
+// Settings is the settings of this package
+type Settings struct {
+	// Type is the format of the manifest: webpack, vite or esbuild
+	Type string `json:"type,omitempty"`
+	// AssetsPath is the path of the generated assets, relative to the generated path of NewManifest
+	AssetsPath string `json:"assets_path,omitempty" env:"ASSETS_PATH"`
+	// ManifestPath is the file path of the manifest, for esbuild it's the metafile.
+	// If empty, it's DefaultManifestFilename (DefaultMetafileFilename for esbuild) in the generated AssetsPath.
+	ManifestPath string `json:"manifest_path,omitempty"`
+	// URLPrefix is prefixed to the paths of the generated files to make the URLs, without the base path,
+	// as the asset host and base path of the urls (see urls.Settings) are applied by NewManifest
+	URLPrefix string `json:"url_prefix,omitempty"`
+	// OutDir is the esbuild outdir, which the metafile output paths start with
+	OutDir string `json:"out_dir,omitempty"`
+}
+
+// DefaultSettings returns the default settings of this package, for the webpack manifest.
+// The ASSETS_PATH environment variable is read by app.LoadSettings and app.SettingsFromFile, not here.
+func DefaultSettings() *Settings {
+	return &Settings{
+		TypeWebpack,
+		"assets",
+		"",
+		"/assets/",
+		"",
+	}
+}
+
+// Validate returns an error if the settings are invalid
+func (s *Settings) Validate() error {
+	switch s.Type {
+	case TypeWebpack, TypeVite, TypeEsbuild:
+	default:
+		return fmt.Errorf("type must be one of %v, %v or %v: %v", TypeWebpack, TypeVite, TypeEsbuild, s.Type)
+	}
+	if s.ManifestPath == "" && s.AssetsPath == "" {
+		return fmt.Errorf("manifest_path or assets_path is required")
+	}
+	return nil
+}
+
+// manifestPath returns the ManifestPath, or the default manifest file in the generated AssetsPath
+func (s *Settings) manifestPath(generatedPath string) string {
+	if s.ManifestPath != "" {
+		return s.ManifestPath
+	}
+	filename := DefaultManifestFilename
+	if s.Type == TypeEsbuild {
+		filename = DefaultMetafileFilename
+	}
+	return filepath.Join(generatedPath, s.AssetsPath, filename)
+}
+
+// NewManifest returns the Manifest adapter of Settings.Type, with the asset host and base path of the urls applied
+// to its URLs, see URLsManifest. The generatedPath is the path of the generated files, see Settings.ManifestPath.
+func NewManifest(generatedPath string, settings *Settings, u *urls.URLs) (Manifest, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return NewURLsManifest(newAdapter(settings.manifestPath(generatedPath), settings), u), nil
+}
+
+func newAdapter(manifestPath string, settings *Settings) Manifest {
+	switch settings.Type {
+	case TypeVite:
+		return NewViteManifest(manifestPath, settings.URLPrefix)
+	case TypeEsbuild:
+		return NewEsbuildManifest(manifestPath, settings.OutDir, settings.URLPrefix)
+	default:
+		return NewWebpackManifest(manifestPath, settings.URLPrefix)
+	}
+}
+
+func joinURL(urlPrefix, filePath string) string {
+	if urlPrefix == "" {
+		return filePath
+	}
+	return strings.TrimSuffix(urlPrefix, "/") + "/" + strings.TrimPrefix(filePath, "/")
+}
+
+func errKeyNotFound(manifestType, key string) error {
+	return fmt.Errorf("%v manifest key not found: %v", manifestType, key)
+}
+
+// appendUnique appends the values not in the slice
+func appendUnique(slice []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range slice {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			slice = append(slice, value)
+		}
+	}
+	return slice
+}
@@ -0,0 +1,222 @@
+package assetmanifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/s12chung/gostatic/go/lib/urls"
+	"github.com/s12chung/gostatic/go/lib/utils"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+var webpackPath = filepath.Join(testfile.FixturePath, "webpack", "manifest.json")
+var vitePath = filepath.Join(testfile.FixturePath, "vite", "manifest.json")
+var esbuildPath = filepath.Join(testfile.FixturePath, "esbuild", "meta.json")
+
+func TestSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		manifestType string
+		assetsPath   string
+		manifestPath string
+		err          bool
+	}{
+		{TypeWebpack, "", "manifest.json", false},
+		{TypeVite, "", "manifest.json", false},
+		{TypeEsbuild, "", "meta.json", false},
+		{TypeWebpack, "assets", "", false},
+		{"rollup", "", "manifest.json", true},
+		{TypeVite, "", "", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":        testCaseIndex,
+			"manifestType": tc.manifestType,
+			"assetsPath":   tc.assetsPath,
+			"manifestPath": tc.manifestPath,
+		})
+		settings := &Settings{tc.manifestType, tc.assetsPath, tc.manifestPath, "/", ""}
+		context.Assert("err != nil", settings.Validate() != nil, tc.err)
+	}
+}
+
+func TestSettings_manifestPath(t *testing.T) {
+	testCases := []struct {
+		manifestType string
+		assetsPath   string
+		manifestPath string
+		exp          string
+	}{
+		{TypeWebpack, "assets", "", "generated/assets/manifest.json"},
+		{TypeVite, "static", "", "generated/static/manifest.json"},
+		{TypeEsbuild, "assets", "", "generated/assets/meta.json"},
+		{TypeWebpack, "assets", "./build/manifest.json", "./build/manifest.json"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":        testCaseIndex,
+			"manifestType": tc.manifestType,
+			"assetsPath":   tc.assetsPath,
+			"manifestPath": tc.manifestPath,
+		})
+		settings := &Settings{tc.manifestType, tc.assetsPath, tc.manifestPath, "/", ""}
+		context.Assert("result", settings.manifestPath("./generated"), tc.exp)
+	}
+}
+
+func TestNewManifest(t *testing.T) {
+	testCases := []struct {
+		manifestType string
+		exp          string
+	}{
+		{TypeWebpack, "*assetmanifest.WebpackManifest"},
+		{TypeVite, "*assetmanifest.ViteManifest"},
+		{TypeEsbuild, "*assetmanifest.EsbuildManifest"},
+		{"rollup", "<nil>"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":        testCaseIndex,
+			"manifestType": tc.manifestType,
+		})
+		settings := DefaultSettings()
+		settings.Type = tc.manifestType
+		manifest, err := NewManifest("./generated", settings, urls.NewURLs(nil))
+		context.Assert("err != nil", err != nil, tc.exp == "<nil>")
+		if err != nil {
+			continue
+		}
+		context.Assert("type", fmt.Sprintf("%T", manifest.(*URLsManifest).Manifest()), tc.exp)
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	testCases := []struct {
+		urlPrefix string
+		filePath  string
+		exp       string
+	}{
+		{"", "a.js", "a.js"},
+		{"/", "a.js", "/a.js"},
+		{"/assets", "a.js", "/assets/a.js"},
+		{"/assets/", "/a.js", "/assets/a.js"},
+		{"https://cdn.example.com/", "a.js", "https://cdn.example.com/a.js"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":     testCaseIndex,
+			"urlPrefix": tc.urlPrefix,
+			"filePath":  tc.filePath,
+		})
+		context.Assert("result", joinURL(tc.urlPrefix, tc.filePath), tc.exp)
+	}
+}
+
+func TestManifest_URL(t *testing.T) {
+	testCases := []struct {
+		manifest Manifest
+		key      string
+		exp      string
+		err      bool
+	}{
+		{NewWebpackManifest(webpackPath, "/assets/"), "main.js", "/assets/main-32267303.js", false},
+		{NewWebpackManifest(webpackPath, "/assets/"), "missing.js", "", true},
+		{NewWebpackManifest("missing.json", "/assets/"), "main.js", "", true},
+		{NewViteManifest(vitePath, "/"), "views/foo.js", "/assets/foo-BRBmoGS9.js", false},
+		{NewViteManifest(vitePath, "/"), "missing.js", "", true},
+		{NewEsbuildManifest(esbuildPath, "public", "/"), "src/app.ts", "/build/app-5EGXVYXY.js", false},
+		{NewEsbuildManifest(esbuildPath, "public/build", "/static/"), "./src/app.ts", "/static/app-5EGXVYXY.js", false},
+		{NewEsbuildManifest(esbuildPath, "public", "/"), "src/missing.ts", "", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"manifest": fmt.Sprintf("%T", tc.manifest),
+			"key":      tc.key,
+		})
+
+		got, err := tc.manifest.URL(tc.key)
+		context.Assert("err != nil", err != nil, tc.err)
+		context.Assert("result", got, tc.exp)
+	}
+}
+
+func TestManifest_Entry(t *testing.T) {
+	testCases := []struct {
+		manifest Manifest
+		key      string
+		exp      *Entry
+	}{
+		{NewWebpackManifest(webpackPath, "/assets/"), "main.js", &Entry{
+			File: "/assets/main-32267303.js",
+			CSS:  []string{"/assets/main-a1b2c3d4.css"},
+		}},
+		{NewWebpackManifest(webpackPath, "/assets/"), "vendor.js", &Entry{File: "/assets/vendor-e5f6a7b8.js"}},
+		{NewWebpackManifest(webpackPath, "/assets/"), "print.css", &Entry{File: "/assets/print-c9d0e1f2.css"}},
+		{NewViteManifest(vitePath, "/"), "views/foo.js", &Entry{
+			File:    "/assets/foo-BRBmoGS9.js",
+			Module:  true,
+			Imports: []string{"/assets/shared-B7PI925R.js", "/assets/utils-Cj1x2y3z.js"},
+			CSS:     []string{"/assets/shared-ChJ_j-JJ.css", "/assets/foo-5UjPuW-k.css"},
+		}},
+		{NewViteManifest(vitePath, "/"), "views/bar.js", &Entry{
+			File:    "/assets/bar-gkvgaI9m.js",
+			Module:  true,
+			Imports: []string{"/assets/shared-B7PI925R.js", "/assets/utils-Cj1x2y3z.js"},
+			CSS:     []string{"/assets/shared-ChJ_j-JJ.css"},
+		}},
+		{NewViteManifest(vitePath, "/"), "styles/print.css", &Entry{File: "/assets/print-Dm8e3b1c.css"}},
+		{NewEsbuildManifest(esbuildPath, "public", "/"), "src/app.ts", &Entry{
+			File:    "/build/app-5EGXVYXY.js",
+			Module:  true,
+			Imports: []string{"/build/chunk-ZK3WA2XU.js", "/build/chunk-AAAA1111.js"},
+			CSS:     []string{"/build/app-OVS6MGIN.css"},
+		}},
+		{NewEsbuildManifest(esbuildPath, "public", "/"), "src/print.css", &Entry{File: "/build/print-H7JK2L3M.css"}},
+		{NewEsbuildManifest(esbuildPath, "public", "/"), "src/missing.ts", nil},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"manifest": fmt.Sprintf("%T", tc.manifest),
+			"key":      tc.key,
+		})
+
+		got, err := tc.manifest.Entry(tc.key)
+		context.Assert("err != nil", err != nil, tc.exp == nil)
+		context.AssertArray("result", got, tc.exp)
+	}
+}
+
+func TestJSONFile_Reload(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "assetmanifest")
+	defer clean()
+	test.AssertError(t, utils.MkdirAll(dir), "utils.MkdirAll")
+
+	manifestPath := filepath.Join(dir, "manifest.json")
+	manifest := NewWebpackManifest(manifestPath, "/")
+	writeManifest := func(manifestJSON string, modTime time.Time) {
+		test.AssertError(t, utils.WriteFile(manifestPath, []byte(manifestJSON)), "utils.WriteFile")
+		test.AssertError(t, os.Chtimes(manifestPath, modTime, modTime), "os.Chtimes")
+	}
+	modTime := time.Now().Add(-time.Hour)
+
+	writeManifest(`{"main.js": "main-1.js"}`, modTime)
+	got, err := manifest.URL("main.js")
+	test.AssertError(t, err, "manifest.URL")
+	test.AssertLabel(t, "first read", got, "/main-1.js")
+
+	writeManifest(`{"main.js": "main-2.js"}`, modTime.Add(time.Minute))
+	got, err = manifest.URL("main.js")
+	test.AssertError(t, err, "manifest.URL")
+	test.AssertLabel(t, "changed modtime", got, "/main-2.js")
+}
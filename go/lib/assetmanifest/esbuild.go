@@ -0,0 +1,127 @@
+package assetmanifest
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/s12chung/gostatic/go/lib/filecache"
+)
+
+// EsbuildImport is an import of an esbuild metafile output
+type EsbuildImport struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	External bool   `json:"external,omitempty"`
+}
+
+// EsbuildOutput is an output of the esbuild metafile
+type EsbuildOutput struct {
+	EntryPoint string           `json:"entryPoint,omitempty"`
+	Imports    []*EsbuildImport `json:"imports,omitempty"`
+	CSSBundle  string           `json:"cssBundle,omitempty"`
+}
+
+type esbuildMetafile struct {
+	Outputs map[string]*EsbuildOutput `json:"outputs"`
+}
+
+// esbuildStaticImportKind is the import kind of static imports, which are preloaded
+const esbuildStaticImportKind = "import-statement"
+
+// EsbuildManifest is the Manifest of an esbuild metafile (`metafile: true`),
+// the keys are the entry points given to esbuild (ex. `src/main.ts`). The outputs are ES modules (`format: "esm"`).
+type EsbuildManifest struct {
+	file      *filecache.File
+	outDir    string
+	urlPrefix string
+}
+
+// NewEsbuildManifest returns a new instance of EsbuildManifest, the outDir is removed from the output paths for the URLs
+func NewEsbuildManifest(metafilePath, outDir, urlPrefix string) *EsbuildManifest {
+	return &EsbuildManifest{
+		filecache.NewFile(metafilePath, func(bytes []byte) (interface{}, error) {
+			metafile := &esbuildMetafile{}
+			if err := json.Unmarshal(bytes, metafile); err != nil {
+				return nil, err
+			}
+			return metafile.Outputs, nil
+		}),
+		outDir,
+		urlPrefix,
+	}
+}
+
+func (m *EsbuildManifest) outputs() (map[string]*EsbuildOutput, error) {
+	value, err := m.file.Get()
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]*EsbuildOutput), nil
+}
+
+func (m *EsbuildManifest) outputURL(outputPath string) string {
+	outDir := path.Clean(m.outDir)
+	outputPath = path.Clean(outputPath)
+	if outDir != "." && strings.HasPrefix(outputPath, outDir+"/") {
+		outputPath = strings.TrimPrefix(outputPath, outDir+"/")
+	}
+	return joinURL(m.urlPrefix, outputPath)
+}
+
+// entryOutput returns the path and output of the entry point key, ignoring the source maps
+func (m *EsbuildManifest) entryOutput(key string) (string, *EsbuildOutput, map[string]*EsbuildOutput, error) {
+	outputs, err := m.outputs()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	key = path.Clean(key)
+	for outputPath, output := range outputs {
+		if output.EntryPoint != "" && path.Clean(output.EntryPoint) == key && path.Ext(outputPath) != ".map" {
+			return outputPath, output, outputs, nil
+		}
+	}
+	return "", nil, nil, errKeyNotFound(TypeEsbuild, key)
+}
+
+// URL returns the URL of the output of the entry point key
+func (m *EsbuildManifest) URL(key string) (string, error) {
+	outputPath, _, _, err := m.entryOutput(key)
+	if err != nil {
+		return "", err
+	}
+	return m.outputURL(outputPath), nil
+}
+
+// Entry returns the Entry of the entry point key, with the static imports (recursively) as Imports
+// and the CSS bundle of the entry
+func (m *EsbuildManifest) Entry(key string) (*Entry, error) {
+	outputPath, output, outputs, err := m.entryOutput(key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{File: m.outputURL(outputPath)}
+	if path.Ext(outputPath) == ".css" {
+		return entry, nil
+	}
+	entry.Module = true
+	if output.CSSBundle != "" {
+		entry.CSS = []string{m.outputURL(output.CSSBundle)}
+	}
+	m.addImports(entry, outputs, output, map[string]bool{outputPath: true})
+	return entry, nil
+}
+
+func (m *EsbuildManifest) addImports(entry *Entry, outputs map[string]*EsbuildOutput, output *EsbuildOutput, visited map[string]bool) {
+	for _, imp := range output.Imports {
+		if imp.Kind != esbuildStaticImportKind || imp.External || visited[imp.Path] {
+			continue
+		}
+		visited[imp.Path] = true
+		entry.Imports = appendUnique(entry.Imports, m.outputURL(imp.Path))
+		if importOutput, has := outputs[imp.Path]; has {
+			m.addImports(entry, outputs, importOutput, visited)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package assetmanifest
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Plugin adds the template functions of a Manifest, it implements github.com/s12chung/gostatic/go/lib/html.Plugin
+type Plugin struct {
+	manifest Manifest
+}
+
+// NewPlugin returns a new instance of Plugin
+func NewPlugin(manifest Manifest) *Plugin {
+	return &Plugin{manifest}
+}
+
+// StylesheetTags returns the <link rel="stylesheet"> tags of the entry, place them in the <head>
+func (p *Plugin) StylesheetTags(key string) (template.HTML, error) {
+	entry, err := p.manifest.Entry(key)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(strings.Join(stylesheetTags(entry), "\n")), nil
+}
+
+// ScriptTags returns the <link rel="modulepreload"> tags of the entry's imports and the <script> tag of the entry
+func (p *Plugin) ScriptTags(key string) (template.HTML, error) {
+	entry, err := p.manifest.Entry(key)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(strings.Join(scriptTags(entry), "\n")), nil
+}
+
+// Tags returns the StylesheetTags and ScriptTags of the entry
+func (p *Plugin) Tags(key string) (template.HTML, error) {
+	entry, err := p.manifest.Entry(key)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(strings.Join(append(stylesheetTags(entry), scriptTags(entry)...), "\n")), nil
+}
+
+func stylesheetTags(entry *Entry) []string {
+	hrefs := entry.CSS
+	if isCSS(entry.File) {
+		hrefs = append([]string{entry.File}, hrefs...)
+	}
+
+	tags := make([]string, len(hrefs))
+	for i, href := range hrefs {
+		tags[i] = fmt.Sprintf(`<link rel="stylesheet" href="%v">`, template.HTMLEscapeString(href))
+	}
+	return tags
+}
+
+func scriptTags(entry *Entry) []string {
+	if isCSS(entry.File) {
+		return nil
+	}
+
+	var tags []string
+	for _, href := range entry.Imports {
+		tags = append(tags, fmt.Sprintf(`<link rel="modulepreload" href="%v">`, template.HTMLEscapeString(href)))
+	}
+	src := template.HTMLEscapeString(entry.File)
+	if entry.Module {
+		return append(tags, fmt.Sprintf(`<script type="module" src="%v"></script>`, src))
+	}
+	return append(tags, fmt.Sprintf(`<script src="%v"></script>`, src))
+}
+
+func isCSS(url string) bool {
+	return strings.HasSuffix(url, ".css")
+}
+
+// TemplateFuncs implements github.com/s12chung/gostatic/go/lib/html.Plugin
+func (p *Plugin) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"assetURL":            p.manifest.URL,
+		"assetTags":           p.Tags,
+		"assetStylesheetTags": p.StylesheetTags,
+		"assetScriptTags":     p.ScriptTags,
+	}
+}
@@ -0,0 +1,81 @@
+package assetmanifest
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestPlugin_Tags(t *testing.T) {
+	testCases := []struct {
+		manifest Manifest
+		key      string
+		exp      []string
+	}{
+		{NewWebpackManifest(webpackPath, "/assets/"), "main.js", []string{
+			`<link rel="stylesheet" href="/assets/main-a1b2c3d4.css">`,
+			`<script src="/assets/main-32267303.js"></script>`,
+		}},
+		{NewWebpackManifest(webpackPath, "/assets/"), "print.css", []string{
+			`<link rel="stylesheet" href="/assets/print-c9d0e1f2.css">`,
+		}},
+		{NewViteManifest(vitePath, "/"), "views/foo.js", []string{
+			`<link rel="stylesheet" href="/assets/shared-ChJ_j-JJ.css">`,
+			`<link rel="stylesheet" href="/assets/foo-5UjPuW-k.css">`,
+			`<link rel="modulepreload" href="/assets/shared-B7PI925R.js">`,
+			`<link rel="modulepreload" href="/assets/utils-Cj1x2y3z.js">`,
+			`<script type="module" src="/assets/foo-BRBmoGS9.js"></script>`,
+		}},
+		{NewEsbuildManifest(esbuildPath, "public", "/"), "src/app.ts", []string{
+			`<link rel="stylesheet" href="/build/app-OVS6MGIN.css">`,
+			`<link rel="modulepreload" href="/build/chunk-ZK3WA2XU.js">`,
+			`<link rel="modulepreload" href="/build/chunk-AAAA1111.js">`,
+			`<script type="module" src="/build/app-5EGXVYXY.js"></script>`,
+		}},
+		{NewViteManifest(vitePath, "/"), "missing.js", nil},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"key":   tc.key,
+		})
+
+		got, err := NewPlugin(tc.manifest).Tags(tc.key)
+		context.Assert("err != nil", err != nil, tc.exp == nil)
+		context.Assert("result", got, template.HTML(strings.Join(tc.exp, "\n")))
+	}
+}
+
+func TestPlugin_StylesheetTags_ScriptTags(t *testing.T) {
+	plugin := NewPlugin(NewViteManifest(vitePath, "/"))
+
+	stylesheetTags, err := plugin.StylesheetTags("views/bar.js")
+	test.AssertError(t, err, "plugin.StylesheetTags")
+	test.AssertLabel(t, "StylesheetTags", stylesheetTags, template.HTML(`<link rel="stylesheet" href="/assets/shared-ChJ_j-JJ.css">`))
+
+	scriptTags, err := plugin.ScriptTags("styles/print.css")
+	test.AssertError(t, err, "plugin.ScriptTags")
+	test.AssertLabel(t, "ScriptTags of css", scriptTags, template.HTML(""))
+}
+
+func TestPlugin_TemplateFuncs(t *testing.T) {
+	plugin := NewPlugin(NewWebpackManifest(webpackPath, "/assets/"))
+	tmpl, err := template.New("test").Funcs(plugin.TemplateFuncs()).Parse(
+		`<img src="{{ assetURL "vendor.js" }}">{{ assetStylesheetTags "main.js" }}{{ assetScriptTags "vendor.js" }}`,
+	)
+	test.AssertError(t, err, "template.Parse")
+
+	buffer := &bytes.Buffer{}
+	test.AssertError(t, tmpl.Execute(buffer, nil), "tmpl.Execute")
+	exp := `<img src="/assets/vendor-e5f6a7b8.js">` +
+		`<link rel="stylesheet" href="/assets/main-a1b2c3d4.css">` +
+		`<script src="/assets/vendor-e5f6a7b8.js"></script>`
+	test.AssertLabel(t, "result", buffer.String(), exp)
+
+	err = template.Must(template.New("test").Funcs(plugin.TemplateFuncs()).Parse(`{{ assetTags "missing.js" }}`)).Execute(&bytes.Buffer{}, nil)
+	test.AssertLabel(t, "missing key err != nil", err != nil, true)
+}
@@ -0,0 +1,58 @@
+package assetmanifest
+
+import (
+	"github.com/s12chung/gostatic/go/lib/urls"
+)
+
+// URLsManifest applies the asset host and base path of the urls to the URLs of a Manifest
+type URLsManifest struct {
+	manifest Manifest
+	urls     *urls.URLs
+}
+
+// NewURLsManifest returns a new instance of URLsManifest
+func NewURLsManifest(manifest Manifest, u *urls.URLs) *URLsManifest {
+	return &URLsManifest{
+		manifest,
+		u,
+	}
+}
+
+// Manifest returns the Manifest with the URLs the urls are applied to
+func (m *URLsManifest) Manifest() Manifest {
+	return m.manifest
+}
+
+// URL returns the URL of the generated file of the key, see urls.URLs.AssetURL
+func (m *URLsManifest) URL(key string) (string, error) {
+	url, err := m.manifest.URL(key)
+	if err != nil {
+		return "", err
+	}
+	return m.urls.AssetURL(url), nil
+}
+
+// Entry returns the Entry of the entry point key, with urls.URLs.AssetURL applied to its URLs
+func (m *URLsManifest) Entry(key string) (*Entry, error) {
+	entry, err := m.manifest.Entry(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		File:    m.urls.AssetURL(entry.File),
+		Module:  entry.Module,
+		Imports: m.assetURLs(entry.Imports),
+		CSS:     m.assetURLs(entry.CSS),
+	}, nil
+}
+
+func (m *URLsManifest) assetURLs(rawURLs []string) []string {
+	if rawURLs == nil {
+		return nil
+	}
+	assetURLs := make([]string, len(rawURLs))
+	for i, rawURL := range rawURLs {
+		assetURLs[i] = m.urls.AssetURL(rawURL)
+	}
+	return assetURLs
+}
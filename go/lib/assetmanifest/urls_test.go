@@ -0,0 +1,66 @@
+package assetmanifest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/lib/urls"
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestURLsManifest_URL(t *testing.T) {
+	testCases := []struct {
+		settings *urls.Settings
+		key      string
+		exp      string
+		err      bool
+	}{
+		{nil, "main.js", "/assets/main-32267303.js", false},
+		{&urls.Settings{BasePath: "/docs/"}, "main.js", "/docs/assets/main-32267303.js", false},
+		{&urls.Settings{BasePath: "/docs/", AssetHost: "https://cdn.example.com"}, "main.js", "https://cdn.example.com/docs/assets/main-32267303.js", false},
+		{&urls.Settings{BasePath: "/docs/"}, "missing.js", "", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"settings": fmt.Sprintf("%+v", tc.settings),
+			"key":      tc.key,
+		})
+
+		manifest := NewURLsManifest(NewWebpackManifest(webpackPath, "/assets/"), urls.NewURLs(tc.settings))
+		got, err := manifest.URL(tc.key)
+		context.Assert("err != nil", err != nil, tc.err)
+		context.Assert("result", got, tc.exp)
+	}
+}
+
+func TestURLsManifest_Entry(t *testing.T) {
+	testCases := []struct {
+		manifest Manifest
+		key      string
+		exp      *Entry
+	}{
+		{NewWebpackManifest(webpackPath, "/assets/"), "vendor.js", &Entry{File: "https://cdn.example.com/docs/assets/vendor-e5f6a7b8.js"}},
+		{NewViteManifest(vitePath, "/"), "views/foo.js", &Entry{
+			File:    "https://cdn.example.com/docs/assets/foo-BRBmoGS9.js",
+			Module:  true,
+			Imports: []string{"https://cdn.example.com/docs/assets/shared-B7PI925R.js", "https://cdn.example.com/docs/assets/utils-Cj1x2y3z.js"},
+			CSS:     []string{"https://cdn.example.com/docs/assets/shared-ChJ_j-JJ.css", "https://cdn.example.com/docs/assets/foo-5UjPuW-k.css"},
+		}},
+		{NewViteManifest(vitePath, "/"), "missing.js", nil},
+	}
+
+	u := urls.NewURLs(&urls.Settings{BasePath: "/docs/", AssetHost: "https://cdn.example.com"})
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"manifest": fmt.Sprintf("%T", tc.manifest),
+			"key":      tc.key,
+		})
+
+		got, err := NewURLsManifest(tc.manifest, u).Entry(tc.key)
+		context.Assert("err != nil", err != nil, tc.exp == nil)
+		context.AssertArray("result", got, tc.exp)
+	}
+}
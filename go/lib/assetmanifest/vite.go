@@ -0,0 +1,95 @@
+package assetmanifest
+
+import (
+	"encoding/json"
+	"path"
+
+	"github.com/s12chung/gostatic/go/lib/filecache"
+)
+
+// ViteChunk is a chunk of the Vite manifest
+type ViteChunk struct {
+	File           string   `json:"file"`
+	Src            string   `json:"src,omitempty"`
+	IsEntry        bool     `json:"isEntry,omitempty"`
+	Imports        []string `json:"imports,omitempty"`
+	DynamicImports []string `json:"dynamicImports,omitempty"`
+	CSS            []string `json:"css,omitempty"`
+	Assets         []string `json:"assets,omitempty"`
+}
+
+// ViteManifest is the Manifest of Vite's `manifest.json` (`build.manifest`),
+// the keys are the source paths relative to the Vite root (ex. `src/main.ts`)
+type ViteManifest struct {
+	file      *filecache.File
+	urlPrefix string
+}
+
+// NewViteManifest returns a new instance of ViteManifest
+func NewViteManifest(manifestPath, urlPrefix string) *ViteManifest {
+	return &ViteManifest{
+		filecache.NewFile(manifestPath, func(bytes []byte) (interface{}, error) {
+			chunks := map[string]*ViteChunk{}
+			return chunks, json.Unmarshal(bytes, &chunks)
+		}),
+		urlPrefix,
+	}
+}
+
+func (m *ViteManifest) chunks() (map[string]*ViteChunk, error) {
+	value, err := m.file.Get()
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]*ViteChunk), nil
+}
+
+// URL returns the URL of the generated file of the key
+func (m *ViteManifest) URL(key string) (string, error) {
+	chunks, err := m.chunks()
+	if err != nil {
+		return "", err
+	}
+	chunk, has := chunks[key]
+	if !has {
+		return "", errKeyNotFound(TypeVite, key)
+	}
+	return joinURL(m.urlPrefix, chunk.File), nil
+}
+
+// Entry returns the Entry of the key, with the files of the static imports (recursively) as Imports
+// and the CSS of the chunk and its imports
+func (m *ViteManifest) Entry(key string) (*Entry, error) {
+	chunks, err := m.chunks()
+	if err != nil {
+		return nil, err
+	}
+	chunk, has := chunks[key]
+	if !has {
+		return nil, errKeyNotFound(TypeVite, key)
+	}
+
+	entry := &Entry{File: joinURL(m.urlPrefix, chunk.File)}
+	if path.Ext(chunk.File) == ".css" {
+		return entry, nil
+	}
+	entry.Module = true
+	m.addChunk(entry, chunks, chunk, map[string]bool{key: true})
+	return entry, nil
+}
+
+// addChunk adds the imports and CSS of the chunk to the entry, depth first so the CSS is in import order
+func (m *ViteManifest) addChunk(entry *Entry, chunks map[string]*ViteChunk, chunk *ViteChunk, visited map[string]bool) {
+	for _, importKey := range chunk.Imports {
+		importChunk, has := chunks[importKey]
+		if !has || visited[importKey] {
+			continue
+		}
+		visited[importKey] = true
+		entry.Imports = appendUnique(entry.Imports, joinURL(m.urlPrefix, importChunk.File))
+		m.addChunk(entry, chunks, importChunk, visited)
+	}
+	for _, css := range chunk.CSS {
+		entry.CSS = appendUnique(entry.CSS, joinURL(m.urlPrefix, css))
+	}
+}
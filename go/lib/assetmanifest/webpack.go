@@ -0,0 +1,57 @@
+package assetmanifest
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/s12chung/gostatic/go/lib/filecache"
+)
+
+// WebpackManifest is the Manifest of the flat key to file map of webpack-manifest-plugin
+type WebpackManifest struct {
+	file      *filecache.File
+	urlPrefix string
+}
+
+// NewWebpackManifest returns a new instance of WebpackManifest
+func NewWebpackManifest(manifestPath, urlPrefix string) *WebpackManifest {
+	return &WebpackManifest{
+		filecache.NewFile(manifestPath, func(bytes []byte) (interface{}, error) {
+			manifestMap := map[string]string{}
+			return manifestMap, json.Unmarshal(bytes, &manifestMap)
+		}),
+		urlPrefix,
+	}
+}
+
+// URL returns the URL of the generated file of the key
+func (m *WebpackManifest) URL(key string) (string, error) {
+	value, err := m.file.Get()
+	if err != nil {
+		return "", err
+	}
+	file, has := value.(map[string]string)[key]
+	if !has {
+		return "", errKeyNotFound(TypeWebpack, key)
+	}
+	return joinURL(m.urlPrefix, file), nil
+}
+
+// Entry returns the Entry of the key, which is a script (not a module).
+// The stylesheet of the entry is the key with a `.css` extension (ex. `main.js` and `main.css`), if it exists.
+func (m *WebpackManifest) Entry(key string) (*Entry, error) {
+	file, err := m.URL(key)
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{File: file}
+	if path.Ext(key) == ".css" {
+		return entry, nil
+	}
+
+	if css, cssErr := m.URL(strings.TrimSuffix(key, path.Ext(key)) + ".css"); cssErr == nil {
+		entry.CSS = []string{css}
+	}
+	return entry, nil
+}
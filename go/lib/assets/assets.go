@@ -0,0 +1,236 @@
+/*
+Package assets fingerprints, bundles and minifies the CSS, JS and other assets of a source directory in Go,
+for sites without a Node toolchain.
+
+The files are written with hashed filenames into the generated assets path, with a manifest.json
+in the same format as Webpack's, so github.com/s12chung/gostatic/go/lib/webpack.Manifest (and `webpackURL`) reads it.
+*/
+package assets
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/utils"
+)
+
+// ManifestFilename is the filename of the manifest in the generated assets path, the same as Webpack's
+const ManifestFilename = "manifest.json"
+
+// hashLength is the length of the hash in the fingerprinted filenames, the same as Webpack's [contenthash:20]
+const hashLength = 20
+
+// Assets fingerprints the files of Settings.SourcePath into the generated assets path
+type Assets struct {
+	generatedAssetsPath string
+	settings            *Settings
+	log                 logrus.FieldLogger
+}
+
+// NewAssets returns a new instance of Assets
+func NewAssets(generatedAssetsPath string, settings *Settings, log logrus.FieldLogger) *Assets {
+	return &Assets{
+		generatedAssetsPath,
+		settings,
+		log,
+	}
+}
+
+// Enabled returns true if Settings.SourcePath is set
+func (a *Assets) Enabled() bool {
+	return a.settings != nil && a.settings.SourcePath != ""
+}
+
+// Build writes the bundles and the files not in a bundle with fingerprinted filenames, then writes the manifest.
+// The files of the previous manifest that are not in the new one are removed. It does nothing if not Enabled.
+func (a *Assets) Build() (map[string]string, error) {
+	if !a.Enabled() {
+		return nil, nil
+	}
+
+	manifest := map[string]string{}
+	for key, sourcePaths := range a.settings.Bundles {
+		contents, err := a.bundle(key, sourcePaths)
+		if err != nil {
+			return nil, err
+		}
+		if manifest[key], err = a.write(key, contents); err != nil {
+			return nil, err
+		}
+	}
+
+	sourcePaths, err := a.sourcePaths()
+	if err != nil {
+		return nil, err
+	}
+	for _, sourcePath := range sourcePaths {
+		contents, readErr := a.read(sourcePath)
+		if readErr != nil {
+			return nil, readErr
+		}
+		if manifest[sourcePath], err = a.write(sourcePath, contents); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = a.writeManifest(manifest); err != nil {
+		return nil, err
+	}
+	a.log.Infof("Built %v assets from %v into %v", len(manifest), a.settings.SourcePath, a.generatedAssetsPath)
+	return manifest, nil
+}
+
+// sourcePaths returns the paths, relative to Settings.SourcePath, of the files not in a bundle,
+// skipping the dot files and directories (ex. .git)
+func (a *Assets) sourcePaths() ([]string, error) {
+	bundled := map[string]bool{}
+	for _, sourcePaths := range a.settings.Bundles {
+		for _, sourcePath := range sourcePaths {
+			bundled[path.Clean(sourcePath)] = true
+		}
+	}
+
+	var sourcePaths []string
+	err := filepath.Walk(a.settings.SourcePath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		isDot := strings.HasPrefix(info.Name(), ".") && filePath != a.settings.SourcePath
+		if info.IsDir() {
+			if isDot {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isDot {
+			return nil
+		}
+		sourcePath, err := filepath.Rel(a.settings.SourcePath, filePath)
+		if err != nil {
+			return err
+		}
+		sourcePath = filepath.ToSlash(sourcePath)
+		if !bundled[sourcePath] {
+			sourcePaths = append(sourcePaths, sourcePath)
+		}
+		return nil
+	})
+	return sourcePaths, err
+}
+
+// read reads the source file, minifying it if needed
+func (a *Assets) read(sourcePath string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(a.settings.SourcePath, filepath.FromSlash(sourcePath)))
+	if err != nil {
+		return nil, err
+	}
+	return a.minify(sourcePath, contents), nil
+}
+
+// bundle concatenates the source files of the bundle, minifying the result if needed
+func (a *Assets) bundle(key string, sourcePaths []string) ([]byte, error) {
+	separator := "\n"
+	if path.Ext(key) == ".js" {
+		// the statement separator handles files ending without a semicolon or with a line comment
+		separator = "\n;\n"
+	}
+
+	parts := make([]string, len(sourcePaths))
+	for i, sourcePath := range sourcePaths {
+		contents, err := ioutil.ReadFile(filepath.Join(a.settings.SourcePath, filepath.FromSlash(sourcePath)))
+		if err != nil {
+			return nil, fmt.Errorf("error reading source of bundle %v - %v", key, err)
+		}
+		parts[i] = string(contents)
+	}
+	return a.minify(key, []byte(strings.Join(parts, separator))), nil
+}
+
+func (a *Assets) minify(key string, contents []byte) []byte {
+	if !a.settings.Minify || strings.Contains(path.Base(key), ".min.") {
+		return contents
+	}
+	switch path.Ext(key) {
+	case ".css":
+		return []byte(MinifyCSS(string(contents)))
+	case ".js":
+		return []byte(MinifyJS(string(contents)))
+	}
+	return contents
+}
+
+// write writes the contents to the fingerprinted path of the key, returning the path relative to the generated assets path
+func (a *Assets) write(key string, contents []byte) (string, error) {
+	fingerprinted := fingerprintedPath(key, contents)
+	filePath := filepath.Join(a.generatedAssetsPath, filepath.FromSlash(fingerprinted))
+	if _, err := os.Stat(filePath); err == nil {
+		return fingerprinted, nil
+	}
+
+	if err := utils.MkdirAll(filepath.Dir(filePath)); err != nil {
+		return "", err
+	}
+	return fingerprinted, utils.WriteFile(filePath, contents)
+}
+
+// fingerprintedPath returns the key with the hash of the contents before its extension
+func fingerprintedPath(key string, contents []byte) string {
+	ext := path.Ext(key)
+	hash := md5.Sum(contents)
+	return fmt.Sprintf("%v-%v%v", strings.TrimSuffix(key, ext), hex.EncodeToString(hash[:])[:hashLength], ext)
+}
+
+func (a *Assets) manifestPath() string {
+	return filepath.Join(a.generatedAssetsPath, ManifestFilename)
+}
+
+func (a *Assets) writeManifest(manifest map[string]string) error {
+	if err := a.removeStale(manifest); err != nil {
+		return err
+	}
+	bytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFile(a.manifestPath(), bytes)
+}
+
+// removeStale removes the files of the previous manifest that are not in the manifest
+func (a *Assets) removeStale(manifest map[string]string) error {
+	bytes, err := ioutil.ReadFile(a.manifestPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	previous := map[string]string{}
+	if err = json.Unmarshal(bytes, &previous); err != nil {
+		a.log.Warnf("Skipping removal of stale assets, error reading previous manifest - %v", err)
+		return nil
+	}
+
+	current := map[string]bool{}
+	for _, value := range manifest {
+		current[value] = true
+	}
+	for _, value := range previous {
+		if current[value] || strings.HasPrefix(path.Clean(value), "../") {
+			continue
+		}
+		err = os.Remove(filepath.Join(a.generatedAssetsPath, filepath.FromSlash(value)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
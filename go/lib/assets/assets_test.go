@@ -0,0 +1,165 @@
+package assets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/urls"
+	"github.com/s12chung/gostatic/go/lib/utils"
+	"github.com/s12chung/gostatic/go/lib/webpack"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func defaultSettings() *Settings {
+	settings := DefaultSettings()
+	settings.SourcePath = filepath.Join(testfile.FixturePath, "src")
+	settings.Bundles = map[string][]string{
+		"main.css": {"css/base.css", "css/links.css"},
+		"main.js":  {"js/app.js", "js/main.js"},
+	}
+	return settings
+}
+
+func readGenerated(t *testing.T, generatedAssetsPath, filePath string) string {
+	bytes, err := ioutil.ReadFile(filepath.Join(generatedAssetsPath, filePath))
+	test.AssertError(t, err, "ioutil.ReadFile")
+	return string(bytes)
+}
+
+func TestAssets_Build(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "assets")
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	manifest, err := NewAssets(dir, defaultSettings(), log).Build()
+	test.AssertError(t, err, "Build")
+
+	exp := map[string]string{
+		"main.css":         "main-f951840a6f888c176710.css",
+		"main.js":          "main-2c85a81999a597ba137c.js",
+		"css/print.css":    "css/print-1be1ed778c1b2548bc61.css",
+		"js/vendor.min.js": "js/vendor.min-eb6068c257dbb23bac4c.js",
+		"images/logo.svg":  "images/logo-76286ea04f1beee22e26.svg",
+	}
+	test.AssertArray(t, "manifest", manifest, exp)
+
+	test.AssertLabel(t, "main.css", readGenerated(t, dir, manifest["main.css"]), `body{margin:0;color:#333}a:hover{content:"a  /* b */  c"}`)
+	test.AssertLabel(t, "main.js", readGenerated(t, dir, manifest["main.js"]), "function add(a, b) {\nreturn a + b;\n}\n;\nvar re = /\\/\\/[a-z]/g\nconsole.log(add(1, 2))")
+	test.AssertLabel(t, "vendor.min.js", readGenerated(t, dir, manifest["js/vendor.min.js"]), "var  x  =  1;\n")
+
+	webpackManifest := webpack.NewManifest(filepath.Dir(dir), filepath.Base(dir), urls.NewURLs(nil), log)
+	for key, value := range exp {
+		got, err := webpackManifest.ManifestValue(key)
+		test.AssertError(t, err, "webpackManifest.ManifestValue")
+		test.AssertLabel(t, "webpack.Manifest "+key, got, value)
+	}
+}
+
+func TestAssets_Build_NoMinify(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "assets")
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	settings := defaultSettings()
+	settings.Minify = false
+	manifest, err := NewAssets(dir, settings, log).Build()
+	test.AssertError(t, err, "Build")
+	test.AssertLabel(t, "print.css", readGenerated(t, dir, manifest["css/print.css"]), ".print { display: none; }\n")
+}
+
+func TestAssets_Build_RemoveStale(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "assets")
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	sourcePath := filepath.Join(dir, "src")
+	generatedAssetsPath := filepath.Join(dir, "generated")
+	test.AssertError(t, utils.MkdirAll(sourcePath), "utils.MkdirAll")
+	test.AssertError(t, utils.MkdirAll(generatedAssetsPath), "utils.MkdirAll")
+	sourceFile := filepath.Join(sourcePath, "a.css")
+	test.AssertError(t, utils.WriteFile(sourceFile, []byte("a { b: c }")), "utils.WriteFile")
+	otherFile := filepath.Join(generatedAssetsPath, "other.png")
+	test.AssertError(t, utils.WriteFile(otherFile, []byte("png")), "utils.WriteFile")
+
+	assets := NewAssets(generatedAssetsPath, &Settings{sourcePath, nil, true}, log)
+	first, err := assets.Build()
+	test.AssertError(t, err, "Build")
+
+	test.AssertError(t, utils.WriteFile(sourceFile, []byte("a { b: d }")), "utils.WriteFile")
+	second, err := assets.Build()
+	test.AssertError(t, err, "Build")
+
+	test.AssertLabel(t, "changed", first["a.css"] != second["a.css"], true)
+	_, err = os.Stat(filepath.Join(generatedAssetsPath, first["a.css"]))
+	test.AssertLabel(t, "stale removed", os.IsNotExist(err), true)
+	_, err = os.Stat(filepath.Join(generatedAssetsPath, second["a.css"]))
+	test.AssertError(t, err, "os.Stat new")
+	_, err = os.Stat(otherFile)
+	test.AssertError(t, err, "os.Stat other")
+}
+
+func TestAssets_Build_DotFiles(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "assets")
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	// the source path itself is walked, even if it's a dot directory
+	sourcePath := filepath.Join(dir, ".src")
+	for _, filePath := range []string{"a.css", ".hidden.css", ".cache/b.css", "sub/.git/c.css", "sub/d.css"} {
+		sourceFile := filepath.Join(sourcePath, filepath.FromSlash(filePath))
+		test.AssertError(t, utils.MkdirAll(filepath.Dir(sourceFile)), "utils.MkdirAll")
+		test.AssertError(t, utils.WriteFile(sourceFile, []byte("a { b: c }")), "utils.WriteFile")
+	}
+
+	manifest, err := NewAssets(filepath.Join(dir, "generated"), &Settings{sourcePath, nil, true}, log).Build()
+	test.AssertError(t, err, "Build")
+	keys := make([]string, 0, len(manifest))
+	for key := range manifest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	test.AssertArray(t, "keys", keys, []string{"a.css", "sub/d.css"})
+}
+
+func TestAssets_Build_Disabled(t *testing.T) {
+	log, _ := logTest.NewNullLogger()
+	manifest, err := NewAssets("generated", DefaultSettings(), log).Build()
+	test.AssertError(t, err, "Build")
+	test.AssertLabel(t, "manifest == nil", manifest == nil, true)
+}
+
+func TestAssets_Build_MissingBundleSource(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "assets")
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	settings := defaultSettings()
+	settings.Bundles["other.css"] = []string{"css/missing.css"}
+	_, err := NewAssets(dir, settings, log).Build()
+	test.AssertLabel(t, "err != nil", err != nil, true)
+}
+
+func TestFingerprintedPath(t *testing.T) {
+	testCases := []struct {
+		key string
+		exp string
+	}{
+		{"main.css", "main-5d41402abc4b2a76b971.css"},
+		{"a/b.min.js", "a/b.min-5d41402abc4b2a76b971.js"},
+		{"LICENSE", "LICENSE-5d41402abc4b2a76b971"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"key":   tc.key,
+		})
+		context.Assert("result", fingerprintedPath(tc.key, []byte("hello")), tc.exp)
+	}
+}
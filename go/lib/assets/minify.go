@@ -0,0 +1,303 @@
+package assets
+
+import (
+	"strings"
+)
+
+// minifier scans the source, copying to out
+type minifier struct {
+	src string
+	i   int
+	out []byte
+
+	// token is the last significant token copied by MinifyJS, with tokenAfterDot if it's a property name
+	token         string
+	tokenAfterDot bool
+}
+
+func (m *minifier) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(m.src[m.i:], prefix)
+}
+
+func (m *minifier) last() byte {
+	if len(m.out) == 0 {
+		return 0
+	}
+	return m.out[len(m.out)-1]
+}
+
+// copyQuoted copies the string starting at i, ending with the unescaped quote
+func (m *minifier) copyQuoted(quote byte) {
+	start := m.i
+	m.skipQuoted(quote)
+	m.out = append(m.out, m.src[start:m.i]...)
+}
+
+// skipQuoted skips the string starting at i, ending with the unescaped quote
+func (m *minifier) skipQuoted(quote byte) {
+	for m.i++; m.i < len(m.src); m.i++ {
+		if m.src[m.i] == '\\' {
+			m.i++
+			continue
+		}
+		if m.src[m.i] == quote {
+			m.i++
+			return
+		}
+	}
+	m.i = len(m.src)
+}
+
+// copyTemplate copies the template literal starting at i, with its ${ } expressions as is
+func (m *minifier) copyTemplate() {
+	start := m.i
+	m.skipTemplate()
+	m.out = append(m.out, m.src[start:m.i]...)
+}
+
+// skipTemplate skips the template literal starting at i, ending with the unescaped '`' outside of the ${ } expressions
+func (m *minifier) skipTemplate() {
+	for m.i++; m.i < len(m.src); {
+		switch {
+		case m.src[m.i] == '\\':
+			m.i += 2
+		case m.src[m.i] == '`':
+			m.i++
+			return
+		case m.hasPrefix("${"):
+			m.i += 2
+			m.skipTemplateExpression()
+		default:
+			m.i++
+		}
+	}
+	m.i = len(m.src)
+}
+
+// skipTemplateExpression skips the ${ } expression after the "${" at i, tracking the depth of the braces,
+// which may have strings and template literals with braces of their own
+func (m *minifier) skipTemplateExpression() {
+	depth := 1
+	for m.i < len(m.src) {
+		c := m.src[m.i]
+		switch {
+		case c == '"' || c == '\'':
+			m.skipQuoted(c)
+		case c == '`':
+			m.skipTemplate()
+		case c == '{':
+			depth++
+			m.i++
+		case c == '}':
+			depth--
+			m.i++
+			if depth == 0 {
+				return
+			}
+		default:
+			m.i++
+		}
+	}
+}
+
+// skipBlockComment skips the /* */ comment starting at i, returning true if it has a newline
+func (m *minifier) skipBlockComment() bool {
+	end := strings.Index(m.src[m.i+2:], "*/")
+	if end == -1 {
+		end = len(m.src)
+	} else {
+		end += m.i + 2 + len("*/")
+	}
+	hasNewline := strings.Contains(m.src[m.i:end], "\n")
+	m.i = end
+	return hasNewline
+}
+
+// skipSpace skips the whitespace starting at i, returning true if it has a newline
+func (m *minifier) skipSpace() bool {
+	hasNewline := false
+	for ; m.i < len(m.src) && isSpace(m.src[m.i]); m.i++ {
+		if m.src[m.i] == '\n' {
+			hasNewline = true
+		}
+	}
+	return hasNewline
+}
+
+func (m *minifier) trimRightSpace() {
+	for len(m.out) > 0 && isSpace(m.last()) {
+		m.out = m.out[:len(m.out)-1]
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// cssPunctuation is where the whitespace around can be removed, whitespace before ':' is kept for selectors (ex. `a :hover`)
+const cssPunctuation = "{};,>"
+
+// MinifyCSS removes the comments and the unneeded whitespace and semicolons of the CSS, keeping the strings as is
+func MinifyCSS(src string) string {
+	m := &minifier{src: src}
+	for m.i < len(m.src) {
+		c := m.src[m.i]
+		switch {
+		case c == '"' || c == '\'':
+			m.copyQuoted(c)
+		case m.hasPrefix("/*"):
+			m.skipBlockComment()
+			m.cssSpace()
+		case isSpace(c):
+			m.cssSpace()
+		case c == '}' && m.last() == ';':
+			m.out[len(m.out)-1] = c
+			m.i++
+		default:
+			m.out = append(m.out, c)
+			m.i++
+		}
+	}
+	return strings.TrimSpace(string(m.out))
+}
+
+// cssSpace skips the whitespace and comments at i, writing a space only if it's needed
+func (m *minifier) cssSpace() {
+	for m.i < len(m.src) {
+		if m.hasPrefix("/*") {
+			m.skipBlockComment()
+		} else if isSpace(m.src[m.i]) {
+			m.skipSpace()
+		} else {
+			break
+		}
+	}
+	if len(m.out) == 0 || m.i >= len(m.src) {
+		return
+	}
+	if strings.IndexByte(cssPunctuation+":(", m.last()) != -1 || strings.IndexByte(cssPunctuation+")", m.src[m.i]) != -1 {
+		return
+	}
+	m.out = append(m.out, ' ')
+}
+
+// regexPrecedingChars are the chars that a regex literal can follow, otherwise '/' is division
+const regexPrecedingChars = "(,=:[!&|?{};+-*%<>~^"
+
+// regexPrecedingKeywords are the keywords that a regex literal can follow
+var regexPrecedingKeywords = []string{"return", "typeof", "case", "do", "else", "in", "of", "new", "delete", "void", "throw", "yield", "await"}
+
+// MinifyJS removes the comments, indentation and blank lines of the JS, keeping the strings, template literals
+// and regex literals as is. It's conservative: the newlines are kept, so automatic semicolon insertion is unchanged.
+func MinifyJS(src string) string {
+	m := &minifier{src: src}
+	for m.i < len(m.src) {
+		c := m.src[m.i]
+		switch {
+		case c == '"' || c == '\'':
+			m.copyQuoted(c)
+			m.token = string(c)
+		case c == '`':
+			m.copyTemplate()
+			m.token = string(c)
+		case m.hasPrefix("//"):
+			for m.i < len(m.src) && m.src[m.i] != '\n' {
+				m.i++
+			}
+		case m.hasPrefix("/*"):
+			m.jsSpace(m.skipBlockComment())
+		case c == '/' && m.regexAllowed():
+			m.copyRegex()
+			m.token = "/"
+		case isSpace(c):
+			m.jsSpace(m.skipSpace())
+		default:
+			m.setJSToken(c)
+			m.out = append(m.out, c)
+			m.i++
+		}
+	}
+	return strings.TrimSpace(string(m.out))
+}
+
+// setJSToken sets the token given the char c about to be copied, which continues the identifier, number,
+// "++" or "--" token if it's directly after it
+func (m *minifier) setJSToken(c byte) {
+	switch {
+	case isIdentifierChar(c) && m.token != "" && isIdentifierChar(m.token[0]) && isIdentifierChar(m.last()):
+		m.token += string(c)
+	case isIdentifierChar(c):
+		m.tokenAfterDot = m.token == "."
+		m.token = string(c)
+	case (c == '+' || c == '-') && m.token == string(c) && m.last() == c:
+		m.token += string(c)
+	default:
+		m.token = string(c)
+	}
+}
+
+// jsSpace writes a newline if the skipped whitespace had one, otherwise a space, without indentation or blank lines
+func (m *minifier) jsSpace(hasNewline bool) {
+	if hasNewline {
+		m.trimRightSpace()
+		if len(m.out) > 0 {
+			m.out = append(m.out, '\n')
+		}
+		return
+	}
+	if len(m.out) > 0 && !isSpace(m.last()) {
+		m.out = append(m.out, ' ')
+	}
+}
+
+// regexAllowed returns true if a '/' at i starts a regex literal, given the token before it
+func (m *minifier) regexAllowed() bool {
+	switch {
+	case m.token == "":
+		return true
+	case m.token == "++" || m.token == "--":
+		// postfix, as a regex literal can't be incremented
+		return false
+	case isIdentifierChar(m.token[0]):
+		return !m.tokenAfterDot && isRegexPrecedingKeyword(m.token)
+	}
+	return strings.Contains(regexPrecedingChars, m.token)
+}
+
+func isRegexPrecedingKeyword(token string) bool {
+	for _, keyword := range regexPrecedingKeywords {
+		if token == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// copyRegex copies the regex literal starting at i, ending with the unescaped '/' outside of a character class
+func (m *minifier) copyRegex() {
+	start := m.i
+	inClass := false
+	for m.i++; m.i < len(m.src) && m.src[m.i] != '\n'; m.i++ {
+		c := m.src[m.i]
+		if c == '\\' {
+			m.i++
+			continue
+		}
+		if c == '[' {
+			inClass = true
+		} else if c == ']' {
+			inClass = false
+		} else if c == '/' && !inClass {
+			m.i++
+			break
+		}
+	}
+	if m.i > len(m.src) {
+		m.i = len(m.src)
+	}
+	m.out = append(m.out, m.src[start:m.i]...)
+}
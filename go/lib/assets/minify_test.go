@@ -0,0 +1,72 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestMinifyCSS(t *testing.T) {
+	testCases := []struct {
+		src string
+		exp string
+	}{
+		{"", ""},
+		{"body {\n  margin: 0;\n  color: #333;\n}\n", "body{margin:0;color:#333}"},
+		{"/* comment */\na , b > c { }", "a,b>c{}"},
+		{"a :hover { color : red }", "a :hover{color :red}"},
+		{"@media screen and (max-width: 100px) { a { b: c; } }", "@media screen and (max-width:100px){a{b:c}}"},
+		{`a { content: "x  /* y */  ;}" ; }`, `a{content:"x  /* y */  ;}"}`},
+		{`a { content: 'it\'s  ok'; }`, `a{content:'it\'s  ok'}`},
+		{"a { width: calc(1px + 2px); margin: 0 auto }", "a{width:calc(1px + 2px);margin:0 auto}"},
+		{"a/* x */b {}", "a b{}"},
+		{"a { b: c; } /* unclosed", "a{b:c}"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"src":   tc.src,
+		})
+		context.Assert("result", MinifyCSS(tc.src), tc.exp)
+	}
+}
+
+func TestMinifyJS(t *testing.T) {
+	testCases := []struct {
+		src string
+		exp string
+	}{
+		{"", ""},
+		{"// comment\nfunction add(a, b) {\n    return a + b; // sum\n}\n", "function add(a, b) {\nreturn a + b;\n}"},
+		{"var a = 1\n\n\n\nvar b = 2", "var a = 1\nvar b = 2"},
+		{"var   a   =   1;", "var a = 1;"},
+		{"var a = 1 /* x */ + 2;\nvar b /* multi\nline */ = 3", "var a = 1 + 2;\nvar b\n= 3"},
+		{`var s = "// not a comment";`, `var s = "// not a comment";`},
+		{`var s = 'it\'s /* kept */';`, `var s = 'it\'s /* kept */';`},
+		{"var t = `a\n    // kept ${b}`;", "var t = `a\n    // kept ${b}`;"},
+		{"var t = `a ${b ? `c ${d}` : \"}`\"}  // kept`;  // comment", "var t = `a ${b ? `c ${d}` : \"}`\"}  // kept`;"},
+		{"var t = `${{a: 1}.a}  /* kept */`;", "var t = `${{a: 1}.a}  /* kept */`;"},
+		{"var t = `\\${a}  // kept`;", "var t = `\\${a}  // kept`;"},
+		{`var re = /\/\/[/"]+/g; // comment`, `var re = /\/\/[/"]+/g;`},
+		{"function f() { return /'/.test(x) }", "function f() { return /'/.test(x) }"},
+		{`var c = a / b / "x";`, `var c = a / b / "x";`},
+		{`var c = x.return / 2 / 'y';`, `var c = x.return / 2 / 'y';`},
+		{"var c = i++ / 2 // half", "var c = i++ / 2"},
+		{"var c = x-- / y /* ratio */ + 1", "var c = x-- / y + 1"},
+		{"var c = a++ /b // c", "var c = a++ /b"},
+		{"var c = a++ /b/ c  // comment", "var c = a++ /b/ c"},
+		{"var c = a + /b/.source;  // comment", "var c = a + /b/.source;"},
+		{"x = y\n++z / 2 / 'w'", "x = y\n++z / 2 / 'w'"},
+		{"var c = typeof /'/  // comment", "var c = typeof /'/"},
+		{"var c = (a) / 2 / 'b'; var d = [1]/2/'e'", "var c = (a) / 2 / 'b'; var d = [1]/2/'e'"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"src":   tc.src,
+		})
+		context.Assert("result", MinifyJS(tc.src), tc.exp)
+	}
+}
@@ -0,0 +1,72 @@
+package assets
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Settings is the settings of this package
+type Settings struct {
+	// SourcePath is the directory of the source assets, an empty SourcePath disables Assets.
+	// The paths relative to it are the keys of the manifest.
+	SourcePath string `json:"source_path,omitempty"`
+	// Bundles maps a bundle key (ex. "main.css") to the source paths concatenated into it, in order.
+	// The source paths are relative to SourcePath and are not written on their own.
+	// A bundle key can't be the path of a source file that is not in a bundle.
+	Bundles map[string][]string `json:"bundles,omitempty"`
+	// Minify minifies the CSS and JS files, except for *.min.css and *.min.js files
+	Minify bool `json:"minify,omitempty"`
+}
+
+// DefaultSettings returns the default settings of this package, which has Assets disabled
+func DefaultSettings() *Settings {
+	return &Settings{
+		"",
+		nil,
+		true,
+	}
+}
+
+// Validate returns an error if the settings are invalid
+func (s *Settings) Validate() error {
+	if s.SourcePath == "" {
+		return nil
+	}
+	for key, sourcePaths := range s.Bundles {
+		ext := path.Ext(key)
+		if ext != ".css" && ext != ".js" {
+			return fmt.Errorf("bundle %v must be a .css or .js file", key)
+		}
+		if len(sourcePaths) == 0 {
+			return fmt.Errorf("bundle %v has no source paths", key)
+		}
+		for _, sourcePath := range sourcePaths {
+			if path.Ext(sourcePath) != ext {
+				return fmt.Errorf("bundle %v has a source path with a different extension: %v", key, sourcePath)
+			}
+		}
+	}
+	return s.validateBundleKeys()
+}
+
+// validateBundleKeys returns an error if a bundle key is the path of a source file that is not bundled,
+// as both would be written to the same path
+func (s *Settings) validateBundleKeys() error {
+	bundled := map[string]bool{}
+	for _, sourcePaths := range s.Bundles {
+		for _, sourcePath := range sourcePaths {
+			bundled[path.Clean(sourcePath)] = true
+		}
+	}
+	for key := range s.Bundles {
+		if bundled[path.Clean(key)] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.SourcePath, filepath.FromSlash(key))); err == nil {
+			return fmt.Errorf("bundle %v has the path of a source file that is not bundled", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		sourcePath string
+		bundles    map[string][]string
+		err        bool
+	}{
+		{"", map[string][]string{"main.scss": nil}, false},
+		{"assets", nil, false},
+		{"assets", map[string][]string{"main.css": {"a.css", "b/c.css"}, "main.js": {"a.js"}}, false},
+		{"assets", map[string][]string{"main.scss": {"a.scss"}}, true},
+		{"assets", map[string][]string{"main.css": {}}, true},
+		{"assets", map[string][]string{"main.css": {"a.css", "b.js"}}, true},
+		{"testdata/src", map[string][]string{"js/main.js": {"js/app.js"}}, true},
+		{"testdata/src", map[string][]string{"js/main.js": {"js/main.js", "js/app.js"}}, false},
+		{"testdata/src", map[string][]string{"js/bundle.js": {"js/main.js", "js/app.js"}}, false},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"sourcePath": tc.sourcePath,
+			"bundles":    tc.bundles,
+		})
+		settings := &Settings{tc.sourcePath, tc.bundles, true}
+		context.Assert("err != nil", settings.Validate() != nil, tc.err)
+	}
+}
@@ -0,0 +1,4 @@
+// app
+function add(a, b) {
+  return a + b; // sum
+}
@@ -0,0 +1,2 @@
+var re = /\/\/[a-z]/g
+console.log(add(1, 2))
\ No newline at end of file
@@ -0,0 +1,273 @@
+/*
+Package favicon generates the favicons, web manifest and browserconfig of a site from a single source image,
+served as routes, with a `faviconTags` template function for the matching <link> and <meta> tags.
+
+Favicon struct implements github.com/s12chung/gostatic/go/lib/html.Plugin
+*/
+package favicon
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/filecache"
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/urls"
+)
+
+// The file names of the generated files, under Settings.Path
+const (
+	ICOName           = "favicon.ico"
+	SVGName           = "favicon.svg"
+	WebManifestName   = "site.webmanifest"
+	BrowserConfigName = "browserconfig.xml"
+)
+
+// ICOSizes are the sizes of the images in the favicon.ico
+var ICOSizes = []int{16, 32, 48}
+
+// Icon is a generated PNG icon
+type Icon struct {
+	Name string
+	Size int
+	// Filled icons have the Settings.BackgroundColor instead of transparency, for the platforms that fill it with black
+	Filled bool
+}
+
+// Icons are the generated PNG icons
+var Icons = []Icon{
+	{"favicon-16x16.png", 16, false},
+	{"favicon-32x32.png", 32, false},
+	{"apple-touch-icon.png", 180, true},
+	{"android-chrome-192x192.png", 192, false},
+	{"android-chrome-512x512.png", 512, false},
+	{"mstile-150x150.png", 150, false},
+}
+
+// Favicon generates the favicons from Settings.SourcePath
+type Favicon struct {
+	settings *Settings
+	urls     *urls.URLs
+
+	// sources caches the sourceImage of Settings.SourcePath
+	sources *filecache.Files
+
+	log logrus.FieldLogger
+}
+
+// NewFavicon returns a new instance of Favicon, the urls are applied to the URLs of the tags and web manifest
+func NewFavicon(settings *Settings, u *urls.URLs, log logrus.FieldLogger) *Favicon {
+	favicon := &Favicon{
+		settings,
+		u,
+		nil,
+		log,
+	}
+	favicon.sources = filecache.NewFiles(favicon.parseSource)
+	return favicon
+}
+
+// Enabled returns true if Settings.SourcePath is set
+func (f *Favicon) Enabled() bool {
+	return f.settings != nil && f.settings.SourcePath != ""
+}
+
+// IsSVG returns true if the source image is a SVG, which is also served as favicon.svg
+func (f *Favicon) IsSVG() bool {
+	return strings.EqualFold(filepath.Ext(f.settings.SourcePath), ".svg")
+}
+
+// URL returns the URL of the generated file name
+func (f *Favicon) URL(name string) string {
+	return f.urls.RelURL(path.Join(f.settings.Path, name))
+}
+
+// SetRoutes defines the routes of the icons, favicon.ico, web manifest and browserconfig, if Enabled
+func (f *Favicon) SetRoutes(r router.Router) {
+	if !f.Enabled() {
+		return
+	}
+	for _, icon := range Icons {
+		icon := icon
+		f.get(r, icon.Name, "", func() ([]byte, error) { return f.PNG(icon) })
+	}
+	f.get(r, ICOName, "image/x-icon", f.ICO)
+	if f.IsSVG() {
+		f.get(r, SVGName, "image/svg+xml", f.SVG)
+	}
+	f.get(r, WebManifestName, "application/manifest+json", f.WebManifest)
+	f.get(r, BrowserConfigName, "", f.BrowserConfig)
+}
+
+// get defines a route of the name, with the contentType if not empty
+func (f *Favicon) get(r router.Router, name, contentType string, generate func() ([]byte, error)) {
+	r.Get(path.Join(f.settings.Path, name), func(ctx router.Context) error {
+		bytes, err := generate()
+		if err != nil {
+			return err
+		}
+		if contentType != "" {
+			ctx.SetContentType(contentType)
+		}
+		ctx.Respond(bytes)
+		return nil
+	})
+}
+
+// PNG returns the PNG of the icon
+func (f *Favicon) PNG(icon Icon) ([]byte, error) {
+	source, err := f.getSource()
+	if err != nil {
+		return nil, err
+	}
+	var background color.Color
+	if icon.Filled {
+		background = parseHexColor(f.settings.BackgroundColor)
+	}
+	img, err := source.render(icon.Size, background)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(img)
+}
+
+// ICO returns the favicon.ico with the ICOSizes
+func (f *Favicon) ICO() ([]byte, error) {
+	source, err := f.getSource()
+	if err != nil {
+		return nil, err
+	}
+	pngs := make([][]byte, len(ICOSizes))
+	for i, size := range ICOSizes {
+		var img *image.RGBA
+		if img, err = source.render(size, nil); err != nil {
+			return nil, err
+		}
+		if pngs[i], err = encodePNG(img); err != nil {
+			return nil, err
+		}
+	}
+	return encodeICO(ICOSizes, pngs)
+}
+
+// SVG returns the source SVG
+func (f *Favicon) SVG() ([]byte, error) {
+	source, err := f.getSource()
+	if err != nil {
+		return nil, err
+	}
+	if source.svg == nil {
+		return nil, fmt.Errorf("favicon source is not a SVG: %v", f.settings.SourcePath)
+	}
+	return source.svg, nil
+}
+
+type webManifestIcon struct {
+	Src   string `json:"src"`
+	Sizes string `json:"sizes"`
+	Type  string `json:"type"`
+}
+
+type webManifest struct {
+	Name            string            `json:"name"`
+	ShortName       string            `json:"short_name"`
+	Icons           []webManifestIcon `json:"icons"`
+	ThemeColor      string            `json:"theme_color"`
+	BackgroundColor string            `json:"background_color"`
+	Display         string            `json:"display"`
+}
+
+// WebManifest returns the site.webmanifest JSON, with the android-chrome icons
+func (f *Favicon) WebManifest() ([]byte, error) {
+	var icons []webManifestIcon
+	for _, icon := range Icons {
+		if strings.HasPrefix(icon.Name, "android-chrome-") {
+			icons = append(icons, webManifestIcon{f.URL(icon.Name), sizes(icon.Size), "image/png"})
+		}
+	}
+	manifest := webManifest{
+		f.settings.Name,
+		f.settings.ShortName,
+		icons,
+		f.settings.ThemeColor,
+		f.settings.BackgroundColor,
+		f.settings.Display,
+	}
+	return json.MarshalIndent(manifest, "", "    ")
+}
+
+// BrowserConfig returns the browserconfig.xml of the Windows tile
+func (f *Favicon) BrowserConfig() ([]byte, error) {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<browserconfig>
+    <msapplication>
+        <tile>
+            <square150x150logo src="%v"/>
+            <TileColor>%v</TileColor>
+        </tile>
+    </msapplication>
+</browserconfig>
+`, template.HTMLEscapeString(f.URL("mstile-150x150.png")), template.HTMLEscapeString(f.settings.TileColor))), nil
+}
+
+// Tags returns the <link> and <meta> tags of the generated files, empty if not Enabled
+func (f *Favicon) Tags() template.HTML {
+	if !f.Enabled() {
+		return ""
+	}
+
+	var tags []string
+	if f.IsSVG() {
+		tags = append(tags, fmt.Sprintf(`<link rel="icon" type="image/svg+xml" href="%v">`, f.escapedURL(SVGName)))
+	}
+	tags = append(tags,
+		fmt.Sprintf(`<link rel="icon" type="image/png" sizes="32x32" href="%v">`, f.escapedURL("favicon-32x32.png")),
+		fmt.Sprintf(`<link rel="icon" type="image/png" sizes="16x16" href="%v">`, f.escapedURL("favicon-16x16.png")),
+		fmt.Sprintf(`<link rel="apple-touch-icon" sizes="180x180" href="%v">`, f.escapedURL("apple-touch-icon.png")),
+		fmt.Sprintf(`<link rel="manifest" href="%v">`, f.escapedURL(WebManifestName)),
+		fmt.Sprintf(`<meta name="msapplication-config" content="%v">`, f.escapedURL(BrowserConfigName)),
+		fmt.Sprintf(`<meta name="msapplication-TileColor" content="%v">`, template.HTMLEscapeString(f.settings.TileColor)),
+		fmt.Sprintf(`<meta name="theme-color" content="%v">`, template.HTMLEscapeString(f.settings.ThemeColor)),
+	)
+	return template.HTML(strings.Join(tags, "\n"))
+}
+
+func (f *Favicon) escapedURL(name string) string {
+	return template.HTMLEscapeString(f.URL(name))
+}
+
+func sizes(size int) string {
+	return fmt.Sprintf("%vx%v", size, size)
+}
+
+// TemplateFuncs returns the template functions of this package
+func (f *Favicon) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"faviconTags": f.Tags,
+	}
+}
+
+// getSource returns the decoded source image, decoding it again when its modtime changes
+func (f *Favicon) getSource() (*sourceImage, error) {
+	source, err := f.sources.Get(f.settings.SourcePath)
+	if err != nil {
+		return nil, err
+	}
+	return source.(*sourceImage), nil
+}
+
+func (f *Favicon) parseSource(bytes []byte) (interface{}, error) {
+	source, err := newSourceImage(bytes, f.IsSVG())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding favicon source %v - %v", f.settings.SourcePath, err)
+	}
+	return source, nil
+}
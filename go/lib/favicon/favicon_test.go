@@ -0,0 +1,296 @@
+package favicon
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/urls"
+	"github.com/s12chung/gostatic/go/lib/utils"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func readSource(t *testing.T, name string, isSVG bool) *sourceImage {
+	imageBytes, err := ioutil.ReadFile(filepath.Join(testfile.FixturePath, name))
+	test.AssertError(t, err, "ioutil.ReadFile")
+	source, err := newSourceImage(imageBytes, isSVG)
+	test.AssertError(t, err, "newSourceImage")
+	return source
+}
+
+func defaultFavicon(sourceName string, urlSettings *urls.Settings) *Favicon {
+	log, _ := logTest.NewNullLogger()
+	settings := DefaultSettings()
+	if sourceName != "" {
+		settings.SourcePath = filepath.Join(testfile.FixturePath, sourceName)
+	}
+	settings.Name = "The Site"
+	settings.ShortName = "Site"
+	return NewFavicon(settings, urls.NewURLs(urlSettings), log)
+}
+
+func TestFavicon_SetRoutes(t *testing.T) {
+	testCases := []struct {
+		sourceName string
+		path       string
+		exp        []string
+	}{
+		{"", "/", []string{}},
+		{"source.png", "/", []string{
+			"/android-chrome-192x192.png", "/android-chrome-512x512.png", "/apple-touch-icon.png", "/browserconfig.xml",
+			"/favicon-16x16.png", "/favicon-32x32.png", "/favicon.ico", "/mstile-150x150.png", "/site.webmanifest",
+		}},
+		{"source.svg", "/favicon", []string{
+			"/favicon/android-chrome-192x192.png", "/favicon/android-chrome-512x512.png", "/favicon/apple-touch-icon.png", "/favicon/browserconfig.xml",
+			"/favicon/favicon-16x16.png", "/favicon/favicon-32x32.png", "/favicon/favicon.ico", "/favicon/favicon.svg", "/favicon/mstile-150x150.png", "/favicon/site.webmanifest",
+		}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"sourceName": tc.sourceName,
+			"path":       tc.path,
+		})
+
+		favicon := defaultFavicon(tc.sourceName, nil)
+		favicon.settings.Path = tc.path
+		r := router.NewGenerateRouter(favicon.log)
+		favicon.SetRoutes(r)
+
+		got := r.URLs()
+		sort.Strings(got)
+		context.AssertArray("URLs", got, tc.exp)
+	}
+}
+
+func TestFavicon_NilSettings(t *testing.T) {
+	log, _ := logTest.NewNullLogger()
+	favicon := NewFavicon(nil, urls.NewURLs(nil), log)
+	test.AssertLabel(t, "Enabled", favicon.Enabled(), false)
+
+	r := router.NewGenerateRouter(log)
+	favicon.SetRoutes(r)
+	test.AssertArray(t, "URLs", r.URLs(), []string{})
+	test.AssertLabel(t, "Tags", favicon.Tags(), template.HTML(""))
+}
+
+func TestFavicon_SetRoutes_Responses(t *testing.T) {
+	favicon := defaultFavicon("source.svg", nil)
+	r := router.NewGenerateRouter(favicon.log)
+	favicon.SetRoutes(r)
+
+	svgBytes, err := ioutil.ReadFile(favicon.settings.SourcePath)
+	test.AssertError(t, err, "ioutil.ReadFile")
+
+	testCases := []struct {
+		url      string
+		mimeType string
+		size     int
+	}{
+		{"/favicon-16x16.png", "image/png", 16},
+		{"/apple-touch-icon.png", "image/png", 180},
+		{"/android-chrome-512x512.png", "image/png", 512},
+		{"/favicon.ico", "image/x-icon", 0},
+		{"/favicon.svg", "image/svg+xml", 0},
+		{"/site.webmanifest", "application/manifest+json", 0},
+		{"/browserconfig.xml", "text/xml; charset=utf-8", 0},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"url":   tc.url,
+		})
+
+		response, err := r.Requester().Get(tc.url)
+		context.AssertError(err, "Requester.Get")
+		context.Assert("MimeType", response.MimeType, tc.mimeType)
+
+		switch tc.mimeType {
+		case "image/png":
+			config, _, err := image.DecodeConfig(bytes.NewReader(response.Body))
+			context.AssertError(err, "image.DecodeConfig")
+			context.Assert("Width", config.Width, tc.size)
+			context.Assert("Height", config.Height, tc.size)
+		case "image/x-icon":
+			context.AssertArray("header", response.Body[:6], []byte{0, 0, 1, 0, byte(len(ICOSizes)), 0})
+		case "image/svg+xml":
+			context.Assert("Body", string(response.Body), string(svgBytes))
+		}
+	}
+}
+
+func TestFavicon_PNG(t *testing.T) {
+	favicon := defaultFavicon("source.png", nil)
+	favicon.settings.BackgroundColor = "#00ff00"
+
+	for _, icon := range Icons {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"icon": icon.Name,
+		})
+
+		pngBytes, err := favicon.PNG(icon)
+		context.AssertError(err, "favicon.PNG")
+		img, _, err := image.Decode(bytes.NewReader(pngBytes))
+		context.AssertError(err, "image.Decode")
+		context.Assert("Bounds", img.Bounds(), image.Rect(0, 0, icon.Size, icon.Size))
+
+		// the source is wider than it's tall, so the top is the background
+		_, green, _, alpha := img.At(icon.Size/2, 0).RGBA()
+		context.Assert("filled", green == 0xffff && alpha == 0xffff, icon.Filled)
+	}
+}
+
+func TestFavicon_Errors(t *testing.T) {
+	testCases := []struct {
+		sourceName string
+		isSVG      bool
+	}{
+		{"missing.png", false},
+		{"invalid.png", false},
+		{"source.png", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"sourceName": tc.sourceName,
+		})
+
+		favicon := defaultFavicon(tc.sourceName, nil)
+		var err error
+		if tc.isSVG {
+			_, err = favicon.SVG()
+		} else {
+			_, err = favicon.ICO()
+		}
+		context.Assert("err != nil", err != nil, true)
+	}
+}
+
+func TestFavicon_getSource(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "favicon")
+	defer clean()
+	test.AssertError(t, utils.MkdirAll(dir), "utils.MkdirAll")
+
+	sourcePath := filepath.Join(dir, "source.svg")
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 8 8"><rect width="8" height="8" fill="%v"/></svg>`
+	test.AssertError(t, ioutil.WriteFile(sourcePath, []byte(fmt.Sprintf(svg, "#000000")), 0600), "ioutil.WriteFile")
+
+	favicon := defaultFavicon("", nil)
+	favicon.settings.SourcePath = sourcePath
+	got, err := favicon.SVG()
+	test.AssertError(t, err, "favicon.SVG")
+	test.AssertLabel(t, "first", string(got), fmt.Sprintf(svg, "#000000"))
+
+	test.AssertError(t, ioutil.WriteFile(sourcePath, []byte(fmt.Sprintf(svg, "#ffffff")), 0600), "ioutil.WriteFile")
+	modTime := time.Now().Add(time.Minute)
+	test.AssertError(t, os.Chtimes(sourcePath, modTime, modTime), "os.Chtimes")
+	got, err = favicon.SVG()
+	test.AssertError(t, err, "favicon.SVG")
+	test.AssertLabel(t, "reloaded", string(got), fmt.Sprintf(svg, "#ffffff"))
+}
+
+func TestFavicon_WebManifest(t *testing.T) {
+	favicon := defaultFavicon("source.png", &urls.Settings{BasePath: "/docs/"})
+	got, err := favicon.WebManifest()
+	test.AssertError(t, err, "favicon.WebManifest")
+
+	exp := `{
+    "name": "The Site",
+    "short_name": "Site",
+    "icons": [
+        {
+            "src": "/docs/android-chrome-192x192.png",
+            "sizes": "192x192",
+            "type": "image/png"
+        },
+        {
+            "src": "/docs/android-chrome-512x512.png",
+            "sizes": "512x512",
+            "type": "image/png"
+        }
+    ],
+    "theme_color": "#ffffff",
+    "background_color": "#ffffff",
+    "display": "standalone"
+}`
+	test.AssertLabel(t, "result", string(got), exp)
+}
+
+func TestFavicon_BrowserConfig(t *testing.T) {
+	favicon := defaultFavicon("source.png", nil)
+	favicon.settings.Path = "/favicon/"
+	got, err := favicon.BrowserConfig()
+	test.AssertError(t, err, "favicon.BrowserConfig")
+
+	exp := `<?xml version="1.0" encoding="utf-8"?>
+<browserconfig>
+    <msapplication>
+        <tile>
+            <square150x150logo src="/favicon/mstile-150x150.png"/>
+            <TileColor>#000000</TileColor>
+        </tile>
+    </msapplication>
+</browserconfig>
+`
+	test.AssertLabel(t, "result", string(got), exp)
+}
+
+func TestFavicon_Tags(t *testing.T) {
+	pngTags := []string{
+		`<link rel="icon" type="image/png" sizes="32x32" href="%[1]v/favicon-32x32.png">`,
+		`<link rel="icon" type="image/png" sizes="16x16" href="%[1]v/favicon-16x16.png">`,
+		`<link rel="apple-touch-icon" sizes="180x180" href="%[1]v/apple-touch-icon.png">`,
+		`<link rel="manifest" href="%[1]v/site.webmanifest">`,
+		`<meta name="msapplication-config" content="%[1]v/browserconfig.xml">`,
+		`<meta name="msapplication-TileColor" content="#000000">`,
+		`<meta name="theme-color" content="#ffffff">`,
+	}
+	svgTag := `<link rel="icon" type="image/svg+xml" href="%[1]v/favicon.svg">`
+
+	testCases := []struct {
+		sourceName  string
+		urlSettings *urls.Settings
+		exp         string
+	}{
+		{"", nil, ""},
+		{"source.png", nil, fmt.Sprintf(strings.Join(pngTags, "\n"), "")},
+		{"source.png", &urls.Settings{BasePath: "/docs/"}, fmt.Sprintf(strings.Join(pngTags, "\n"), "/docs")},
+		{"source.svg", nil, fmt.Sprintf(strings.Join(append([]string{svgTag}, pngTags...), "\n"), "")},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":       testCaseIndex,
+			"sourceName":  tc.sourceName,
+			"urlSettings": tc.urlSettings,
+		})
+
+		favicon := defaultFavicon(tc.sourceName, tc.urlSettings)
+		context.Assert("result", favicon.Tags(), template.HTML(tc.exp))
+	}
+}
+
+func TestFavicon_TemplateFuncs(t *testing.T) {
+	favicon := defaultFavicon("source.png", nil)
+	tmpl, err := template.New("test").Funcs(favicon.TemplateFuncs()).Parse(`{{ faviconTags }}`)
+	test.AssertError(t, err, "template.Parse")
+
+	buffer := &bytes.Buffer{}
+	test.AssertError(t, tmpl.Execute(buffer, nil), "tmpl.Execute")
+	test.AssertLabel(t, "result", buffer.String(), string(favicon.Tags()))
+}
@@ -0,0 +1,143 @@
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	// image formats of the source image
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	xdraw "golang.org/x/image/draw"
+)
+
+// sourceImage is the decoded source image, SVG sources are kept as bytes to be rasterized to each size
+type sourceImage struct {
+	raster image.Image
+	svg    []byte
+}
+
+func newSourceImage(imageBytes []byte, isSVG bool) (*sourceImage, error) {
+	if isSVG {
+		// parse to give the error early
+		if _, err := oksvg.ReadIconStream(bytes.NewReader(imageBytes)); err != nil {
+			return nil, err
+		}
+		return &sourceImage{nil, imageBytes}, nil
+	}
+	raster, _, err := image.Decode(bytes.NewReader(imageBytes))
+	if err != nil {
+		return nil, err
+	}
+	return &sourceImage{raster, nil}, nil
+}
+
+// render returns a square image of the size with the source centered and scaled to fit, over the background if not nil
+func (source *sourceImage) render(size int, background color.Color) (*image.RGBA, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	if background != nil {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	}
+
+	if source.svg == nil {
+		bounds := source.raster.Bounds()
+		xdraw.CatmullRom.Scale(dst, fitRect(float64(bounds.Dx()), float64(bounds.Dy()), size), source.raster, bounds, xdraw.Over, nil)
+		return dst, nil
+	}
+
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(source.svg))
+	if err != nil {
+		return nil, err
+	}
+	rect := fitRect(icon.ViewBox.W, icon.ViewBox.H, size)
+	icon.SetTarget(float64(rect.Min.X), float64(rect.Min.Y), float64(rect.Dx()), float64(rect.Dy()))
+	icon.Draw(rasterx.NewDasher(size, size, rasterx.NewScannerGV(size, size, dst, dst.Bounds())), 1)
+	return dst, nil
+}
+
+// fitRect returns the rect of the width and height scaled to fit in the size square, centered
+func fitRect(width, height float64, size int) image.Rectangle {
+	if width <= 0 || height <= 0 {
+		return image.Rect(0, 0, size, size)
+	}
+	scale := math.Min(float64(size)/width, float64(size)/height)
+	fitWidth := int(math.Max(1, math.Round(width*scale)))
+	fitHeight := int(math.Max(1, math.Round(height*scale)))
+	x, y := (size-fitWidth)/2, (size-fitHeight)/2
+	return image.Rect(x, y, x+fitWidth, y+fitHeight)
+}
+
+// parseHexColor parses a #rgb or #rrggbb color, validated by Settings.Validate
+func parseHexColor(hex string) color.Color {
+	hex = hex[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	var rgb [3]uint8
+	for i := range rgb {
+		rgb[i] = hexByte(hex[i*2])<<4 | hexByte(hex[i*2+1])
+	}
+	return color.RGBA{rgb[0], rgb[1], rgb[2], 255}
+}
+
+func hexByte(c byte) uint8 {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	}
+	return c - '0'
+}
+
+// encodeICO encodes the PNGs of the sizes into an ICO, which supports PNG images since Windows Vista
+func encodeICO(sizes []int, pngs [][]byte) ([]byte, error) {
+	const headerLength, entryLength = 6, 16
+
+	buffer := &bytes.Buffer{}
+	// reserved, type (1 is icon), image count
+	header := []uint16{0, 1, uint16(len(pngs))}
+	if err := binary.Write(buffer, binary.LittleEndian, header); err != nil {
+		return nil, err
+	}
+
+	offset := headerLength + entryLength*len(pngs)
+	for i, pngBytes := range pngs {
+		entry := struct {
+			Width, Height, ColorCount, Reserved uint8
+			Planes, BitCount                    uint16
+			BytesLength, Offset                 uint32
+		}{icoDimension(sizes[i]), icoDimension(sizes[i]), 0, 0, 1, 32, uint32(len(pngBytes)), uint32(offset)}
+		if err := binary.Write(buffer, binary.LittleEndian, entry); err != nil {
+			return nil, err
+		}
+		offset += len(pngBytes)
+	}
+	for _, pngBytes := range pngs {
+		buffer.Write(pngBytes)
+	}
+	return buffer.Bytes(), nil
+}
+
+// icoDimension returns the ICO dimension byte of the size, where 0 is 256
+func icoDimension(size int) uint8 {
+	if size >= 256 {
+		return 0
+	}
+	return uint8(size)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := png.Encode(buffer, img); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,133 @@
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestFitRect(t *testing.T) {
+	testCases := []struct {
+		width  float64
+		height float64
+		size   int
+		exp    image.Rectangle
+	}{
+		{32, 32, 16, image.Rect(0, 0, 16, 16)},
+		{64, 32, 16, image.Rect(0, 4, 16, 12)},
+		{32, 64, 16, image.Rect(4, 0, 12, 16)},
+		{1000, 1, 16, image.Rect(0, 7, 16, 8)},
+		{0, 0, 16, image.Rect(0, 0, 16, 16)},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"width":  tc.width,
+			"height": tc.height,
+			"size":   tc.size,
+		})
+		context.Assert("result", fitRect(tc.width, tc.height, tc.size), tc.exp)
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	testCases := []struct {
+		hex string
+		exp color.Color
+	}{
+		{"#ffffff", color.RGBA{255, 255, 255, 255}},
+		{"#1A2b3C", color.RGBA{0x1a, 0x2b, 0x3c, 255}},
+		{"#f0a", color.RGBA{0xff, 0x00, 0xaa, 255}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"hex":   tc.hex,
+		})
+		context.Assert("result", parseHexColor(tc.hex), tc.exp)
+	}
+}
+
+func TestSourceImage_Render(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	transparent := color.RGBA{}
+
+	pngSource := readSource(t, "source.png", false)
+	svgSource := readSource(t, "source.svg", true)
+
+	testCases := []struct {
+		source     *sourceImage
+		background color.Color
+		points     []image.Point
+		exp        []color.RGBA
+	}{
+		{pngSource, nil, []image.Point{{8, 8}, {8, 1}, {8, 14}}, []color.RGBA{red, transparent, transparent}},
+		{pngSource, white, []image.Point{{8, 8}, {8, 1}, {8, 14}}, []color.RGBA{red, white, white}},
+		{svgSource, nil, []image.Point{{8, 8}, {1, 8}, {14, 8}}, []color.RGBA{{0, 0, 255, 255}, transparent, transparent}},
+		{svgSource, white, []image.Point{{8, 8}, {1, 8}, {14, 8}}, []color.RGBA{{0, 0, 255, 255}, white, white}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"svg":        tc.source.svg != nil,
+			"background": tc.background,
+		})
+
+		img, err := tc.source.render(16, tc.background)
+		context.AssertError(err, "source.render")
+		context.Assert("Bounds", img.Bounds(), image.Rect(0, 0, 16, 16))
+		for i, point := range tc.points {
+			context.Assert(point.String(), img.RGBAAt(point.X, point.Y), tc.exp[i])
+		}
+	}
+}
+
+func TestEncodeICO(t *testing.T) {
+	pngs := [][]byte{[]byte("abc"), []byte("defgh")}
+	got, err := encodeICO([]int{16, 256}, pngs)
+	test.AssertError(t, err, "encodeICO")
+
+	header := make([]uint16, 3)
+	test.AssertError(t, binary.Read(bytes.NewReader(got[:6]), binary.LittleEndian, header), "binary.Read")
+	test.AssertArray(t, "header", header, []uint16{0, 1, 2})
+
+	testCases := []struct {
+		dimension uint8
+		length    uint32
+		offset    uint32
+	}{
+		{16, 3, 38},
+		{0, 5, 41},
+	}
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+		})
+		entry := got[6+16*testCaseIndex:]
+		context.Assert("width", entry[0], tc.dimension)
+		context.Assert("height", entry[1], tc.dimension)
+		context.Assert("bit count", binary.LittleEndian.Uint16(entry[6:]), uint16(32))
+		context.Assert("length", binary.LittleEndian.Uint32(entry[8:]), tc.length)
+		context.Assert("offset", binary.LittleEndian.Uint32(entry[12:]), tc.offset)
+	}
+	test.AssertLabel(t, "images", string(got[38:]), "abcdefgh")
+}
+
+func TestEncodePNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	pngBytes, err := encodePNG(img)
+	test.AssertError(t, err, "encodePNG")
+
+	decoded, err := png.Decode(bytes.NewReader(pngBytes))
+	test.AssertError(t, err, "png.Decode")
+	test.AssertLabel(t, "Bounds", decoded.Bounds(), img.Bounds())
+}
@@ -0,0 +1,77 @@
+package favicon
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var colorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+var displays = map[string]bool{
+	"fullscreen": true,
+	"standalone": true,
+	"minimal-ui": true,
+	"browser":    true,
+}
+
+// Settings is the settings of this package
+type Settings struct {
+	// SourcePath is the PNG, JPEG, GIF or SVG image the icons are generated from, an empty SourcePath disables the Favicon
+	SourcePath string `json:"source_path,omitempty"`
+	// Path is the URL path the icons, web manifest and browserconfig are served under
+	Path string `json:"path,omitempty"`
+
+	// Name and ShortName are the names of the site in the web manifest
+	Name      string `json:"name,omitempty"`
+	ShortName string `json:"short_name,omitempty"`
+	// Display is the display mode of the web manifest: fullscreen, standalone, minimal-ui or browser
+	Display string `json:"display,omitempty"`
+
+	// ThemeColor and BackgroundColor are the colors of the web manifest, BackgroundColor also fills the apple-touch-icon
+	ThemeColor      string `json:"theme_color,omitempty"`
+	BackgroundColor string `json:"background_color,omitempty"`
+	// TileColor is the color of the Windows tile in the browserconfig
+	TileColor string `json:"tile_color,omitempty"`
+}
+
+// DefaultSettings returns the default settings of this package, which has the Favicon disabled
+func DefaultSettings() *Settings {
+	return &Settings{
+		"",
+		"/",
+		"",
+		"",
+		"standalone",
+		"#ffffff",
+		"#ffffff",
+		"#000000",
+	}
+}
+
+// Validate returns an error if the settings are invalid
+func (s *Settings) Validate() error {
+	if s.SourcePath == "" {
+		return nil
+	}
+	if !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("path must start with /: %v", s.Path)
+	}
+	if !displays[s.Display] {
+		return fmt.Errorf("display must be fullscreen, standalone, minimal-ui or browser: %v", s.Display)
+	}
+	colors := []struct {
+		name  string
+		value string
+	}{
+		{"theme_color", s.ThemeColor},
+		{"background_color", s.BackgroundColor},
+		{"tile_color", s.TileColor},
+	}
+	for _, c := range colors {
+		if !colorRegex.MatchString(c.value) {
+			return fmt.Errorf("%v must be a hex color (ex. #ffffff): %v", c.name, c.value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package favicon
+
+import (
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		sourcePath string
+		path       string
+		display    string
+		themeColor string
+		err        bool
+	}{
+		{"", "", "", "", false},
+		{"icon.png", "/", "standalone", "#ffffff", false},
+		{"icon.svg", "/favicon/", "browser", "#FFF", false},
+		{"icon.png", "favicon", "standalone", "#ffffff", true},
+		{"icon.png", "/", "window", "#ffffff", true},
+		{"icon.png", "/", "standalone", "white", true},
+		{"icon.png", "/", "standalone", "#fffff", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"sourcePath": tc.sourcePath,
+			"path":       tc.path,
+			"display":    tc.display,
+			"themeColor": tc.themeColor,
+		})
+		settings := DefaultSettings()
+		settings.SourcePath = tc.sourcePath
+		settings.Path = tc.path
+		settings.Display = tc.display
+		settings.ThemeColor = tc.themeColor
+		context.Assert("err != nil", settings.Validate() != nil, tc.err)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Package filecache caches the values parsed from files, parsing them again when the file's modtime changes
+(ex. assets rebuilt while hosting).
+*/
+package filecache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ParseFunc parses the bytes of a file into the cached value
+type ParseFunc func(bytes []byte) (interface{}, error)
+
+// File caches the value parsed from a file, until the file's modtime changes.
+// The value is replaced, not modified, on reloads, so it's safe to use after Get returns.
+type File struct {
+	filePath string
+	parse    ParseFunc
+
+	value   interface{}
+	modTime time.Time
+	loaded  bool
+	mutex   *sync.RWMutex
+}
+
+// NewFile returns a new instance of File
+func NewFile(filePath string, parse ParseFunc) *File {
+	return &File{
+		filePath,
+		parse,
+		nil,
+		time.Time{},
+		false,
+		&sync.RWMutex{},
+	}
+}
+
+// Get returns the parsed value of the file, reading and parsing the file if it has not been or its modtime changed.
+// The file is read once when called concurrently.
+func (f *File) Get() (interface{}, error) {
+	info, err := os.Stat(f.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mutex.RLock()
+	value, has := f.cachedValue(info.ModTime())
+	f.mutex.RUnlock()
+	if has {
+		return value, nil
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	// another call may have read the file while waiting for the lock
+	if value, has = f.cachedValue(info.ModTime()); has {
+		return value, nil
+	}
+	bytes, err := ioutil.ReadFile(filepath.Clean(f.filePath))
+	if err != nil {
+		return nil, err
+	}
+	value, err = f.parse(bytes)
+	if err != nil {
+		return nil, err
+	}
+	f.value, f.modTime, f.loaded = value, info.ModTime(), true
+	return value, nil
+}
+
+func (f *File) cachedValue(modTime time.Time) (interface{}, bool) {
+	if !f.loaded || !f.modTime.Equal(modTime) {
+		return nil, false
+	}
+	return f.value, true
+}
+
+// Files caches the values parsed from the files, keyed by file path, see File
+type Files struct {
+	parse ParseFunc
+	files map[string]*File
+	mutex *sync.Mutex
+}
+
+// NewFiles returns a new instance of Files, which parses all of its files with parse
+func NewFiles(parse ParseFunc) *Files {
+	return &Files{
+		parse,
+		map[string]*File{},
+		&sync.Mutex{},
+	}
+}
+
+// Get returns the parsed value of the file path, see File.Get
+func (files *Files) Get(filePath string) (interface{}, error) {
+	files.mutex.Lock()
+	file, has := files.files[filePath]
+	if !has {
+		file = NewFile(filePath, files.parse)
+		files.files[filePath] = file
+	}
+	files.mutex.Unlock()
+	return file.Get()
+}
@@ -0,0 +1,128 @@
+package filecache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s12chung/gostatic/go/lib/utils"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+type countingParse struct {
+	count int
+	mutex *sync.Mutex
+}
+
+func newCountingParse() *countingParse {
+	return &countingParse{0, &sync.Mutex{}}
+}
+
+func (parse *countingParse) parse(bytes []byte) (interface{}, error) {
+	parse.mutex.Lock()
+	defer parse.mutex.Unlock()
+	parse.count++
+	if string(bytes) == "invalid" {
+		return nil, fmt.Errorf("invalid file")
+	}
+	return string(bytes), nil
+}
+
+func writeFile(t *testing.T, filePath, contents string, modTime time.Time) {
+	test.AssertError(t, utils.WriteFile(filePath, []byte(contents)), "utils.WriteFile")
+	test.AssertError(t, os.Chtimes(filePath, modTime, modTime), "os.Chtimes")
+}
+
+func TestFile_Get(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "filecache")
+	defer clean()
+	test.AssertError(t, utils.MkdirAll(dir), "utils.MkdirAll")
+
+	filePath := filepath.Join(dir, "file.txt")
+	parse := newCountingParse()
+	file := NewFile(filePath, parse.parse)
+
+	_, err := file.Get()
+	if err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	modTime := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		contents string
+		modTime  time.Time
+		exp      string
+		count    int
+	}{
+		{"a", modTime, "a", 1},
+		{"a", modTime, "a", 1},
+		// the same modtime is cached
+		{"b", modTime, "a", 1},
+		{"b", modTime.Add(time.Second), "b", 2},
+		{"invalid", modTime.Add(2 * time.Second), "", 3},
+		{"c", modTime.Add(3 * time.Second), "c", 4},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"contents": tc.contents,
+		})
+
+		writeFile(t, filePath, tc.contents, tc.modTime)
+		value, err := file.Get()
+		context.Assert("err != nil", err != nil, tc.exp == "")
+		if err == nil {
+			context.Assert("value", value, tc.exp)
+		}
+		context.Assert("count", parse.count, tc.count)
+	}
+}
+
+func TestFile_Get_Concurrent(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "filecache")
+	defer clean()
+	test.AssertError(t, utils.MkdirAll(dir), "utils.MkdirAll")
+
+	filePath := filepath.Join(dir, "file.txt")
+	writeFile(t, filePath, "a", time.Now().Add(-time.Hour))
+	parse := newCountingParse()
+	file := NewFile(filePath, parse.parse)
+
+	waitGroup := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			value, err := file.Get()
+			test.AssertError(t, err, "file.Get")
+			test.AssertLabel(t, "value", value, "a")
+		}()
+	}
+	waitGroup.Wait()
+	test.AssertLabel(t, "count", parse.count, 1)
+}
+
+func TestFiles_Get(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "filecache")
+	defer clean()
+	test.AssertError(t, utils.MkdirAll(dir), "utils.MkdirAll")
+
+	modTime := time.Now().Add(-time.Hour)
+	for _, name := range []string{"a", "b"} {
+		writeFile(t, filepath.Join(dir, name), name, modTime)
+	}
+	parse := newCountingParse()
+	files := NewFiles(parse.parse)
+
+	for _, name := range []string{"a", "b", "a", "b"} {
+		value, err := files.Get(filepath.Join(dir, name))
+		test.AssertError(t, err, "files.Get")
+		test.AssertLabel(t, "value", value, name)
+	}
+	test.AssertLabel(t, "count", parse.count, 2)
+}
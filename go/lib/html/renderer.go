@@ -37,6 +37,13 @@ type Plugin interface {
 	TemplateFuncs() template.FuncMap
 }
 
+// WithPlugins returns a copy of the Renderer with the plugins added, for plugins that differ per route (such as locales)
+func (renderer *Renderer) WithPlugins(plugins ...Plugin) *Renderer {
+	merged := make([]Plugin, 0, len(renderer.plugins)+len(plugins))
+	merged = append(merged, renderer.plugins...)
+	return NewRenderer(renderer.settings, append(merged, plugins...), renderer.log)
+}
+
 func (renderer *Renderer) partialPaths() ([]string, error) {
 	filePaths, err := utils.FilePaths(renderer.settings.TemplateExt, renderer.settings.TemplatePath)
 	if err != nil {
@@ -176,3 +176,23 @@ func TestRenderer_Render_Plugins(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderer_WithPlugins(t *testing.T) {
+	renderer, hook := defaultRenderer()
+	renderer.plugins = []Plugin{&stringPlugin{}}
+
+	withPlugins := renderer.WithPlugins(&intPlugin{})
+	test.AssertLabel(t, "len(renderer.plugins)", len(renderer.plugins), 1)
+	test.AssertLabel(t, "len(withPlugins.plugins)", len(withPlugins.plugins), 2)
+
+	rendered, err := withPlugins.Render("plugins", nil)
+	if err != nil {
+		test.PrintLogEntries(t, hook)
+		test.AssertError(t, err, "withPlugins.Render")
+	}
+	got := strings.TrimSpace(string(rendered))
+	exp := strings.TrimSpace(string(testfile.ReadFixture(t, "plugins.html")))
+	if got != exp {
+		t.Error(test.NewContext(t).DiffString("Result", got, exp, cmp.Diff(got, exp)))
+	}
+}
@@ -1,5 +1,9 @@
 package html
 
+import (
+	"fmt"
+)
+
 // Settings represents the settings of the HTML templates
 type Settings struct {
 	TemplatePath string `json:"template_path,omitempty"`
@@ -8,6 +12,14 @@ type Settings struct {
 	WebsiteTitle string `json:"website_title,omitempty"`
 }
 
+// Validate returns an error if the settings are invalid
+func (s *Settings) Validate() error {
+	if s.TemplatePath == "" || s.TemplateExt == "" {
+		return fmt.Errorf("template_path and template_ext are required")
+	}
+	return nil
+}
+
 // DefaultSettings is the default settings of the HTML templates
 func DefaultSettings() *Settings {
 	return &Settings{
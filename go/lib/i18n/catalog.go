@@ -0,0 +1,166 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Catalog maps the translation keys to the translations of a locale
+type Catalog map[string]string
+
+// ContextKey returns the catalog key of the key in the context, which is the msgctxt of the PO entries
+// joined with the msgid by "\x04", like gettext
+func ContextKey(context, key string) string {
+	return context + "\x04" + key
+}
+
+// ReadCatalog reads the catalog of the locale from the dirPath, from <locale>.json or <locale>.po
+func ReadCatalog(dirPath, locale string) (Catalog, error) {
+	jsonPath := filepath.Join(dirPath, locale+".json")
+	fileBytes, err := ioutil.ReadFile(jsonPath)
+	if err == nil {
+		catalog, parseErr := ParseJSONCatalog(fileBytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("error parsing %v - %v", jsonPath, parseErr)
+		}
+		return catalog, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	poPath := filepath.Join(dirPath, locale+".po")
+	fileBytes, err = ioutil.ReadFile(poPath)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := ParsePOCatalog(fileBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %v - %v", poPath, err)
+	}
+	return catalog, nil
+}
+
+// ParseJSONCatalog parses a JSON object of translations, nested objects have their keys joined by "."
+func ParseJSONCatalog(jsonBytes []byte) (Catalog, error) {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(jsonBytes, &object); err != nil {
+		return nil, err
+	}
+
+	catalog := Catalog{}
+	if err := flattenJSON(catalog, "", object); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+func flattenJSON(catalog Catalog, prefix string, object map[string]interface{}) error {
+	for key, value := range object {
+		key = prefix + key
+		switch v := value.(type) {
+		case string:
+			catalog[key] = v
+		case map[string]interface{}:
+			if err := flattenJSON(catalog, key+".", v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("translation is not a string or object: %v", key)
+		}
+	}
+	return nil
+}
+
+// ParsePOCatalog parses a gettext PO file. The msgid is the key, the (first) msgstr is the translation.
+// Entries with a msgctxt are keyed by ContextKey. Untranslated entries and the header are skipped.
+func ParsePOCatalog(poBytes []byte) (Catalog, error) {
+	parser := &poParser{catalog: Catalog{}}
+	scanner := bufio.NewScanner(bytes.NewReader(poBytes))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if err := parser.parseLine(strings.TrimSpace(scanner.Text())); err != nil {
+			return nil, fmt.Errorf("line %v: %v", lineNumber, err)
+		}
+	}
+	parser.addEntry()
+	return parser.catalog, scanner.Err()
+}
+
+type poParser struct {
+	catalog Catalog
+
+	msgctxt *string
+	msgid   *string
+	msgstr  *string
+	current *string
+}
+
+func (parser *poParser) parseLine(line string) error {
+	keyword, quoted := splitPOLine(line)
+	switch keyword {
+	case "", "#":
+		return nil
+	case "msgctxt":
+		parser.addEntry()
+		parser.msgctxt = new(string)
+		parser.current = parser.msgctxt
+	case "msgid":
+		if parser.msgstr != nil {
+			parser.addEntry()
+		}
+		parser.msgid = new(string)
+		parser.current = parser.msgid
+	case "msgstr", "msgstr[0]":
+		parser.msgstr = new(string)
+		parser.current = parser.msgstr
+	case `"`:
+	default:
+		// msgid_plural and the other plural forms are ignored
+		parser.current = nil
+		return nil
+	}
+
+	value, err := strconv.Unquote(quoted)
+	if err != nil {
+		return err
+	}
+	if parser.current != nil {
+		*parser.current += value
+	}
+	return nil
+}
+
+func (parser *poParser) addEntry() {
+	if parser.msgid != nil && parser.msgstr != nil && *parser.msgid != "" && *parser.msgstr != "" {
+		key := *parser.msgid
+		if parser.msgctxt != nil {
+			key = ContextKey(*parser.msgctxt, key)
+		}
+		parser.catalog[key] = *parser.msgstr
+	}
+	parser.msgctxt, parser.msgid, parser.msgstr, parser.current = nil, nil, nil, nil
+}
+
+// splitPOLine returns the keyword and the quoted string of the line, or `"` as the keyword for continued strings
+func splitPOLine(line string) (string, string) {
+	switch {
+	case line == "":
+		return "", ""
+	case strings.HasPrefix(line, "#"):
+		return "#", ""
+	case strings.HasPrefix(line, `"`):
+		return `"`, line
+	}
+	split := strings.SplitN(line, " ", 2)
+	if len(split) != 2 {
+		return split[0], `""`
+	}
+	return split[0], strings.TrimSpace(split[1])
+}
@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+const localesPath = testfile.FixturePath + "/locales"
+
+func TestReadCatalog(t *testing.T) {
+	testCases := []struct {
+		locale string
+		exp    Catalog
+		err    bool
+	}{
+		{"en", Catalog{
+			"hello":     "Hello",
+			"greeting":  "Hello, %v!",
+			"only_en":   "Only in English",
+			"nav.home":  "Home",
+			"nav.posts": "Posts",
+		}, false},
+		{"fr", Catalog{
+			"hello":                    "Bonjour",
+			"greeting":                 "Bonjour, %v !",
+			"nav.home":                 "Accueil",
+			"nav.posts":                `Articles "récents"`,
+			ContextKey("menu", "open"): "Ouvrir",
+			ContextKey("file", "open"): "Ouvrir le fichier",
+			"post":                     "article",
+		}, false},
+		{"de", nil, true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"locale": tc.locale,
+		})
+
+		got, err := ReadCatalog(localesPath, tc.locale)
+		context.Assert("err != nil", err != nil, tc.err)
+		context.AssertArray("result", got, tc.exp)
+	}
+}
+
+func TestParseJSONCatalog(t *testing.T) {
+	testCases := []struct {
+		json string
+		exp  Catalog
+		err  bool
+	}{
+		{`{}`, Catalog{}, false},
+		{`{"a": {"b": {"c": "d"}}, "e": "f"}`, Catalog{"a.b.c": "d", "e": "f"}, false},
+		{`{"a": 1}`, nil, true},
+		{`{"a": ["b"]}`, nil, true},
+		{`{`, nil, true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"json":  tc.json,
+		})
+
+		got, err := ParseJSONCatalog([]byte(tc.json))
+		context.Assert("err != nil", err != nil, tc.err)
+		if !tc.err {
+			context.AssertArray("result", got, tc.exp)
+		}
+	}
+}
+
+func TestParsePOCatalog(t *testing.T) {
+	testCases := []struct {
+		po  string
+		exp Catalog
+		err bool
+	}{
+		{``, Catalog{}, false},
+		{"msgid \"a\"\nmsgstr \"b\"", Catalog{"a": "b"}, false},
+		{"msgid \"a\\tb\"\nmsgstr \"c\\n\"\n\"d\"", Catalog{"a\tb": "c\nd"}, false},
+		{"msgid \"a\"\nmsgstr \"\"\nmsgid \"c\"\nmsgstr \"d\"", Catalog{"c": "d"}, false},
+		{"# comment\n\nmsgid \"a\"\n#~ obsolete\nmsgstr \"b\"", Catalog{"a": "b"}, false},
+		{"msgid \"a\nmsgstr \"b\"", nil, true},
+		{"msgctxt \"x\"\nmsgid \"a\"\nmsgstr \"b\"\nmsgctxt \"y\"\nmsgid \"a\"\nmsgstr \"c\"\nmsgid \"a\"\nmsgstr \"d\"",
+			Catalog{ContextKey("x", "a"): "b", ContextKey("y", "a"): "c", "a": "d"}, false},
+		{"msgctxt \"x\"\n\"y\"\nmsgid \"a\"\nmsgstr \"b\"\n\nmsgid \"c\"\nmsgstr \"d\"", Catalog{ContextKey("xy", "a"): "b", "c": "d"}, false},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"po":    tc.po,
+		})
+
+		got, err := ParsePOCatalog([]byte(tc.po))
+		context.Assert("err != nil", err != nil, tc.err)
+		if !tc.err {
+			context.AssertArray("result", got, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"strings"
+	"time"
+)
+
+// The catalog keys that override the date layout and names of a locale, names are comma separated
+const (
+	DateLayoutKey        = "date.layout"
+	DateMonthsKey        = "date.months"
+	DateShortMonthsKey   = "date.short_months"
+	DateWeekdaysKey      = "date.weekdays"
+	DateShortWeekdaysKey = "date.short_weekdays"
+)
+
+type dateNames struct {
+	layout        string
+	months        []string
+	shortMonths   []string
+	weekdays      []string
+	shortWeekdays []string
+}
+
+var languageDateNames = map[string]*dateNames{
+	"en": {
+		"January 2, 2006",
+		[]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		[]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		[]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		[]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	},
+	"fr": {
+		"2 January 2006",
+		[]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		[]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		[]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		[]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	},
+}
+
+// dateNames returns the date names of the locale's language (`fr` for `fr-CA`) or English,
+// overridden by the date keys of the locale's catalog
+func (l *Localizer) dateNames() *dateNames {
+	language := strings.ToLower(strings.SplitN(strings.Replace(l.locale, "_", "-", -1), "-", 2)[0])
+	defaults, has := languageDateNames[language]
+	if !has {
+		defaults = languageDateNames["en"]
+	}
+
+	names := *defaults
+	if layout, hasLayout := l.i18n.lookup(l.locale, DateLayoutKey); hasLayout {
+		names.layout = layout
+	}
+	names.months = l.lookupNames(DateMonthsKey, names.months)
+	names.shortMonths = l.lookupNames(DateShortMonthsKey, names.shortMonths)
+	names.weekdays = l.lookupNames(DateWeekdaysKey, names.weekdays)
+	names.shortWeekdays = l.lookupNames(DateShortWeekdaysKey, names.shortWeekdays)
+	return &names
+}
+
+func (l *Localizer) lookupNames(key string, defaults []string) []string {
+	value, has := l.i18n.lookup(l.locale, key)
+	if !has {
+		return defaults
+	}
+	names := strings.Split(value, ",")
+	if len(names) != len(defaults) {
+		l.i18n.log.Errorf("%v of locale %v needs %v comma separated names, using defaults", key, l.locale, len(defaults))
+		return defaults
+	}
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+	return names
+}
+
+// dateNameTokens are the time.Format layout tokens that are replaced by names, longer tokens first
+var dateNameTokens = []string{"January", "Jan", "Monday", "Mon"}
+
+func (names *dateNames) name(token string, date time.Time) string {
+	switch token {
+	case "January":
+		return names.months[date.Month()-1]
+	case "Jan":
+		return names.shortMonths[date.Month()-1]
+	case "Monday":
+		return names.weekdays[date.Weekday()]
+	default:
+		return names.shortWeekdays[date.Weekday()]
+	}
+}
+
+// formatDate formats the date like time.Format, but with the month and weekday names replaced
+func formatDate(date time.Time, names *dateNames) string {
+	var builder strings.Builder
+	layout := names.layout
+	for {
+		index, token := nextDateNameToken(layout)
+		if index < 0 {
+			builder.WriteString(date.Format(layout))
+			return builder.String()
+		}
+		builder.WriteString(date.Format(layout[:index]))
+		builder.WriteString(names.name(token, date))
+		layout = layout[index+len(token):]
+	}
+}
+
+func nextDateNameToken(layout string) (int, string) {
+	firstIndex, firstToken := -1, ""
+	for _, token := range dateNameTokens {
+		index := strings.Index(layout, token)
+		if index >= 0 && (firstIndex < 0 || index < firstIndex) {
+			firstIndex, firstToken = index, token
+		}
+	}
+	return firstIndex, firstToken
+}
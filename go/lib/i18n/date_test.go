@@ -0,0 +1,46 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestLocalizer_DateFormat(t *testing.T) {
+	date := time.Date(2018, 8, 5, 13, 4, 0, 0, time.UTC)
+
+	testCases := []struct {
+		locale  string
+		catalog Catalog
+		exp     string
+		err     bool
+	}{
+		{"en", nil, "August 5, 2018", false},
+		{"fr", nil, "5 août 2018", false},
+		{"fr-CA", nil, "5 août 2018", false},
+		{"de", nil, "August 5, 2018", false},
+		{"fr", Catalog{DateLayoutKey: "Monday 2 January 2006, 15:04"}, "dimanche 5 août 2018, 13:04", false},
+		{"fr", Catalog{DateLayoutKey: "Mon 2 Jan 2006"}, "dim. 5 août 2018", false},
+		{"en", Catalog{DateLayoutKey: "Mon, Jan 2"}, "Sun, Aug 5", false},
+		{"de", Catalog{
+			DateLayoutKey:   "Monday, 2. January 2006",
+			DateMonthsKey:   "Januar, Februar, März, April, Mai, Juni, Juli, August, September, Oktober, November, Dezember",
+			DateWeekdaysKey: "Sonntag, Montag, Dienstag, Mittwoch, Donnerstag, Freitag, Samstag",
+		}, "Sonntag, 5. August 2018", false},
+		{"fr", Catalog{DateMonthsKey: "a,b"}, "5 août 2018", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":   testCaseIndex,
+			"locale":  tc.locale,
+			"catalog": tc.catalog,
+		})
+
+		i18n, hook := defaultI18n()
+		i18n.catalogs = map[string]Catalog{tc.locale: tc.catalog}
+		context.Assert("result", i18n.Localizer(tc.locale).DateFormat(date), tc.exp)
+		context.Assert("logged error", len(hook.AllEntries()) == 1, tc.err)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Package i18n handles the translation catalogs and routes of locales.
+
+Routes are registered per locale under `/<locale>/...` and handled with a Localizer of the locale,
+which is a html.Plugin that adds the `t`, `tc`, `locale`, `localizedURL`, `hreflangs` and locale-aware `dateFormat` template functions.
+*/
+package i18n
+
+import (
+	"fmt"
+	"html/template"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/urls"
+)
+
+// XDefault is the hreflang of the alternate for unmatched languages, which links to the default locale
+const XDefault = "x-default"
+
+// I18n holds the catalogs of the locales
+type I18n struct {
+	settings      *Settings
+	urls          *urls.URLs
+	catalogs      map[string]Catalog
+	catalogsMutex *sync.RWMutex
+	log           logrus.FieldLogger
+}
+
+// NewI18n returns a new instance of I18n, the urls are applied to the LocalizedURL and Hreflangs URLs
+func NewI18n(settings *Settings, u *urls.URLs, log logrus.FieldLogger) *I18n {
+	return &I18n{
+		settings,
+		u,
+		nil,
+		&sync.RWMutex{},
+		log,
+	}
+}
+
+// Locales returns the locales of the settings
+func (i *I18n) Locales() []string {
+	return i.settings.Locales
+}
+
+// DefaultLocale returns the default locale of the settings
+func (i *I18n) DefaultLocale() string {
+	return i.settings.DefaultLocale
+}
+
+// Localizer returns the Localizer of the locale
+func (i *I18n) Localizer(locale string) *Localizer {
+	return newLocalizer(i, locale)
+}
+
+// Translate returns the translation of the key for the locale, formatted with the args (fmt.Sprintf) if given.
+// It falls back to the translation of the default locale, then the key.
+func (i *I18n) Translate(locale, key string, args ...interface{}) string {
+	return i.translate(locale, key, key, args)
+}
+
+// TranslateContext returns the translation of the key in the context (the msgctxt of PO catalogs) for the locale,
+// see Translate and ContextKey
+func (i *I18n) TranslateContext(locale, context, key string, args ...interface{}) string {
+	return i.translate(locale, ContextKey(context, key), key, args)
+}
+
+// translate returns the translation of the catalogKey, falling back to the key
+func (i *I18n) translate(locale, catalogKey, key string, args []interface{}) string {
+	translation, has := i.lookup(locale, catalogKey)
+	if !has {
+		i.log.Warnf("translation not found for locale %v, key: %v", locale, catalogKey)
+		translation, has = i.lookup(i.settings.DefaultLocale, catalogKey)
+		if !has {
+			translation = key
+		}
+	}
+
+	if len(args) == 0 {
+		return translation
+	}
+	return fmt.Sprintf(translation, args...)
+}
+
+func (i *I18n) lookup(locale, key string) (string, bool) {
+	catalogs := i.getCatalogs()
+	translation, has := catalogs[locale][key]
+	return translation, has
+}
+
+func (i *I18n) getCatalogs() map[string]Catalog {
+	i.catalogsMutex.RLock()
+	catalogs := i.catalogs
+	i.catalogsMutex.RUnlock()
+	if catalogs != nil {
+		return catalogs
+	}
+
+	i.catalogsMutex.Lock()
+	defer i.catalogsMutex.Unlock()
+	if i.catalogs == nil {
+		i.catalogs = i.readCatalogs()
+	}
+	return i.catalogs
+}
+
+func (i *I18n) readCatalogs() map[string]Catalog {
+	catalogs := make(map[string]Catalog, len(i.settings.Locales))
+	for _, locale := range i.settings.Locales {
+		catalog, err := ReadCatalog(i.settings.CatalogPath, locale)
+		if err != nil {
+			i.log.Errorf("error reading catalog for locale %v - %v", locale, err)
+			catalog = Catalog{}
+		}
+		catalogs[locale] = catalog
+	}
+	return catalogs
+}
+
+// LocalizedURL returns the url under the locale and the base path of the urls, `/` maps to `/<locale>/`
+func (i *I18n) LocalizedURL(locale, url string) string {
+	return i.urls.RelURL(localizedPath(locale, url))
+}
+
+// localizedPath returns the route of the url under the locale, `/` maps to `/<locale>/`
+func localizedPath(locale, url string) string {
+	if strings.TrimPrefix(url, "/") == "" {
+		return path.Join("/", locale) + "/"
+	}
+	return path.Join("/", locale, url)
+}
+
+// Hreflangs returns the alternate link tags of the url for each locale, and the x-default for the default locale.
+// The hrefs are absolute URLs with the base URL and base path of the urls.
+func (i *I18n) Hreflangs(url string) template.HTML {
+	links := make([]string, len(i.settings.Locales)+1)
+	for index, locale := range i.settings.Locales {
+		links[index] = i.hreflang(locale, localizedPath(locale, url))
+	}
+	links[len(links)-1] = i.hreflang(XDefault, localizedPath(i.settings.DefaultLocale, url))
+	return template.HTML(strings.Join(links, "\n"))
+}
+
+func (i *I18n) hreflang(hreflang, url string) string {
+	href := i.urls.AbsURL(url)
+	return fmt.Sprintf(`<link rel="alternate" hreflang="%v" href="%v">`, template.HTMLEscapeString(hreflang), template.HTMLEscapeString(href))
+}
+
+// LocaleHandler is a handler of a localized route, given the Localizer of the route's locale
+type LocaleHandler func(ctx router.Context, localizer *Localizer) error
+
+// GetHTML defines a HTML handler of the url for each locale, under `/<locale>/` without the base path
+func (i *I18n) GetHTML(r router.Router, url string, handler LocaleHandler) {
+	i.eachLocale(url, handler, r.GetHTML)
+}
+
+// Get defines a handler of the url for each locale, under `/<locale>/` without the base path
+func (i *I18n) Get(r router.Router, url string, handler LocaleHandler) {
+	i.eachLocale(url, handler, r.Get)
+}
+
+func (i *I18n) eachLocale(url string, handler LocaleHandler, get func(url string, handler router.ContextHandler)) {
+	for _, locale := range i.settings.Locales {
+		localizer := i.Localizer(locale)
+		get(localizedPath(locale, url), func(ctx router.Context) error {
+			return handler(ctx, localizer)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package i18n
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/urls"
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func defaultSettings() *Settings {
+	return &Settings{
+		[]string{"en", "fr"},
+		"en",
+		localesPath,
+	}
+}
+
+func defaultI18n() (*I18n, *logTest.Hook) {
+	log, hook := logTest.NewNullLogger()
+	return NewI18n(defaultSettings(), urls.NewURLs(&urls.Settings{BaseURL: "https://example.com/", BasePath: "/docs/"}), log), hook
+}
+
+func TestSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		locales       []string
+		defaultLocale string
+		err           bool
+	}{
+		{[]string{"en", "fr"}, "en", false},
+		{[]string{"en", "fr"}, "fr", false},
+		{[]string{"en", "fr"}, "de", true},
+		{nil, "en", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":         testCaseIndex,
+			"locales":       tc.locales,
+			"defaultLocale": tc.defaultLocale,
+		})
+		settings := defaultSettings()
+		settings.Locales = tc.locales
+		settings.DefaultLocale = tc.defaultLocale
+		context.Assert("err != nil", settings.Validate() != nil, tc.err)
+	}
+}
+
+func TestI18n_Translate(t *testing.T) {
+	testCases := []struct {
+		locale string
+		key    string
+		args   []interface{}
+		exp    string
+		warn   bool
+	}{
+		{"en", "hello", nil, "Hello", false},
+		{"fr", "hello", nil, "Bonjour", false},
+		{"fr", "nav.home", nil, "Accueil", false},
+		{"en", "greeting", []interface{}{"Steve"}, "Hello, Steve!", false},
+		{"fr", "greeting", []interface{}{"Steve"}, "Bonjour, Steve !", false},
+		{"fr", "only_en", nil, "Only in English", true},
+		{"fr", "missing", nil, "missing", true},
+		{"de", "hello", nil, "Hello", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"locale": tc.locale,
+			"key":    tc.key,
+		})
+
+		i18n, hook := defaultI18n()
+		context.Assert("result", i18n.Translate(tc.locale, tc.key, tc.args...), tc.exp)
+		context.Assert("warned", len(hook.AllEntries()) == 1, tc.warn)
+	}
+}
+
+func TestI18n_TranslateContext(t *testing.T) {
+	testCases := []struct {
+		locale  string
+		context string
+		key     string
+		exp     string
+		warn    bool
+	}{
+		{"fr", "menu", "open", "Ouvrir", false},
+		{"fr", "file", "open", "Ouvrir le fichier", false},
+		{"fr", "other", "open", "open", true},
+		{"en", "menu", "open", "open", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":   testCaseIndex,
+			"locale":  tc.locale,
+			"context": tc.context,
+			"key":     tc.key,
+		})
+
+		i18n, hook := defaultI18n()
+		context.Assert("result", i18n.TranslateContext(tc.locale, tc.context, tc.key), tc.exp)
+		context.Assert("warned", len(hook.AllEntries()) == 1, tc.warn)
+	}
+
+	i18n, _ := defaultI18n()
+	test.AssertLabel(t, "no context", i18n.Translate("fr", "open"), "open")
+}
+
+func TestI18n_Translate_MissingCatalog(t *testing.T) {
+	i18n, hook := defaultI18n()
+	i18n.settings.Locales = []string{"en", "de"}
+
+	test.AssertLabel(t, "result", i18n.Translate("de", "hello"), "Hello")
+	test.AssertArray(t, "levels", test.LogEntryLevels(hook), []logrus.Level{logrus.ErrorLevel, logrus.WarnLevel})
+}
+
+func TestI18n_LocalizedURL(t *testing.T) {
+	testCases := []struct {
+		locale string
+		url    string
+		exp    string
+	}{
+		{"en", "/", "/docs/en/"},
+		{"en", "", "/docs/en/"},
+		{"fr", "/posts", "/docs/fr/posts"},
+		{"fr", "posts/a.html", "/docs/fr/posts/a.html"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"locale": tc.locale,
+			"url":    tc.url,
+		})
+
+		i18n, _ := defaultI18n()
+		context.Assert("result", i18n.LocalizedURL(tc.locale, tc.url), tc.exp)
+	}
+}
+
+func TestI18n_Hreflangs(t *testing.T) {
+	i18n, _ := defaultI18n()
+	exp := strings.Join([]string{
+		`<link rel="alternate" hreflang="en" href="https://example.com/docs/en/posts">`,
+		`<link rel="alternate" hreflang="fr" href="https://example.com/docs/fr/posts">`,
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/docs/en/posts">`,
+	}, "\n")
+	test.AssertLabel(t, "result", i18n.Hreflangs("/posts"), template.HTML(exp))
+}
+
+func TestI18n_GetHTML(t *testing.T) {
+	log, _ := logTest.NewNullLogger()
+	r := router.NewGenerateRouter(log)
+
+	i18n, _ := defaultI18n()
+	handler := func(ctx router.Context, localizer *Localizer) error {
+		ctx.Respond([]byte(fmt.Sprintf("%v %v", ctx.URL(), localizer.T("hello"))))
+		return nil
+	}
+	i18n.GetHTML(r, "/", handler)
+	i18n.GetHTML(r, "/posts", handler)
+	i18n.Get(r, "/feed.atom", handler)
+
+	routeURLs := r.URLs()
+	sort.Strings(routeURLs)
+	test.AssertArray(t, "URLs", routeURLs, []string{"/en/", "/en/feed.atom", "/en/posts", "/fr/", "/fr/feed.atom", "/fr/posts"})
+
+	testCases := []struct {
+		url      string
+		exp      string
+		mimeType string
+	}{
+		{"/en/", "/en/ Hello", "text/html; charset=utf-8"},
+		{"/fr/posts", "/fr/posts Bonjour", "text/html; charset=utf-8"},
+		{"/fr/feed.atom", "/fr/feed.atom Bonjour", "application/atom+xml"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"url":   tc.url,
+		})
+
+		response, err := r.Requester().Get(tc.url)
+		context.AssertError(err, "Requester.Get")
+		context.Assert("Body", string(response.Body), tc.exp)
+		context.Assert("MimeType", response.MimeType, tc.mimeType)
+	}
+}
+
+func TestLocalizer_TemplateFuncs(t *testing.T) {
+	i18n, _ := defaultI18n()
+	localizer := i18n.Localizer("fr")
+	date := time.Date(2018, 2, 4, 0, 0, 0, 0, time.UTC)
+
+	tmpl := `{{ locale }}|{{ t "greeting" "Steve" }}|{{ tc "file" "open" }}|{{ localizedURL "/posts" }}|{{ localizedURL "/posts" "en" }}|{{ dateFormat . }}`
+	templ, err := template.New("test").Funcs(localizer.TemplateFuncs()).Parse(tmpl)
+	test.AssertError(t, err, "Parse")
+
+	builder := &strings.Builder{}
+	test.AssertError(t, templ.Execute(builder, date), "Execute")
+	test.AssertLabel(t, "result", builder.String(), "fr|Bonjour, Steve !|Ouvrir le fichier|/docs/fr/posts|/docs/en/posts|4 février 2018")
+}
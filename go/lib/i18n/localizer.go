@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"html/template"
+	"time"
+)
+
+// Localizer translates and formats for a locale, it is a html.Plugin
+type Localizer struct {
+	i18n   *I18n
+	locale string
+}
+
+func newLocalizer(i18n *I18n, locale string) *Localizer {
+	return &Localizer{
+		i18n,
+		locale,
+	}
+}
+
+// Locale returns the locale of the Localizer
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// T returns the translation of the key, see I18n.Translate
+func (l *Localizer) T(key string, args ...interface{}) string {
+	return l.i18n.Translate(l.locale, key, args...)
+}
+
+// TC returns the translation of the key in the context, see I18n.TranslateContext
+func (l *Localizer) TC(context, key string, args ...interface{}) string {
+	return l.i18n.TranslateContext(l.locale, context, key, args...)
+}
+
+// LocalizedURL returns the url under the first of the given locales, or the Localizer's locale
+func (l *Localizer) LocalizedURL(url string, locales ...string) string {
+	locale := l.locale
+	if len(locales) > 0 {
+		locale = locales[0]
+	}
+	return l.i18n.LocalizedURL(locale, url)
+}
+
+// DateFormat formats the date with the date layout and month and weekday names of the locale, see date.go
+func (l *Localizer) DateFormat(date time.Time) string {
+	return formatDate(date, l.dateNames())
+}
+
+// TemplateFuncs returns the template functions of the Localizer, `dateFormat` overrides the html package's
+func (l *Localizer) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"t":            l.T,
+		"tc":           l.TC,
+		"locale":       l.Locale,
+		"localizedURL": l.LocalizedURL,
+		"hreflangs":    l.i18n.Hreflangs,
+		"dateFormat":   l.DateFormat,
+	}
+}
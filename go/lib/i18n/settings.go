@@ -0,0 +1,32 @@
+package i18n
+
+import (
+	"fmt"
+)
+
+// Settings represents the settings of the locales and their catalogs
+type Settings struct {
+	Locales       []string `json:"locales,omitempty"`
+	DefaultLocale string   `json:"default_locale,omitempty"`
+	// CatalogPath is the directory of the catalogs, named by locale: en.json or en.po
+	CatalogPath string `json:"catalog_path,omitempty"`
+}
+
+// DefaultSettings returns the default settings of this package
+func DefaultSettings() *Settings {
+	return &Settings{
+		[]string{"en"},
+		"en",
+		"./go/content/locales",
+	}
+}
+
+// Validate returns an error if the settings are invalid
+func (s *Settings) Validate() error {
+	for _, locale := range s.Locales {
+		if locale == s.DefaultLocale {
+			return nil
+		}
+	}
+	return fmt.Errorf("default_locale must be one of the locales: %v", s.DefaultLocale)
+}
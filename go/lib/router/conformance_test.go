@@ -0,0 +1,96 @@
+package router_test
+
+import (
+	gocontext "context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/lib/router/routertest"
+)
+
+type generateRouterSetup struct{}
+
+func (setup *generateRouterSetup) NewRouter(log logrus.FieldLogger) router.Router {
+	return router.NewGenerateRouter(log)
+}
+
+func (setup *generateRouterSetup) RunServer(r router.Router, callback func()) {
+	callback()
+}
+
+func (setup *generateRouterSetup) Requester(r router.Router) router.Requester {
+	return r.Requester()
+}
+
+type webMuxRouterSetup struct{}
+
+func (setup *webMuxRouterSetup) NewRouter(log logrus.FieldLogger) router.Router {
+	return router.NewWebRouter(0, router.DefaultServerSettings(), log)
+}
+
+func (setup *webMuxRouterSetup) RunServer(r router.Router, callback func()) {
+	callback()
+}
+
+func (setup *webMuxRouterSetup) Requester(r router.Router) router.Requester {
+	return r.(*router.WebRouter).MuxRequester()
+}
+
+type webRouterSetup struct {
+	t *testing.T
+}
+
+func (setup *webRouterSetup) NewRouter(log logrus.FieldLogger) router.Router {
+	settings := router.DefaultServerSettings()
+	settings.Host = "127.0.0.1"
+	return router.NewWebRouter(0, settings, log)
+}
+
+func (setup *webRouterSetup) webRouter(r router.Router) *router.WebRouter {
+	webRouter, ok := r.(*router.WebRouter)
+	if !ok {
+		setup.t.Fatal("Not a *router.WebRouter being passed")
+	}
+	return webRouter
+}
+
+func (setup *webRouterSetup) RunServer(r router.Router, callback func()) {
+	webRouter := setup.webRouter(r)
+	if err := webRouter.Listen(); err != nil {
+		setup.t.Fatal(err)
+	}
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	done := make(chan error)
+	go func() {
+		done <- webRouter.RunContext(ctx)
+	}()
+	callback()
+	cancel()
+	if err := <-done; err != nil {
+		setup.t.Error(err)
+	}
+}
+
+func (setup *webRouterSetup) Requester(r router.Router) router.Requester {
+	return setup.webRouter(r).Requester()
+}
+
+func TestGenerateRouter_Conformance(t *testing.T) {
+	routertest.Run(t, &generateRouterSetup{})
+}
+
+func TestWebRouter_Conformance(t *testing.T) {
+	routertest.Run(t, &webRouterSetup{t})
+}
+
+func TestWebRouter_MuxConformance(t *testing.T) {
+	routertest.Run(t, &webMuxRouterSetup{})
+}
+
+func TestWebRouter_Parity(t *testing.T) {
+	routertest.RunParity(t, &generateRouterSetup{}, &webMuxRouterSetup{})
+	routertest.RunParity(t, &generateRouterSetup{}, &webRouterSetup{t})
+}
@@ -1,20 +1,24 @@
 package router
 
 import (
-	"fmt"
-	"mime"
 	"net/http"
-	"path"
-	"strconv"
 
 	"github.com/sirupsen/logrus"
 )
 
-// RunFileServer hosts the files of targetDir into given port with the log
-func RunFileServer(targetDir string, port int, log logrus.FieldLogger) error {
-	log.Infof("Serving files from '%v' at http://localhost:%v/", targetDir, port)
-	handler := http.FileServer(http.Dir(targetDir))
-	return http.ListenAndServe(":"+strconv.Itoa(port), handler)
+// RunFileServer hosts the files of targetDir into given port with the settings and log,
+// under the basePath (see WebRouter.SetBasePath), until SIGINT or SIGTERM is received
+func RunFileServer(targetDir, basePath string, port int, settings *ServerSettings, log logrus.FieldLogger) error {
+	s := newServer(port, settings, http.FileServer(http.Dir(targetDir)), log)
+	s.setBasePath(basePath)
+	if err := s.listen(); err != nil {
+		return err
+	}
+	log.Infof("Serving files from '%v' at %v", targetDir, s.url())
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	return s.serve(ctx)
 }
 
 type generateRoute struct {
@@ -67,11 +71,11 @@ func (router *GenerateRouter) GetHTML(url string, handler ContextHandler) {
 
 // Get define a handler for any file type given a URL
 func (router *GenerateRouter) Get(url string, handler ContextHandler) {
-	router.checkAndSetRoutes(url, mime.TypeByExtension(path.Ext(url)), handler)
+	router.checkAndSetRoutes(url, urlContentType(url), handler)
 }
 
 func (router *GenerateRouter) checkAndSetHTMLRoutes(url string, handler ContextHandler) {
-	router.checkAndSetRoutes(url, mime.TypeByExtension(".html"), handler)
+	router.checkAndSetRoutes(url, htmlContentType(), handler)
 }
 
 func (router *GenerateRouter) hasRoute(url string) bool {
@@ -91,7 +95,7 @@ func (router *GenerateRouter) checkAndSetRoutes(url, contentType string, handler
 func (router *GenerateRouter) get(url string) (*Response, error) {
 	route := router.routes[url]
 	if route == nil {
-		return nil, fmt.Errorf("url not found: %v", url)
+		return nil, errURLNotFound(url)
 	}
 
 	ctx := newContext(router.log)
@@ -105,6 +109,15 @@ func (router *GenerateRouter) get(url string) (*Response, error) {
 	return NewResponse(ctx.response, ctx.contentType), nil
 }
 
+// ContentType returns the default Content-Type of the route given the URL, before any handlers set it via Context
+func (router *GenerateRouter) ContentType(url string) string {
+	route := router.routes[handleURLSlash(url)]
+	if route == nil {
+		return ""
+	}
+	return route.ContentType
+}
+
 // URLs returns a list the URLs defined on the router
 func (router *GenerateRouter) URLs() []string {
 	staticRoutes := make([]string, len(router.routes))
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsContentType is the Content-Type of the Prometheus text format
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultMetricsBuckets are the upper bounds (in seconds) of the request duration histogram buckets
+var DefaultMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects the request counts, errors, durations and in-flight requests of the routes
+// via an AroundHandler, and writes them in the Prometheus text format
+type Metrics struct {
+	buckets []float64
+	// skippedURLs are not recorded, such as the URL serving the metrics
+	skippedURLs map[string]bool
+
+	routes   map[string]*routeMetrics
+	inFlight int
+	mutex    *sync.Mutex
+}
+
+type routeMetrics struct {
+	count        int
+	errorCount   int
+	durationSum  float64
+	bucketCounts []int
+}
+
+// NewMetrics returns a new instance of Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		DefaultMetricsBuckets,
+		map[string]bool{},
+		map[string]*routeMetrics{},
+		0,
+		&sync.Mutex{},
+	}
+}
+
+// Around is the AroundHandler that records the metrics of the route, add it via Router.Around
+func (metrics *Metrics) Around(ctx Context, handler ContextHandler) error {
+	route := ctx.URL()
+	if metrics.skippedURLs[route] {
+		return handler(ctx)
+	}
+
+	metrics.addInFlight(1)
+	defer metrics.addInFlight(-1)
+
+	start := time.Now()
+	err := handler(ctx)
+	metrics.record(route, time.Since(start), err != nil)
+	return err
+}
+
+func (metrics *Metrics) addInFlight(delta int) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+	metrics.inFlight += delta
+}
+
+func (metrics *Metrics) record(route string, duration time.Duration, hasError bool) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	r, has := metrics.routes[route]
+	if !has {
+		r = &routeMetrics{bucketCounts: make([]int, len(metrics.buckets))}
+		metrics.routes[route] = r
+	}
+
+	r.count++
+	if hasError {
+		r.errorCount++
+	}
+	seconds := duration.Seconds()
+	r.durationSum += seconds
+	for i, bucket := range metrics.buckets {
+		if seconds <= bucket {
+			r.bucketCounts[i]++
+		}
+	}
+}
+
+// Handler returns the ContextHandler that responds with the metrics in the Prometheus text format
+func (metrics *Metrics) Handler() ContextHandler {
+	return func(ctx Context) error {
+		buffer := &bytes.Buffer{}
+		if err := metrics.WritePrometheus(buffer); err != nil {
+			return err
+		}
+		ctx.SetContentType(MetricsContentType)
+		ctx.Respond(buffer.Bytes())
+		return nil
+	}
+}
+
+// WritePrometheus writes the metrics to w in the Prometheus text format
+func (metrics *Metrics) WritePrometheus(w io.Writer) error {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	routes := make([]string, 0, len(metrics.routes))
+	for route := range metrics.routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	var lines []string
+	lines = append(lines,
+		"# HELP gostatic_requests_total Total number of requests by route.",
+		"# TYPE gostatic_requests_total counter")
+	for _, route := range routes {
+		lines = append(lines, fmt.Sprintf("gostatic_requests_total{route=%v} %v", metricsLabel(route), metrics.routes[route].count))
+	}
+
+	lines = append(lines,
+		"# HELP gostatic_request_errors_total Total number of requests with handler errors by route.",
+		"# TYPE gostatic_request_errors_total counter")
+	for _, route := range routes {
+		lines = append(lines, fmt.Sprintf("gostatic_request_errors_total{route=%v} %v", metricsLabel(route), metrics.routes[route].errorCount))
+	}
+
+	lines = append(lines,
+		"# HELP gostatic_request_duration_seconds Duration of the route handlers in seconds.",
+		"# TYPE gostatic_request_duration_seconds histogram")
+	for _, route := range routes {
+		lines = append(lines, metrics.histogramLines(route)...)
+	}
+
+	lines = append(lines,
+		"# HELP gostatic_requests_in_flight Number of requests being handled.",
+		"# TYPE gostatic_requests_in_flight gauge",
+		fmt.Sprintf("gostatic_requests_in_flight %v", metrics.inFlight))
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func (metrics *Metrics) histogramLines(route string) []string {
+	r := metrics.routes[route]
+	label := metricsLabel(route)
+
+	lines := make([]string, 0, len(metrics.buckets)+3)
+	for i, bucket := range metrics.buckets {
+		lines = append(lines, fmt.Sprintf("gostatic_request_duration_seconds_bucket{route=%v,le=\"%v\"} %v", label, bucket, r.bucketCounts[i]))
+	}
+	return append(lines,
+		fmt.Sprintf("gostatic_request_duration_seconds_bucket{route=%v,le=\"+Inf\"} %v", label, r.count),
+		fmt.Sprintf("gostatic_request_duration_seconds_sum{route=%v} %v", label, r.durationSum),
+		fmt.Sprintf("gostatic_request_duration_seconds_count{route=%v} %v", label, r.count))
+}
+
+var metricsLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func metricsLabel(value string) string {
+	return `"` + metricsLabelReplacer.Replace(value) + `"`
+}
@@ -0,0 +1,90 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestWebRouter_ServeMetrics(t *testing.T) {
+	router, _, _ := defaultWebRouter()
+	metrics := router.ServeMetrics("/metrics")
+	metrics.buckets = []float64{0, 10}
+
+	router.GetRootHTML(func(ctx Context) error {
+		return nil
+	})
+	router.GetHTML("/fail", func(ctx Context) error {
+		return fmt.Errorf("failed")
+	})
+	router.GetHTML(`/quote"d`, func(ctx Context) error {
+		return nil
+	})
+
+	requester := router.MuxRequester()
+	for _, url := range []string{RootURL, RootURL, "/fail", `/quote"d`} {
+		_, err := requester.Get(url)
+		if err != nil && url != "/fail" {
+			t.Error(err)
+		}
+	}
+
+	_, err := requester.Get("/metrics")
+	test.AssertError(t, err, "requester.Get")
+	response, err := requester.Get("/metrics")
+	test.AssertError(t, err, "requester.Get again")
+	test.AssertLabel(t, "MimeType", response.MimeType, MetricsContentType)
+
+	lines := strings.Split(string(response.Body), "\n")
+	for _, exp := range []string{
+		`gostatic_requests_total{route="/"} 2`,
+		`gostatic_requests_total{route="/fail"} 1`,
+		`gostatic_requests_total{route="/quote\"d"} 1`,
+		`gostatic_request_errors_total{route="/"} 0`,
+		`gostatic_request_errors_total{route="/fail"} 1`,
+		`gostatic_request_duration_seconds_bucket{route="/",le="10"} 2`,
+		`gostatic_request_duration_seconds_bucket{route="/",le="+Inf"} 2`,
+		`gostatic_request_duration_seconds_count{route="/fail"} 1`,
+		"# TYPE gostatic_request_duration_seconds histogram",
+		// the /metrics requests are not recorded
+		"gostatic_requests_in_flight 0",
+	} {
+		found := false
+		for _, line := range lines {
+			if line == exp {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("line not found in metrics: %v\n%v", exp, string(response.Body))
+		}
+	}
+	test.AssertLabel(t, "metrics route", strings.Contains(string(response.Body), `route="/metrics"`), false)
+}
+
+func TestMetrics_Around_Panic(t *testing.T) {
+	metrics := NewMetrics()
+	log, _ := logTest.NewNullLogger()
+	ctx := newContext(log)
+	func() {
+		defer func() {
+			test.AssertLabel(t, "recovered", recover(), "handler panic")
+		}()
+		test.AssertError(t, metrics.Around(ctx, func(ctx Context) error {
+			panic("handler panic")
+		}), "metrics.Around")
+	}()
+	test.AssertLabel(t, "inFlight", metrics.inFlight, 0)
+}
+
+func TestMetrics_WritePrometheus_Empty(t *testing.T) {
+	builder := &strings.Builder{}
+	test.AssertError(t, NewMetrics().WritePrometheus(builder), "WritePrometheus")
+	test.AssertLabel(t, "in flight", strings.HasSuffix(builder.String(), "gostatic_requests_in_flight 0\n"), true)
+	test.AssertLabel(t, "requests", strings.Contains(builder.String(), "gostatic_requests_total{"), false)
+}
@@ -6,6 +6,7 @@ package router
 
 import (
 	"fmt"
+	"mime"
 	"path"
 
 	"github.com/sirupsen/logrus"
@@ -40,6 +41,15 @@ type Context interface {
 	Respond(bytes []byte)
 }
 
+// ResponseContext is a Context that returns its response data, so Around handlers can post-process it.
+// The contexts of the routers implement it, check for it with a type assertion.
+type ResponseContext interface {
+	Context
+
+	// Response returns the response data of the request
+	Response() []byte
+}
+
 // context provided for every route
 type context struct {
 	log         logrus.FieldLogger
@@ -84,6 +94,11 @@ func (ctx *context) Respond(bytes []byte) {
 	ctx.response = bytes
 }
 
+// Response returns the response data of the request, so Around handlers can post-process it
+func (ctx *context) Response() []byte {
+	return ctx.response
+}
+
 // Router is the interface for all routers.
 type Router interface {
 	// Around is a callback/handler that is called around all routes
@@ -126,6 +141,20 @@ func handleURLSlash(url string) string {
 	return url
 }
 
+// htmlContentType returns the Content-Type of HTML routes
+func htmlContentType() string {
+	return mime.TypeByExtension(".html")
+}
+
+// urlContentType returns the default Content-Type of the url, given by it's extension
+func urlContentType(url string) string {
+	return mime.TypeByExtension(path.Ext(url))
+}
+
+func errURLNotFound(url string) error {
+	return fmt.Errorf("url not found: %v", url)
+}
+
 func panicDuplicateRoute(url string) {
 	panic(fmt.Sprintf("%v is a duplicate route", url))
 }
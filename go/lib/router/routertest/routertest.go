@@ -0,0 +1,583 @@
+/*
+Package routertest is a conformance test suite for router.Router implementations, so custom routers
+behave the same as the routers of the router package.
+
+Implement Setup for your router and call Run within a test:
+
+	func TestMyRouter(t *testing.T) {
+		routertest.Run(t, &MyRouterSetup{})
+	}
+*/
+package routertest
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/test"
+)
+
+// Setup sets up a router.Router implementation for the suite
+type Setup interface {
+	// NewRouter returns a new instance of the router with the given log
+	NewRouter(log logrus.FieldLogger) router.Router
+	// RunServer runs the server of the router (if any) around the callback
+	RunServer(r router.Router, callback func())
+	// Requester returns the requester to make requests with, called within the RunServer callback
+	Requester(r router.Router) router.Requester
+}
+
+// SetRoute sets a route with the handler on the router
+type SetRoute func(r router.Router, url string, handler router.ContextHandler)
+
+// Run runs the conformance suite on the router given by the setup, each check is a subtest
+func Run(t *testing.T, setup Setup) {
+	checks := []struct {
+		name  string
+		check func(t *testing.T, setup Setup)
+	}{
+		{"Around", checkAround},
+		{"AroundError", checkAroundError},
+		{"AroundResponse", checkAroundResponse},
+		{"GetInvalidRoute", checkGetInvalidRoute},
+		{"GetRootHTML", checkGetRootHTML},
+		{"GetHTML", checkGetHTML},
+		{"GetDirectoryHTML", checkGetDirectoryHTML},
+		{"Get", checkGet},
+		{"GetRoot", checkGetRoot},
+		{"GetWithContentTypeSet", checkGetWithContentTypeSet},
+		{"URLs", checkURLs},
+		{"Requester", checkRequester},
+		{"Folders", checkFolders},
+	}
+	for _, c := range checks {
+		check := c.check
+		t.Run(c.name, func(t *testing.T) {
+			check(t, setup)
+		})
+	}
+}
+
+// RunParity sets the same routes on the routers of both setups, then requests every URL
+// on both and checks that the responses and errors are the same
+func RunParity(t *testing.T, expSetup, gotSetup Setup) {
+	log, _ := logTest.NewNullLogger()
+	expRouter, gotRouter := expSetup.NewRouter(log), gotSetup.NewRouter(log)
+	for _, r := range []router.Router{expRouter, gotRouter} {
+		setAllRoutes(r)
+		r.GetHTML("/error", func(ctx router.Context) error {
+			return fmt.Errorf("handler error")
+		})
+	}
+
+	expSetup.RunServer(expRouter, func() {
+		gotSetup.RunServer(gotRouter, func() {
+			urls := append(sortedURLs(expRouter), "/does_not_exist")
+			test.AssertArray(t, "URLs", sortedURLs(gotRouter), urls[:len(urls)-1])
+
+			for index, url := range urls {
+				context := test.NewContext(t).SetFields(test.ContextFields{
+					"index": index,
+					"url":   url,
+				})
+
+				exp, expErr := expSetup.Requester(expRouter).Get(url)
+				got, gotErr := gotSetup.Requester(gotRouter).Get(url)
+				if expErr != nil || gotErr != nil {
+					context.Assert("error", fmt.Sprint(gotErr), fmt.Sprint(expErr))
+					continue
+				}
+				context.Assert("Response.Body", string(got.Body), string(exp.Body))
+				context.Assert("Response.MimeType", got.MimeType, exp.MimeType)
+			}
+		})
+	})
+}
+
+func newRouter(setup Setup) (router.Router, logrus.FieldLogger, *logTest.Hook) {
+	log, hook := logTest.NewNullLogger()
+	return setup.NewRouter(log), log, hook
+}
+
+func sortedURLs(r router.Router) []string {
+	urls := r.URLs()
+	sort.Strings(urls)
+	return urls
+}
+
+func checkAround(t *testing.T, setup Setup) {
+	var got []string
+	var previousContext router.Context
+
+	testPreviousContext := func(ctx router.Context) {
+		if previousContext == nil {
+			previousContext = ctx
+		} else {
+			test.AssertLabel(t, "ctx", ctx, previousContext)
+		}
+	}
+
+	h := func(before, after string) router.AroundHandler {
+		return func(ctx router.Context, handler router.ContextHandler) error {
+			testPreviousContext(ctx)
+
+			if before != "" {
+				got = append(got, before)
+			}
+			err := handler(ctx)
+			if after != "" {
+				got = append(got, after)
+			}
+			return err
+		}
+	}
+
+	testCases := []struct {
+		handlers []router.AroundHandler
+		expected []string
+	}{
+		{[]router.AroundHandler{}, []string{"call"}},
+		{[]router.AroundHandler{h("b1", "")}, []string{"b1", "call"}},
+		{[]router.AroundHandler{h("b1", ""), h("b2", "")}, []string{"b1", "b2", "call"}},
+		{[]router.AroundHandler{h("", "a1")}, []string{"call", "a1"}},
+		{[]router.AroundHandler{h("", "a1"), h("", "a2")}, []string{"call", "a2", "a1"}},
+		{[]router.AroundHandler{h("ar1", "ar2")}, []string{"ar1", "call", "ar2"}},
+		{[]router.AroundHandler{h("ar1", "ar2"), h("arr1", "arr2")}, []string{"ar1", "arr1", "call", "arr2", "ar2"}},
+		{[]router.AroundHandler{h("ar1", "ar2"), h("", "a1"), h("b1", ""), h("arr1", "arr2")}, []string{"ar1", "b1", "arr1", "call", "arr2", "a1", "ar2"}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		got = nil
+		previousContext = nil
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":       testCaseIndex,
+			"handlersLen": len(tc.handlers),
+		})
+
+		r, _, _ := newRouter(setup)
+		r.GetRootHTML(func(ctx router.Context) error {
+			testPreviousContext(ctx)
+
+			got = append(got, "call")
+			return nil
+		})
+
+		for _, handler := range tc.handlers {
+			r.Around(handler)
+		}
+
+		setup.RunServer(r, func() {
+			_, err := setup.Requester(r).Get(router.RootURL)
+			context.AssertError(err, "Requester.Get")
+			context.AssertArray("arounds", got, tc.expected)
+		})
+	}
+}
+
+func checkAroundError(t *testing.T, setup Setup) {
+	expError := "around error"
+
+	testCases := []struct {
+		around     router.AroundHandler
+		expCalled  bool
+		expMessage string
+	}{
+		{func(ctx router.Context, handler router.ContextHandler) error {
+			return fmt.Errorf("%v", expError)
+		}, false, expError},
+		{func(ctx router.Context, handler router.ContextHandler) error {
+			return handler(ctx)
+		}, true, "handler error"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+		})
+
+		called := false
+		r, _, _ := newRouter(setup)
+		r.Around(tc.around)
+		r.GetHTML("/page", func(ctx router.Context) error {
+			called = true
+			return fmt.Errorf("handler error")
+		})
+
+		setup.RunServer(r, func() {
+			response, err := setup.Requester(r).Get("/page")
+			if err == nil {
+				t.Fatal(context.String("expected error"))
+			}
+			context.Assert("error", err.Error(), tc.expMessage)
+			context.Assert("response", response == nil, true)
+			context.Assert("called", called, tc.expCalled)
+		})
+	}
+}
+
+func checkAroundResponse(t *testing.T, setup Setup) {
+	r, _, _ := newRouter(setup)
+	r.Around(func(ctx router.Context, handler router.ContextHandler) error {
+		if err := handler(ctx); err != nil {
+			return err
+		}
+		ctx.Respond([]byte(strings.ToUpper(string(ctx.(router.ResponseContext).Response()))))
+		return nil
+	})
+	r.GetHTML("/page", func(ctx router.Context) error {
+		ctx.Respond([]byte("page"))
+		return nil
+	})
+
+	setup.RunServer(r, func() {
+		response, err := setup.Requester(r).Get("/page")
+		test.AssertError(t, err, "Requester.Get")
+		test.AssertLabel(t, "Response.Body", string(response.Body), "PAGE")
+	})
+}
+
+func checkGetInvalidRoute(t *testing.T, setup Setup) {
+	r, _, _ := newRouter(setup)
+	r.GetRootHTML(func(ctx router.Context) error {
+		return nil
+	})
+	setup.RunServer(r, func() {
+		for _, url := range []string{"/does_not_exist", "/does/not/exist.txt"} {
+			response, err := setup.Requester(r).Get(url)
+			if err == nil {
+				t.Errorf("expecting error for %v", url)
+			}
+			if response != nil {
+				t.Errorf("expecting no response for %v", url)
+			}
+		}
+	})
+}
+
+func checkGetRootHTML(t *testing.T, setup Setup) {
+	checkRoute(t, setup, router.RootURL, mime.TypeByExtension(".html"), func(r router.Router, url string, handler router.ContextHandler) {
+		if url == router.RootURL {
+			r.GetRootHTML(handler)
+		} else {
+			r.GetHTML(url, handler)
+		}
+	})
+}
+
+func checkGetHTML(t *testing.T, setup Setup) {
+	checkRoute(t, setup, "/blah", mime.TypeByExtension(".html"), func(r router.Router, url string, handler router.ContextHandler) {
+		r.GetHTML(url, handler)
+	})
+}
+
+func checkGetDirectoryHTML(t *testing.T, setup Setup) {
+	r, _, _ := newRouter(setup)
+	handler := func(ctx router.Context) error {
+		ctx.Respond([]byte(ctx.URL()))
+		return nil
+	}
+	r.GetHTML("/dir/", handler)
+	r.GetHTML("/dir/page", handler)
+
+	setup.RunServer(r, func() {
+		for _, url := range []string{"/dir/", "/dir/page"} {
+			response, err := setup.Requester(r).Get(url)
+			test.AssertError(t, err, "Requester.Get "+url)
+			test.AssertLabel(t, "Response.Body "+url, string(response.Body), url)
+		}
+
+		_, err := setup.Requester(r).Get("/dir/does_not_exist")
+		if err == nil {
+			t.Error("expecting error for /dir/does_not_exist")
+		}
+	})
+}
+
+func checkGet(t *testing.T, setup Setup) {
+	for _, url := range []string{"/blah.xml", "/blah.css", "/blah.js", "/blah.png", "/blah.json"} {
+		checkRoute(t, setup, url, mime.TypeByExtension(path.Ext(url)), func(r router.Router, url string, handler router.ContextHandler) {
+			r.Get(url, handler)
+		})
+	}
+}
+
+func checkGetRoot(t *testing.T, setup Setup) {
+	checkRouteContext(t, setup, router.RootURL, mime.TypeByExtension(""), func(r router.Router, url string, handler router.ContextHandler) {
+		r.Get(url, handler)
+	})
+}
+
+func checkGetWithContentTypeSet(t *testing.T, setup Setup) {
+	checkRoute(t, setup, "/something.fakeext", "text/plain; charset=utf-8", func(r router.Router, url string, handler router.ContextHandler) {
+		r.Get(url, func(ctx router.Context) error {
+			ctx.SetContentType("text/plain; charset=utf-8")
+			return handler(ctx)
+		})
+	})
+}
+
+func checkRoute(t *testing.T, setup Setup, url, contentType string, setRoute SetRoute) {
+	checkRouteContext(t, setup, url, contentType, setRoute)
+	checkRouteErrors(t, setup, url, setRoute)
+	checkRouteSlash(t, setup, url, setRoute)
+}
+
+func checkRouteContext(t *testing.T, setup Setup, url, contentType string, setRoute SetRoute) {
+	called := false
+	r, log, _ := newRouter(setup)
+
+	expResponse := "The Response"
+	setRoute(r, url, func(ctx router.Context) error {
+		called = true
+		test.AssertLabel(t, "ctx.Log()", ctx.Log(), log)
+		test.AssertLabel(t, "ctx.URL()", ctx.URL(), url)
+		test.AssertLabel(t, "ctx.ContentType()", ctx.ContentType(), contentType)
+		ctx.Respond([]byte(expResponse))
+		return nil
+	})
+	setup.RunServer(r, func() {
+		response, err := setup.Requester(r).Get(url)
+		if err != nil {
+			t.Fatal(test.AssertErrorString(err, "Requester.Get"))
+		}
+		test.AssertLabel(t, "Response.Body", string(response.Body), expResponse)
+		test.AssertLabel(t, "Response.MimeType", response.MimeType, contentType)
+		test.AssertLabel(t, "called", called, true)
+	})
+}
+
+func checkRouteErrors(t *testing.T, setup Setup, url string, setRoute SetRoute) {
+	expError := "test error"
+
+	r, _, _ := newRouter(setup)
+	setRoute(r, url, func(ctx router.Context) error {
+		return fmt.Errorf("%v", expError)
+	})
+
+	setup.RunServer(r, func() {
+		_, err := setup.Requester(r).Get(url)
+		if err == nil {
+			t.Fatal("Handler error not given")
+		}
+		test.AssertLabel(t, "Handler error", err.Error(), expError)
+
+		_, err = setup.Requester(r).Get("/multipart/url")
+		if err == nil {
+			t.Error("Multipart URLs are not giving errors")
+		}
+	})
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Did not panic for duplicate route setup.")
+			}
+		}()
+		setRoute(r, url, func(ctx router.Context) error {
+			return nil
+		})
+	}()
+}
+
+func checkRouteSlash(t *testing.T, setup Setup, url string, setRoute SetRoute) {
+	trimmedURL := strings.TrimLeft(url, "/")
+
+	testCases := []struct {
+		routeURL   string
+		requestURL string
+	}{
+		{url, url},
+		{trimmedURL, url},
+		{url, trimmedURL},
+		{trimmedURL, trimmedURL},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":      testCaseIndex,
+			"routeURL":   tc.routeURL,
+			"requestURL": tc.requestURL,
+		})
+
+		r, _, _ := newRouter(setup)
+		setRoute(r, tc.routeURL, func(ctx router.Context) error {
+			return nil
+		})
+
+		setup.RunServer(r, func() {
+			_, err := setup.Requester(r).Get(tc.requestURL)
+			context.AssertError(err, "Requester.Get")
+		})
+	}
+}
+
+func checkURLs(t *testing.T, setup Setup) {
+	testCases := []struct {
+		htmlRoutes  []string
+		otherRoutes []string
+	}{
+		{nil, nil},
+		{[]string{}, []string{}},
+		{[]string{"/some"}, []string{"/something.xml"}},
+		{[]string{"/some", "/ha", "/works"}, []string{"/something.xml", "/main.css"}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":       testCaseIndex,
+			"htmlRoutes":  tc.htmlRoutes,
+			"otherRoutes": tc.otherRoutes,
+		})
+
+		r, _, _ := newRouter(setup)
+		exp := []string{}
+		if tc.htmlRoutes != nil {
+			handler := func(ctx router.Context) error { return nil }
+			r.GetRootHTML(handler)
+			for _, url := range tc.htmlRoutes {
+				r.GetHTML(url, handler)
+			}
+			for _, url := range tc.otherRoutes {
+				r.Get(url, handler)
+			}
+			exp = append(append(append(exp, router.RootURL), tc.htmlRoutes...), tc.otherRoutes...)
+		}
+		sort.Strings(exp)
+		context.AssertArray("URLs", sortedURLs(r), exp)
+	}
+}
+
+type routeWithResponse struct {
+	url      string
+	response string
+}
+
+var routesWithResponse = []routeWithResponse{
+	{router.RootURL, `<p>the root of it all</p>`},
+	{"/page", `<html>some page</html>`},
+	{"/another_page", `<html>another_page</html>`},
+	{"/something.xml", `<?xml version="1.0" encoding="UTF-8"?>`},
+	{"/main.css", "body { color: red; }"},
+	{"/files/haha.json", `{ "haha": true }`},
+	{"/files/waa", `<html>waa</html>`},
+	{"/files/hmm.html", `<html>hmm</html>`},
+	{"/files/more/morez", `<html>morez</html>`},
+	{"/files/more/deep.js", "var deep = true;"},
+	{"/files/no_type.fakeext", "no type"},
+}
+
+func routeContentType(url string) string {
+	if url == router.RootURL || path.Ext(url) == "" {
+		return mime.TypeByExtension(".html")
+	}
+	return mime.TypeByExtension(path.Ext(url))
+}
+
+func setAllRoutes(r router.Router) {
+	for _, route := range routesWithResponse {
+		response := route.response
+		handler := func(ctx router.Context) error {
+			ctx.Respond([]byte(response))
+			return nil
+		}
+
+		switch {
+		case route.url == router.RootURL:
+			r.GetRootHTML(handler)
+		case path.Ext(route.url) == "":
+			r.GetHTML(route.url, handler)
+		default:
+			r.Get(route.url, handler)
+		}
+	}
+}
+
+func checkRequester(t *testing.T, setup Setup) {
+	r, _, _ := newRouter(setup)
+	setAllRoutes(r)
+
+	setup.RunServer(r, func() {
+		requester := setup.Requester(r)
+		for index, route := range routesWithResponse {
+			context := test.NewContext(t).SetFields(test.ContextFields{
+				"index": index,
+				"url":   route.url,
+			})
+
+			response, err := requester.Get(route.url)
+			if err != nil {
+				context.AssertError(err, "requester.Get")
+				continue
+			}
+			context.Assert("Response.Body", string(response.Body), route.response)
+			context.Assert("Response.MimeType", response.MimeType, routeContentType(route.url))
+
+			if route.url != router.RootURL {
+				_, err := requester.Get(route.url[1:])
+				if err != nil {
+					t.Error(context.String("Can't handle requester.Get without / prefix"))
+				}
+			}
+		}
+	})
+}
+
+func checkFolders(t *testing.T, setup Setup) {
+	testCases := []struct {
+		urls  []string
+		panic bool
+	}{
+		{[]string{"/blah"}, false},
+		{[]string{"/blah", "/haha.xml"}, false},
+		{[]string{"/blah", "/blah/haha.xml"}, true},
+		{[]string{"/blah/haha.xml", "/blah"}, true},
+		{[]string{"/blah", "/blah/haha"}, true},
+		{[]string{"/blah/haha", "/blah"}, true},
+		{[]string{"/blah/he", "/blah/haha.xml"}, false},
+		{[]string{"/blah/he", "/blah/haha.xml", "/blah/wa.css"}, false},
+		{[]string{"/blah/he", "/blah/he/ni.xml"}, true},
+		{[]string{"/blah/he/ni.xml", "/blah/he"}, true},
+		{[]string{"/blah/he", "/blah/he/ni"}, true},
+		{[]string{"/blah/he/ni", "/blah/he"}, true},
+		{[]string{"/blah/", "/blah/he"}, false},
+		{[]string{"/blah", "/blah/"}, true},
+	}
+
+	handler := func(ctx router.Context) error {
+		ctx.Respond([]byte(ctx.URL()))
+		return nil
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"urls":  tc.urls,
+		})
+
+		r, _, _ := newRouter(setup)
+		r.GetRootHTML(handler)
+
+		func() {
+			defer func() {
+				if recovered := recover(); (recovered != nil) != tc.panic {
+					t.Error(context.AssertString("panic", recovered != nil, tc.panic))
+				}
+			}()
+
+			for _, url := range tc.urls {
+				if path.Ext(url) == "" {
+					r.GetHTML(url, handler)
+				} else {
+					r.Get(url, handler)
+				}
+			}
+		}()
+	}
+}
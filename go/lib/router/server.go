@@ -0,0 +1,239 @@
+package router
+
+import (
+	gocontext "context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ServerSettings represents the settings of the servers, the WebRouter and RunFileServer
+type ServerSettings struct {
+	// Host is the host to bind to, empty binds to all interfaces
+	Host string `json:"host,omitempty"`
+	// ReadTimeout is the maximum seconds for reading the entire request, 0 for no timeout
+	ReadTimeout int `json:"read_timeout,omitempty"`
+	// WriteTimeout is the maximum seconds for writing the response, 0 for no timeout
+	WriteTimeout int `json:"write_timeout,omitempty"`
+	// ShutdownTimeout is the maximum seconds to wait for in-flight requests when shutting down, 0 for no timeout
+	ShutdownTimeout int `json:"shutdown_timeout,omitempty"`
+
+	TLS *TLSSettings `json:"tls,omitempty"`
+}
+
+// Validate returns an error if the settings are invalid
+func (settings *ServerSettings) Validate() error {
+	if settings.ReadTimeout < 0 || settings.WriteTimeout < 0 || settings.ShutdownTimeout < 0 {
+		return fmt.Errorf("timeouts must be 0 or greater")
+	}
+	return nil
+}
+
+// DefaultServerSettings returns the default ServerSettings
+func DefaultServerSettings() *ServerSettings {
+	return &ServerSettings{
+		"",
+		10,
+		60,
+		10,
+		DefaultTLSSettings(),
+	}
+}
+
+func seconds(i int) time.Duration {
+	return time.Duration(i) * time.Second
+}
+
+// server wraps http.Server to listen with the ServerSettings and shut down gracefully
+type server struct {
+	port     int
+	settings *ServerSettings
+	handler  http.Handler
+	basePath string
+	log      logrus.FieldLogger
+
+	listener      net.Listener
+	tlsConfig     *tls.Config
+	listenerMutex *sync.RWMutex
+}
+
+func newServer(port int, settings *ServerSettings, handler http.Handler, log logrus.FieldLogger) *server {
+	return &server{
+		port:          port,
+		settings:      settings,
+		handler:       handler,
+		log:           log,
+		listenerMutex: &sync.RWMutex{},
+	}
+}
+
+// listen binds the address of the server, if it is not already bound
+func (s *server) listen() error {
+	s.listenerMutex.Lock()
+	defer s.listenerMutex.Unlock()
+	if s.listener != nil {
+		return nil
+	}
+
+	if s.settings.TLS.Enabled() {
+		tlsConfig, err := s.settings.TLS.Config(s.log)
+		if err != nil {
+			return err
+		}
+		s.tlsConfig = tlsConfig
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.settings.Host, strconv.Itoa(s.port)))
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	return nil
+}
+
+// addr returns the bound address, which differs from the settings if port 0 is used
+func (s *server) addr() string {
+	s.listenerMutex.RLock()
+	defer s.listenerMutex.RUnlock()
+	if s.listener == nil {
+		return net.JoinHostPort(s.settings.Host, strconv.Itoa(s.port))
+	}
+	return s.listener.Addr().String()
+}
+
+// hostPort returns the hostname and port to reach the server with
+func (s *server) hostPort() (string, int) {
+	host, portString, err := net.SplitHostPort(s.addr())
+	if err != nil {
+		return "localhost", s.port
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		port = s.port
+	}
+
+	ip := net.ParseIP(host)
+	if host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return host, port
+}
+
+func (s *server) url() string {
+	host, port := s.hostPort()
+	return fmt.Sprintf("%v://%v%v/", s.settings.TLS.Scheme(), net.JoinHostPort(host, strconv.Itoa(port)), s.basePath)
+}
+
+// setBasePath serves the handler under the basePath (ex. "/docs/"), as when the site is published under a subpath.
+// The basePath is stripped from the request URLs, so the handler's routes stay the same.
+// Requests for "/" are redirected to the basePath, other requests outside of it are not found.
+func (s *server) setBasePath(basePath string) {
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath == "" {
+		return
+	}
+	if !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+
+	serveMux := http.NewServeMux()
+	serveMux.Handle(basePath+"/", http.StripPrefix(basePath, s.handler))
+	serveMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, basePath+"/", http.StatusFound)
+	})
+	s.handler = serveMux
+	s.basePath = basePath
+}
+
+// serve serves until ctx is done, then shuts down gracefully, waiting for in-flight requests
+func (s *server) serve(ctx gocontext.Context) error {
+	if err := s.listen(); err != nil {
+		return err
+	}
+	// the listener is closed by the http.Server, so the next serve listens again
+	defer s.resetListener()
+
+	httpServer := &http.Server{
+		Handler:      s.handler,
+		TLSConfig:    s.tlsConfig,
+		ReadTimeout:  seconds(s.settings.ReadTimeout),
+		WriteTimeout: seconds(s.settings.WriteTimeout),
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		if s.tlsConfig != nil {
+			errChan <- httpServer.ServeTLS(s.listener, "", "")
+		} else {
+			errChan <- httpServer.Serve(s.listener)
+		}
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.log.Infof("Shutting down server at %v", s.url())
+	if err := s.shutdown(httpServer); err != nil {
+		return err
+	}
+	if err := <-errChan; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// shutdown shuts down the httpServer gracefully within the ShutdownTimeout, closing the remaining connections if it's exceeded
+func (s *server) shutdown(httpServer *http.Server) error {
+	shutdownCtx := gocontext.Background()
+	if s.settings.ShutdownTimeout > 0 {
+		var cancel gocontext.CancelFunc
+		shutdownCtx, cancel = gocontext.WithTimeout(shutdownCtx, seconds(s.settings.ShutdownTimeout))
+		defer cancel()
+	}
+
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		if closeErr := httpServer.Close(); closeErr != nil {
+			s.log.Errorf("error closing server - %v", closeErr)
+		}
+	}
+	return err
+}
+
+func (s *server) resetListener() {
+	s.listenerMutex.Lock()
+	defer s.listenerMutex.Unlock()
+	s.listener = nil
+}
+
+// signalContext returns a context that is done when SIGINT or SIGTERM is received
+func signalContext() (gocontext.Context, gocontext.CancelFunc) {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+		cancel()
+	}()
+	return ctx, cancel
+}
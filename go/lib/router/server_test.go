@@ -0,0 +1,286 @@
+package router
+
+import (
+	gocontext "context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func localServerSettings() *ServerSettings {
+	settings := DefaultServerSettings()
+	settings.Host = "127.0.0.1"
+	return settings
+}
+
+func runWebRouter(t *testing.T, router *WebRouter, callback func()) {
+	test.AssertError(t, router.Listen(), "router.Listen")
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	done := make(chan error)
+	go func() {
+		done <- router.RunContext(ctx)
+	}()
+	callback()
+	cancel()
+	test.AssertError(t, <-done, "router.RunContext")
+}
+
+func TestServer_hostPort(t *testing.T) {
+	testCases := []struct {
+		host    string
+		port    int
+		expHost string
+	}{
+		{"", 8080, "localhost"},
+		{"0.0.0.0", 8080, "localhost"},
+		{"::", 8080, "localhost"},
+		{"127.0.0.1", 3000, "127.0.0.1"},
+		{"example.com", 80, "example.com"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"host":  tc.host,
+			"port":  tc.port,
+		})
+
+		log, _ := logTest.NewNullLogger()
+		settings := DefaultServerSettings()
+		settings.Host = tc.host
+		host, port := newServer(tc.port, settings, nil, log).hostPort()
+		context.Assert("host", host, tc.expHost)
+		context.Assert("port", port, tc.port)
+	}
+}
+
+func TestWebRouter_RunContext(t *testing.T) {
+	log, hook := logTest.NewNullLogger()
+	router := NewWebRouter(0, localServerSettings(), log)
+	router.GetRootHTML(func(ctx Context) error {
+		ctx.Respond([]byte("root"))
+		return nil
+	})
+
+	runWebRouter(t, router, func() {
+		if router.Addr() == "127.0.0.1:0" {
+			t.Error("Addr() did not give the chosen port")
+		}
+
+		response, err := router.Requester().Get(RootURL)
+		test.AssertError(t, err, "Requester.Get")
+		test.AssertLabel(t, "Response.Body", string(response.Body), "root")
+	})
+
+	_, err := router.Requester().Get(RootURL)
+	if err == nil {
+		t.Error("server is still running after RunContext returns")
+	}
+	test.AssertLabel(t, "SafeLogEntries", test.SafeLogEntries(hook), true)
+}
+
+func TestWebRouter_RunContext_IPv6(t *testing.T) {
+	listener, listenErr := net.Listen("tcp", "[::1]:0")
+	if listenErr != nil {
+		t.Skipf("IPv6 is not available - %v", listenErr)
+	}
+	test.AssertError(t, listener.Close(), "listener.Close")
+
+	log, _ := logTest.NewNullLogger()
+	settings := DefaultServerSettings()
+	settings.Host = "::1"
+	router := NewWebRouter(0, settings, log)
+	router.GetRootHTML(func(ctx Context) error {
+		ctx.Respond([]byte("root"))
+		return nil
+	})
+
+	runWebRouter(t, router, func() {
+		response, err := router.Requester().Get(RootURL)
+		test.AssertError(t, err, "Requester.Get")
+		test.AssertLabel(t, "Response.Body", string(response.Body), "root")
+	})
+}
+
+func TestWebRouter_RunContext_Twice(t *testing.T) {
+	log, hook := logTest.NewNullLogger()
+	router := NewWebRouter(0, localServerSettings(), log)
+	router.GetRootHTML(func(ctx Context) error {
+		ctx.Respond([]byte("root"))
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		runWebRouter(t, router, func() {
+			response, err := router.Requester().Get(RootURL)
+			test.AssertError(t, err, "Requester.Get")
+			test.AssertLabel(t, "Response.Body", string(response.Body), "root")
+		})
+	}
+	test.AssertLabel(t, "SafeLogEntries", test.SafeLogEntries(hook), true)
+}
+
+func TestWebRouter_RunContext_Graceful(t *testing.T) {
+	for _, shutdownTimeout := range []int{0, 10} {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"shutdownTimeout": shutdownTimeout,
+		})
+
+		log, _ := logTest.NewNullLogger()
+		settings := localServerSettings()
+		settings.ShutdownTimeout = shutdownTimeout
+		router := NewWebRouter(0, settings, log)
+
+		started := make(chan bool)
+		release := make(chan bool)
+		router.GetRootHTML(func(ctx Context) error {
+			started <- true
+			<-release
+			ctx.Respond([]byte("finished"))
+			return nil
+		})
+
+		context.AssertError(router.Listen(), "router.Listen")
+		ctx, cancel := gocontext.WithCancel(gocontext.Background())
+		done := make(chan error)
+		go func() {
+			done <- router.RunContext(ctx)
+		}()
+
+		responseChan := make(chan *Response)
+		go func() {
+			response, err := router.Requester().Get(RootURL)
+			context.AssertError(err, "Requester.Get")
+			responseChan <- response
+		}()
+
+		<-started
+		cancel()
+		close(release)
+
+		response := <-responseChan
+		if response == nil {
+			t.Fatal(context.String("in-flight request was not drained"))
+		}
+		context.Assert("Response.Body", string(response.Body), "finished")
+		context.AssertError(<-done, "router.RunContext")
+	}
+}
+
+func TestWebRouter_RunContext_ShutdownTimeout(t *testing.T) {
+	log, _ := logTest.NewNullLogger()
+	settings := localServerSettings()
+	settings.ShutdownTimeout = 1
+	router := NewWebRouter(0, settings, log)
+
+	started := make(chan bool)
+	release := make(chan bool)
+	defer close(release)
+	router.GetRootHTML(func(ctx Context) error {
+		started <- true
+		<-release
+		return nil
+	})
+
+	test.AssertError(t, router.Listen(), "router.Listen")
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	done := make(chan error)
+	go func() {
+		done <- router.RunContext(ctx)
+	}()
+
+	requestErr := make(chan error)
+	go func() {
+		_, err := router.Requester().Get(RootURL)
+		requestErr <- err
+	}()
+
+	<-started
+	cancel()
+	test.AssertLabel(t, "RunContext err", <-done, gocontext.DeadlineExceeded)
+	if <-requestErr == nil {
+		t.Error("in-flight request was not closed after the shutdown timeout")
+	}
+}
+
+func TestWebRouter_RunContext_HTTP2(t *testing.T) {
+	tlsSettings, clean := selfSignedTLSSettings(t)
+	defer clean()
+
+	settings := localServerSettings()
+	settings.TLS = tlsSettings
+	log, _ := logTest.NewNullLogger()
+	router := NewWebRouter(0, settings, log)
+	router.GetRootHTML(func(ctx Context) error {
+		return nil
+	})
+
+	runWebRouter(t, router, func() {
+		rootCAs, err := tlsSettings.RootCAs()
+		test.AssertError(t, err, "tlsSettings.RootCAs")
+
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: rootCAs},
+			ForceAttemptHTTP2: true,
+		}}
+		response, err := client.Get("https://" + router.Addr() + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.AssertError(t, response.Body.Close(), "response.Body.Close")
+		test.AssertLabel(t, "ProtoMajor", response.ProtoMajor, 2)
+		client.CloseIdleConnections()
+	})
+}
+
+func TestWebRouter_SetBasePath(t *testing.T) {
+	log, _ := logTest.NewNullLogger()
+	router := NewWebRouter(0, localServerSettings(), log)
+	router.SetBasePath("/docs/")
+	router.GetRootHTML(func(ctx Context) error {
+		ctx.Respond([]byte("root"))
+		return nil
+	})
+	router.GetHTML("/about.html", func(ctx Context) error {
+		ctx.Respond([]byte("about"))
+		return nil
+	})
+
+	runWebRouter(t, router, func() {
+		requester := router.Requester()
+		for url, exp := range map[string]string{RootURL: "root", "/about.html": "about"} {
+			response, err := requester.Get(url)
+			test.AssertError(t, err, "Requester.Get "+url)
+			test.AssertLabel(t, "Response.Body "+url, string(response.Body), exp)
+		}
+
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		testCases := []struct {
+			path     string
+			status   int
+			location string
+		}{
+			{"/", http.StatusFound, "/docs/"},
+			{"/about.html", http.StatusNotFound, ""},
+			{"/docs/about.html", http.StatusOK, ""},
+		}
+		for testCaseIndex, tc := range testCases {
+			context := test.NewContext(t).SetFields(test.ContextFields{
+				"index": testCaseIndex,
+				"path":  tc.path,
+			})
+			response, err := client.Get("http://" + router.Addr() + tc.path)
+			context.AssertError(err, "client.Get")
+			context.AssertError(response.Body.Close(), "response.Body.Close")
+			context.Assert("StatusCode", response.StatusCode, tc.status)
+			context.Assert("Location", response.Header.Get("Location"), tc.location)
+		}
+	})
+	test.AssertLabel(t, "url", router.server.url(), "http://"+router.Addr()+"/docs/")
+}
@@ -0,0 +1,278 @@
+package router
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/utils"
+)
+
+const (
+	caCertFilename = "ca.pem"
+	caKeyFilename  = "ca-key.pem"
+	certFilename   = "cert.pem"
+	keyFilename    = "key.pem"
+
+	caValidFor   = 10 * 365 * 24 * time.Hour
+	certValidFor = 825 * 24 * time.Hour
+)
+
+// TLSSettings represents the settings to serve over HTTPS. Either CertFile and KeyFile are given,
+// or SelfSigned is set to generate a local certificate authority (CA) and a certificate signed by it.
+//
+// When TLS is enabled, HTTP/2 is enabled too.
+type TLSSettings struct {
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+
+	SelfSigned bool `json:"self_signed,omitempty"`
+	// SelfSignedPath is the directory where the local CA and certificate are generated and reused.
+	// Add the CA (ca.pem) to your OS/browser trust store to avoid the browser warnings.
+	SelfSignedPath string `json:"self_signed_path,omitempty"`
+	// Hosts are the hostnames and IPs of the self signed certificate
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// DefaultTLSSettings returns the default TLSSettings, which has TLS disabled
+func DefaultTLSSettings() *TLSSettings {
+	return &TLSSettings{
+		SelfSignedPath: "./tls",
+		Hosts:          []string{"localhost", "127.0.0.1", "::1"},
+	}
+}
+
+// Validate returns an error if the settings are invalid
+func (settings *TLSSettings) Validate() error {
+	if (settings.CertFile == "") != (settings.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be given together")
+	}
+	return nil
+}
+
+// Enabled returns true if TLS is enabled for the settings
+func (settings *TLSSettings) Enabled() bool {
+	return settings != nil && (settings.SelfSigned || settings.CertFile != "")
+}
+
+// Scheme returns the URL scheme of the settings, https when Enabled, otherwise http
+func (settings *TLSSettings) Scheme() string {
+	if settings.Enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// Config returns the *tls.Config for the settings with HTTP/2 enabled.
+// If SelfSigned, the CA and certificate are generated into SelfSignedPath if they do not exist, have expired
+// or the certificate's hosts differ from Hosts.
+func (settings *TLSSettings) Config(log logrus.FieldLogger) (*tls.Config, error) {
+	certFile, keyFile := settings.CertFile, settings.KeyFile
+	if settings.SelfSigned {
+		if err := settings.generateSelfSigned(log); err != nil {
+			return nil, err
+		}
+		certFile, keyFile = settings.selfSignedPath(certFilename), settings.selfSignedPath(keyFilename)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// RootCAs returns a *x509.CertPool that trusts the certificate of the settings, used for clients
+func (settings *TLSSettings) RootCAs() (*x509.CertPool, error) {
+	certFile := settings.CertFile
+	if settings.SelfSigned {
+		certFile = settings.selfSignedPath(caCertFilename)
+	}
+
+	bytes, err := ioutil.ReadFile(filepath.Clean(certFile))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bytes) {
+		return nil, fmt.Errorf("no certificates found in %v", certFile)
+	}
+	return pool, nil
+}
+
+func (settings *TLSSettings) selfSignedPath(filename string) string {
+	return filepath.Join(settings.SelfSignedPath, filename)
+}
+
+func (settings *TLSSettings) generateSelfSigned(log logrus.FieldLogger) error {
+	if err := utils.MkdirAll(settings.SelfSignedPath); err != nil {
+		return err
+	}
+
+	ca, caKey, err := readCertAndKey(settings.selfSignedPath(caCertFilename), settings.selfSignedPath(caKeyFilename))
+	if err != nil {
+		ca, caKey, err = settings.writeCA()
+		if err != nil {
+			return err
+		}
+		log.Warnf("Generated local CA at %v, add it to your trust store to avoid browser warnings", settings.selfSignedPath(caCertFilename))
+	}
+
+	cert, _, err := readCertAndKey(settings.selfSignedPath(certFilename), settings.selfSignedPath(keyFilename))
+	if err == nil && cert.CheckSignatureFrom(ca) == nil && settings.matchesHosts(cert) {
+		return nil
+	}
+	log.Infof("Generating self signed certificate for %v at %v", settings.Hosts, settings.selfSignedPath(certFilename))
+	return settings.writeCert(ca, caKey)
+}
+
+// matchesHosts returns true if the SANs of the certificate are the Hosts, so a changed Hosts regenerates the certificate
+func (settings *TLSSettings) matchesHosts(cert *x509.Certificate) bool {
+	sans := map[string]bool{}
+	for _, name := range cert.DNSNames {
+		sans[name] = true
+	}
+	for _, ip := range cert.IPAddresses {
+		sans[ip.String()] = true
+	}
+
+	hosts := map[string]bool{}
+	for _, host := range settings.Hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			host = ip.String()
+		}
+		if !sans[host] {
+			return false
+		}
+		hosts[host] = true
+	}
+	return len(hosts) == len(sans)
+}
+
+func (settings *TLSSettings) writeCA() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	template, err := certTemplate("gostatic local CA", caValidFor)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	return writeCertAndKey(template, nil, nil, settings.selfSignedPath(caCertFilename), settings.selfSignedPath(caKeyFilename))
+}
+
+func (settings *TLSSettings) writeCert(ca *x509.Certificate, caKey *ecdsa.PrivateKey) error {
+	template, err := certTemplate("gostatic local certificate", certValidFor)
+	if err != nil {
+		return err
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, host := range settings.Hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	_, _, err = writeCertAndKey(template, ca, caKey, settings.selfSignedPath(certFilename), settings.selfSignedPath(keyFilename))
+	return err
+}
+
+func certTemplate(commonName string, validFor time.Duration) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{Organization: []string{"gostatic"}, CommonName: commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validFor),
+	}, nil
+}
+
+// writeCertAndKey creates the certificate from the template, signed by the parent (self signed if parent is nil)
+func writeCertAndKey(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = utils.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})); err != nil {
+		return nil, nil, err
+	}
+	if err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	return cert, key, err
+}
+
+// readCertAndKey reads the certificate and key, giving an error if they're invalid or expired
+func readCertAndKey(certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certBlock, err := readPEM(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, err := readPEM(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().After(cert.NotAfter) {
+		return nil, nil, fmt.Errorf("certificate expired: %v", certPath)
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func readPEM(filePath string) (*pem.Block, error) {
+	bytes, err := ioutil.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %v", filePath)
+	}
+	return block, nil
+}
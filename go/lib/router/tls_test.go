@@ -0,0 +1,139 @@
+package router
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func selfSignedTLSSettings(t *testing.T) (*TLSSettings, func()) {
+	dir, clean := testfile.SandboxDir(t, "tls")
+	settings := DefaultTLSSettings()
+	settings.SelfSigned = true
+	settings.SelfSignedPath = dir
+	return settings, clean
+}
+
+func TestTLSSettings_Enabled(t *testing.T) {
+	testCases := []struct {
+		settings *TLSSettings
+		exp      bool
+		scheme   string
+	}{
+		{nil, false, "http"},
+		{DefaultTLSSettings(), false, "http"},
+		{&TLSSettings{SelfSigned: true}, true, "https"},
+		{&TLSSettings{CertFile: "cert.pem", KeyFile: "key.pem"}, true, "https"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"settings": tc.settings,
+		})
+		context.Assert("Enabled", tc.settings.Enabled(), tc.exp)
+		context.Assert("Scheme", tc.settings.Scheme(), tc.scheme)
+	}
+}
+
+func TestTLSSettings_Config_SelfSigned(t *testing.T) {
+	settings, clean := selfSignedTLSSettings(t)
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	config, err := settings.Config(log)
+	test.AssertError(t, err, "settings.Config")
+	test.AssertArray(t, "NextProtos", config.NextProtos, []string{"h2", "http/1.1"})
+
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	test.AssertError(t, err, "x509.ParseCertificate")
+	rootCAs, err := settings.RootCAs()
+	test.AssertError(t, err, "settings.RootCAs")
+	for _, host := range settings.Hosts {
+		_, err = cert.Verify(x509.VerifyOptions{DNSName: host, Roots: rootCAs})
+		test.AssertError(t, err, "cert.Verify for "+host)
+	}
+
+	certBytes, err := ioutil.ReadFile(filepath.Join(settings.SelfSignedPath, certFilename))
+	test.AssertError(t, err, "ioutil.ReadFile")
+	_, err = settings.Config(log)
+	test.AssertError(t, err, "settings.Config again")
+	reusedBytes, err := ioutil.ReadFile(filepath.Join(settings.SelfSignedPath, certFilename))
+	test.AssertError(t, err, "ioutil.ReadFile again")
+	test.AssertLabel(t, "reused certificate", string(reusedBytes), string(certBytes))
+
+	settings.Hosts = []string{"example.test", "127.0.0.1"}
+	config, err = settings.Config(log)
+	test.AssertError(t, err, "settings.Config with new Hosts")
+	cert, err = x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	test.AssertError(t, err, "x509.ParseCertificate with new Hosts")
+	test.AssertArray(t, "DNSNames", cert.DNSNames, []string{"example.test"})
+	test.AssertLabel(t, "IPAddresses", len(cert.IPAddresses), 1)
+	test.AssertLabel(t, "IPAddresses[0]", cert.IPAddresses[0].String(), "127.0.0.1")
+}
+
+func TestTLSSettings_Config_CertFile(t *testing.T) {
+	selfSigned, clean := selfSignedTLSSettings(t)
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	_, err := selfSigned.Config(log)
+	test.AssertError(t, err, "selfSigned.Config")
+
+	settings := &TLSSettings{
+		CertFile: filepath.Join(selfSigned.SelfSignedPath, certFilename),
+		KeyFile:  filepath.Join(selfSigned.SelfSignedPath, keyFilename),
+	}
+	config, err := settings.Config(log)
+	test.AssertError(t, err, "settings.Config")
+	test.AssertLabel(t, "len(Certificates)", len(config.Certificates), 1)
+
+	settings.KeyFile = "does_not_exist.pem"
+	_, err = settings.Config(log)
+	if err == nil {
+		t.Error("expected error for missing key file")
+	}
+}
+
+func TestWebRouter_Requester_TLS(t *testing.T) {
+	tlsSettings, clean := selfSignedTLSSettings(t)
+	defer clean()
+	log, _ := logTest.NewNullLogger()
+
+	settings := DefaultServerSettings()
+	settings.TLS = tlsSettings
+	router := NewWebRouter(8080, settings, log)
+	router.GetRootHTML(func(ctx Context) error {
+		ctx.Respond([]byte("secure"))
+		return nil
+	})
+
+	config, err := tlsSettings.Config(log)
+	test.AssertError(t, err, "tlsSettings.Config")
+	server := httptest.NewUnstartedServer(router.serveMux)
+	server.TLS = config
+	server.StartTLS()
+	defer server.Close()
+
+	urlObject, err := url.Parse(server.URL)
+	test.AssertError(t, err, "url.Parse")
+	port, err := strconv.Atoi(urlObject.Port())
+	test.AssertError(t, err, "strconv.Atoi")
+
+	requester := router.webRequester()
+	requester.port = port
+
+	response, err := requester.Get(RootURL)
+	test.AssertError(t, err, "requester.Get")
+	test.AssertLabel(t, "Response.Body", string(response.Body), "secure")
+	test.AssertLabel(t, "scheme", requester.scheme, "https")
+}
@@ -1,11 +1,15 @@
 package router
 
 import (
+	gocontext "context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"mime"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
@@ -18,8 +22,8 @@ import (
 
 type webHandler func(w http.ResponseWriter, r *http.Request) error
 
-// WebRouter is the router to host a web application server. It's simplified such that all errors
-// give http.StatusBadRequest and print out the errors. It's also can't handle two routes like this:
+// WebRouter is the router to host a web application server. It's simplified such that all handler errors
+// give http.StatusBadRequest and print out the errors, while undefined routes give http.StatusNotFound. It's also can't handle two routes like this:
 // `/folder` returning HTML and `/folder/something.png` because gostatic is made to generate static websites
 // so `/folder` would be a folder and can't return HTML.
 //
@@ -36,27 +40,30 @@ type WebRouter struct {
 	folders map[string]bool
 
 	rootHandler http.HandlerFunc
-	port        int
+	server      *server
 }
 
 // NewWebRouter returns a new instance of WebRouter
-func NewWebRouter(port int, log logrus.FieldLogger) *WebRouter {
+func NewWebRouter(port int, settings *ServerSettings, log logrus.FieldLogger) *WebRouter {
 	defaultHandler := func(w http.ResponseWriter, r *http.Request) {
-		s := fmt.Sprintf("%v is not being handled", r.URL)
-		log.Errorf(s)
-		http.Error(w, s, http.StatusBadRequest)
+		notFound(w, r, log)
 	}
 
+	serveMux := http.NewServeMux()
 	router := &WebRouter{
-		http.NewServeMux(),
+		serveMux,
 		log,
 		nil,
 		make(map[string]bool),
 		make(map[string]bool),
 		defaultHandler,
-		port,
+		newServer(port, settings, serveMux, log),
 	}
 	router.serveMux.HandleFunc(RootURL, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != RootURL {
+			defaultHandler(w, r)
+			return
+		}
 		router.rootHandler(w, r)
 	})
 	return router
@@ -70,7 +77,7 @@ func (router *WebRouter) Around(handler AroundHandler) {
 // GetRootHTML defines a HTML handler for the root URL `/`
 func (router *WebRouter) GetRootHTML(handler ContextHandler) {
 	router.checkAndSetRoutes(RootURL)
-	router.rootHandler = router.getRequestHandler(router.htmlHandler(handler))
+	router.get(RootURL, router.htmlHandler(handler))
 }
 
 // GetHTML defines a HTML handler given a URL (shorthand for Get with Content-Type set for .html files)
@@ -82,14 +89,14 @@ func (router *WebRouter) GetHTML(url string, handler ContextHandler) {
 	}
 
 	router.checkAndSetRoutes(url)
-	router.get(url, router.htmlHandler(handler))
+	router.get(url, router.exactHandler(url, router.htmlHandler(handler)))
 }
 
 // Get define a handler for any file type given a URL
 func (router *WebRouter) Get(url string, handler ContextHandler) {
 	url = handleURLSlash(url)
 	router.checkAndSetRoutes(url)
-	router.get(url, router.handler(mime.TypeByExtension(path.Ext(url)), handler))
+	router.get(url, router.exactHandler(url, router.handler(urlContentType(url), handler)))
 }
 
 func (router *WebRouter) hasRoute(url string) bool {
@@ -116,20 +123,44 @@ func (router *WebRouter) URLs() []string {
 	return staticRoutes
 }
 
-// Requester returns a requester for the given router, to make requests and return the response
+// Requester returns a requester for the given router, to make requests and return the response.
+// It makes HTTP requests to the running server of the router, see MuxRequester for in-process requests.
 func (router *WebRouter) Requester() Requester {
-	return newWebRequester(router.port)
+	return router.webRequester()
+}
+
+// MuxRequester returns a requester that dispatches the requests in-process, so the server does not need to be running
+func (router *WebRouter) MuxRequester() *MuxRequester {
+	return &MuxRequester{router}
+}
+
+func (router *WebRouter) webRequester() *WebRequester {
+	requester := newWebRequester(router.server.hostPort())
+	requester.basePath = router.server.basePath
+	tlsSettings := router.server.settings.TLS
+	if !tlsSettings.Enabled() {
+		return requester
+	}
+
+	rootCAs, err := tlsSettings.RootCAs()
+	if err != nil {
+		router.log.Errorf("Requester can not trust the TLS certificate - %v", err)
+		return requester
+	}
+	requester.scheme = tlsSettings.Scheme()
+	requester.client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}}
+	return requester
 }
 
 func (router *WebRouter) htmlHandler(handler ContextHandler) webHandler {
-	return router.handler(mime.TypeByExtension(".html"), handler)
+	return router.handler(htmlContentType(), handler)
 }
 
 func (router *WebRouter) handler(contentType string, handler ContextHandler) webHandler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		ctx := newContext(router.log)
 		ctx.contentType = contentType
-		ctx.url = r.URL.String()
+		ctx.url = r.URL.Path
 
 		err := callArounds(router.arounds, handler, ctx)
 		if err != nil {
@@ -142,6 +173,26 @@ func (router *WebRouter) handler(contentType string, handler ContextHandler) web
 	}
 }
 
+// exactHandler only handles the url, as the http.ServeMux patterns ending in "/" match the URLs under them too
+func (router *WebRouter) exactHandler(url string, handler webHandler) webHandler {
+	if !strings.HasSuffix(url, "/") {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path != url {
+			notFound(w, r, router.log)
+			return nil
+		}
+		return handler(w, r)
+	}
+}
+
+func notFound(w http.ResponseWriter, r *http.Request, log logrus.FieldLogger) {
+	err := errURLNotFound(r.URL.String())
+	log.Error(err)
+	http.Error(w, err.Error(), http.StatusNotFound)
+}
+
 func (router *WebRouter) getRequestHandler(handler webHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "GET" {
@@ -158,6 +209,11 @@ var dangerPathRegex = regexp.MustCompile(`/\.\./`)
 // FileServe sets the router to redirect requests with a pattern to a file directory.
 // Content-Type is respected via calling mime.TypeByExtension (Go std lib).
 func (router *WebRouter) FileServe(pattern, dirPath string) {
+	if handleURLSlash(pattern) == RootURL {
+		router.log.Errorf("FileServe can not serve the root URL, use a prefix instead")
+		return
+	}
+
 	router.get(pattern, func(w http.ResponseWriter, r *http.Request) error {
 		url := r.URL.String()
 		if dangerPathRegex.MatchString(url) {
@@ -180,30 +236,96 @@ func (router *WebRouter) FileServe(pattern, dirPath string) {
 
 func (router *WebRouter) get(url string, handler webHandler) {
 	if url == RootURL {
-		router.log.Errorf("Can not use url that touches root, use GetRootHTML instead")
+		router.rootHandler = router.getRequestHandler(handler)
 		return
 	}
-
 	router.serveMux.HandleFunc(url, router.getRequestHandler(handler))
 }
 
-// Run starts the web server for this router
+// SetBasePath serves the router under the basePath (ex. "/docs/"), as when the site is published under a subpath.
+// The basePath is stripped from the request URLs, so the routes and FileServe patterns are defined without it.
+// Call it before Run.
+func (router *WebRouter) SetBasePath(basePath string) {
+	router.server.setBasePath(basePath)
+}
+
+// ServeMetrics records the metrics of all routes via an Around handler, and serves them at the url
+// in the Prometheus text format. Call it before the other Around handlers, so it wraps them.
+func (router *WebRouter) ServeMetrics(url string) *Metrics {
+	metrics := NewMetrics()
+	metrics.skippedURLs[url] = true
+	router.Around(metrics.Around)
+	router.Get(url, metrics.Handler())
+	return metrics
+}
+
+// Listen binds the address of the server, so requests can be made before running it.
+// When the port is 0, a port is chosen and given by Addr.
+func (router *WebRouter) Listen() error {
+	return router.server.listen()
+}
+
+// Addr returns the address of the server, which is the chosen address after Listen when the port is 0
+func (router *WebRouter) Addr() string {
+	return router.server.addr()
+}
+
+// Run starts the web server for this router, until SIGINT or SIGTERM is received
 func (router *WebRouter) Run() error {
-	router.log.Infof("Running server at http://localhost:%v/", router.port)
-	server := &http.Server{Addr: ":" + strconv.Itoa(router.port), Handler: router.serveMux}
-	return server.ListenAndServe()
+	ctx, cancel := signalContext()
+	defer cancel()
+	return router.RunContext(ctx)
+}
+
+// RunContext starts the web server for this router, until ctx is done.
+// Then, the server shuts down gracefully, waiting for in-flight requests within ServerSettings.ShutdownTimeout.
+func (router *WebRouter) RunContext(ctx gocontext.Context) error {
+	if err := router.Listen(); err != nil {
+		return err
+	}
+	router.log.Infof("Running server at %v", router.server.url())
+	return router.server.serve(ctx)
+}
+
+// MuxRequester makes requests on the WebRouter in-process, by dispatching through the router's http.ServeMux
+// and recording the response, so it gives the same responses as the GenerateRequester.
+type MuxRequester struct {
+	router *WebRouter
+}
+
+// Get gets the response of the route's handler given the url
+func (requester *MuxRequester) Get(url string) (*Response, error) {
+	url = handleURLSlash(url)
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	recorder := httptest.NewRecorder()
+	requester.router.serveMux.ServeHTTP(recorder, request)
+
+	if recorder.Code < 200 || recorder.Code >= 300 {
+		return nil, fmt.Errorf("%v", strings.TrimSpace(recorder.Body.String()))
+	}
+	return NewResponse(recorder.Body.Bytes(), recorder.Header().Get("Content-Type")), nil
 }
 
-// WebRequester makes requests on the WebRouter
+// WebRequester makes HTTP requests on the WebRouter's running server
 type WebRequester struct {
+	scheme   string
 	hostname string
 	port     int
+	basePath string
+	client   *http.Client
 }
 
-func newWebRequester(port int) *WebRequester {
+func newWebRequester(hostname string, port int) *WebRequester {
 	return &WebRequester{
-		"localhost",
+		"http",
+		hostname,
 		port,
+		"",
+		http.DefaultClient,
 	}
 }
 
@@ -211,7 +333,8 @@ func newWebRequester(port int) *WebRequester {
 func (requester *WebRequester) Get(url string) (resp *Response, err error) {
 	url = handleURLSlash(url)
 
-	response, err := http.Get(fmt.Sprintf("http://%v:%v%v", requester.hostname, requester.port, url))
+	hostPort := net.JoinHostPort(requester.hostname, strconv.Itoa(requester.port))
+	response, err := requester.client.Get(fmt.Sprintf("%v://%v%v%v", requester.scheme, hostPort, requester.basePath, url))
 	if err != nil {
 		return nil, err
 	}
@@ -228,7 +351,7 @@ func (requester *WebRequester) Get(url string) (resp *Response, err error) {
 	}()
 
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		return nil, fmt.Errorf(strings.TrimSpace(string(body)))
+		return nil, fmt.Errorf("%v", strings.TrimSpace(string(body)))
 	}
 	return NewResponse(body, response.Header.Get("Content-Type")), nil
 }
@@ -20,7 +20,7 @@ import (
 
 func defaultWebRouter() (*WebRouter, logrus.FieldLogger, *logTest.Hook) {
 	log, hook := logTest.NewNullLogger()
-	return NewWebRouter(8080, log), log, hook
+	return NewWebRouter(8080, DefaultServerSettings(), log), log, hook
 }
 
 type WebRouterSetup struct {
@@ -31,10 +31,6 @@ func NewWebRouterSetup() *WebRouterSetup {
 	return &WebRouterSetup{}
 }
 
-func (setup *WebRouterSetup) DefaultRouter() (Router, logrus.FieldLogger, *logTest.Hook) {
-	return defaultWebRouter()
-}
-
 func (setup *WebRouterSetup) RunServer(router Router, callback func()) {
 	r, ok := router.(*WebRouter)
 	if !ok {
@@ -59,9 +55,7 @@ func (setup *WebRouterSetup) Requester(router Router) Requester {
 		panic(err)
 	}
 
-	requester := newWebRequester(int(port))
-	requester.hostname = urlObject.Hostname()
-	return requester
+	return newWebRequester(urlObject.Hostname(), int(port))
 }
 
 func TestWebRouter_FileServe(t *testing.T) {
@@ -0,0 +1,120 @@
+/*
+Package urls applies the base path, base URL and asset host of the site to URLs,
+so a site can be published under a subpath of a larger domain and serve its assets from a CDN.
+
+URLs struct implements github.com/s12chung/gostatic/go/lib/html.Plugin
+*/
+package urls
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// Settings is the settings of this package
+type Settings struct {
+	// BaseURL is the scheme and host of the site (ex. "https://example.com"), used by AbsURL
+	BaseURL string `json:"base_url,omitempty" env:"BASE_URL"`
+	// BasePath is the path the site is published under (ex. "/docs/"), empty for the root
+	BasePath string `json:"base_path,omitempty" env:"BASE_PATH"`
+	// AssetHost is the scheme and host the assets are served from (ex. "https://cdn.example.com"), empty for the site
+	AssetHost string `json:"asset_host,omitempty" env:"ASSET_HOST"`
+}
+
+// DefaultSettings returns the default settings of this package
+func DefaultSettings() *Settings {
+	return &Settings{}
+}
+
+// Validate returns an error if the settings are invalid
+func (s *Settings) Validate() error {
+	if err := validateHost("base_url", s.BaseURL); err != nil {
+		return err
+	}
+	if err := validateHost("asset_host", s.AssetHost); err != nil {
+		return err
+	}
+	if s.BasePath != "" && !strings.HasPrefix(s.BasePath, "/") {
+		return fmt.Errorf("base_path must start with /: %v", s.BasePath)
+	}
+	return nil
+}
+
+func validateHost(name, host string) error {
+	if host == "" {
+		return nil
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("%v is invalid - %v", name, err)
+	}
+	if u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return fmt.Errorf("%v must be a scheme and host without a path (ex. https://example.com): %v", name, host)
+	}
+	return nil
+}
+
+// URLs applies the Settings to URLs
+type URLs struct {
+	settings *Settings
+}
+
+// NewURLs returns a new instance of URLs, nil settings are empty settings
+func NewURLs(settings *Settings) *URLs {
+	if settings == nil {
+		settings = &Settings{}
+	}
+	return &URLs{settings}
+}
+
+// BasePath returns Settings.BasePath without the trailing slash, empty for the root
+func (u *URLs) BasePath() string {
+	return strings.TrimSuffix(u.settings.BasePath, "/")
+}
+
+// RelURL returns the url under Settings.BasePath, leaving external URLs (and URLs already under it) unchanged.
+// Relative urls are treated as relative to the root, so they become root-relative if there is a BasePath.
+func (u *URLs) RelURL(rawURL string) string {
+	basePath := u.BasePath()
+	if basePath == "" || isExternal(rawURL) || rawURL == basePath || strings.HasPrefix(rawURL, basePath+"/") {
+		return rawURL
+	}
+	return basePath + "/" + strings.TrimPrefix(rawURL, "/")
+}
+
+// AbsURL returns the RelURL prefixed with Settings.BaseURL, leaving external URLs unchanged
+func (u *URLs) AbsURL(rawURL string) string {
+	return withHost(u.settings.BaseURL, u.RelURL(rawURL), rawURL)
+}
+
+// AssetURL returns the RelURL prefixed with Settings.AssetHost, leaving external URLs unchanged
+func (u *URLs) AssetURL(rawURL string) string {
+	return withHost(u.settings.AssetHost, u.RelURL(rawURL), rawURL)
+}
+
+func withHost(host, relURL, rawURL string) string {
+	if host == "" || isExternal(rawURL) {
+		return relURL
+	}
+	return strings.TrimSuffix(host, "/") + "/" + strings.TrimPrefix(relURL, "/")
+}
+
+// isExternal returns true if the URL has a scheme or host (ex. "https://a.com/b", "//a.com/b", "data:..."),
+// or is only a fragment or query, which are relative to the current page
+func isExternal(rawURL string) bool {
+	if strings.HasPrefix(rawURL, "#") || strings.HasPrefix(rawURL, "?") {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	return err != nil || u.Scheme != "" || u.Host != ""
+}
+
+// TemplateFuncs implements github.com/s12chung/gostatic/go/lib/html.Plugin
+func (u *URLs) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"absURL": u.AbsURL,
+		"relURL": u.RelURL,
+	}
+}
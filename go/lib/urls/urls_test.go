@@ -0,0 +1,97 @@
+package urls
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestDefaultSettings(t *testing.T) {
+	test.AssertError(t, os.Setenv("BASE_PATH", "/env/"), "os.Setenv")
+	defer func() {
+		test.AssertError(t, os.Unsetenv("BASE_PATH"), "os.Unsetenv")
+	}()
+	// the environment is read by app.LoadSettings
+	test.AssertLabel(t, "DefaultSettings", *DefaultSettings(), Settings{})
+}
+
+func TestSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		settings *Settings
+		err      bool
+	}{
+		{&Settings{}, false},
+		{&Settings{"https://example.com", "/docs/", "https://cdn.example.com/"}, false},
+		{&Settings{"", "/docs", "//cdn.example.com"}, false},
+		{&Settings{"example.com", "", ""}, true},
+		{&Settings{"https://example.com/docs", "", ""}, true},
+		{&Settings{"", "", "cdn.example.com"}, true},
+		{&Settings{"", "docs/", ""}, true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"settings": *tc.settings,
+		})
+		context.Assert("err != nil", tc.settings.Validate() != nil, tc.err)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	testCases := []struct {
+		settings *Settings
+		url      string
+		rel      string
+		abs      string
+		asset    string
+	}{
+		{nil, "/about.html", "/about.html", "/about.html", "/about.html"},
+		{&Settings{}, "assets/a.js", "assets/a.js", "assets/a.js", "assets/a.js"},
+		{&Settings{"https://example.com/", "", "https://cdn.example.com"}, "/about.html",
+			"/about.html", "https://example.com/about.html", "https://cdn.example.com/about.html"},
+		{&Settings{"https://example.com", "/docs/", "https://cdn.example.com"}, "/about.html",
+			"/docs/about.html", "https://example.com/docs/about.html", "https://cdn.example.com/docs/about.html"},
+		{&Settings{"https://example.com", "/docs", ""}, "assets/a.js",
+			"/docs/assets/a.js", "https://example.com/docs/assets/a.js", "/docs/assets/a.js"},
+		{&Settings{"https://example.com", "/docs/", ""}, "/",
+			"/docs/", "https://example.com/docs/", "/docs/"},
+		{&Settings{"https://example.com", "/docs/", ""}, "/docs/about.html",
+			"/docs/about.html", "https://example.com/docs/about.html", "/docs/about.html"},
+		{&Settings{"https://example.com", "/docs/", ""}, "/docsy.html",
+			"/docs/docsy.html", "https://example.com/docs/docsy.html", "/docs/docsy.html"},
+		{&Settings{"https://example.com", "/docs/", "https://cdn.example.com"}, "https://other.com/a.js",
+			"https://other.com/a.js", "https://other.com/a.js", "https://other.com/a.js"},
+		{&Settings{"https://example.com", "/docs/", "https://cdn.example.com"}, "//other.com/a.js",
+			"//other.com/a.js", "//other.com/a.js", "//other.com/a.js"},
+		{&Settings{"https://example.com", "/docs/", ""}, "#top", "#top", "#top", "#top"},
+		{&Settings{"https://example.com", "/docs/", ""}, "data:image/gif;base64,R0lG",
+			"data:image/gif;base64,R0lG", "data:image/gif;base64,R0lG", "data:image/gif;base64,R0lG"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"settings": tc.settings,
+			"url":      tc.url,
+		})
+
+		u := NewURLs(tc.settings)
+		context.Assert("RelURL", u.RelURL(tc.url), tc.rel)
+		context.Assert("AbsURL", u.AbsURL(tc.url), tc.abs)
+		context.Assert("AssetURL", u.AssetURL(tc.url), tc.asset)
+	}
+}
+
+func TestURLs_TemplateFuncs(t *testing.T) {
+	u := NewURLs(&Settings{"https://example.com", "/docs/", ""})
+	tmpl, err := template.New("test").Funcs(u.TemplateFuncs()).Parse(`<a href="{{ relURL "/a.html" }}">{{ absURL "/a.html" }}</a>`)
+	test.AssertError(t, err, "template.Parse")
+
+	buffer := &bytes.Buffer{}
+	test.AssertError(t, tmpl.Execute(buffer, nil), "tmpl.Execute")
+	test.AssertLabel(t, "result", buffer.String(), `<a href="/docs/a.html">https://example.com/docs/a.html</a>`)
+}
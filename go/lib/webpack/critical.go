@@ -0,0 +1,133 @@
+package webpack
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/s12chung/gostatic/go/lib/filecache"
+	"github.com/s12chung/gostatic/go/lib/router"
+)
+
+// preloadSwap is the onload attr that applies the preloaded stylesheet
+const preloadSwap = "this.onload=null;this.rel='stylesheet'"
+
+// newStylesheetCache returns the cache of the parsed rules of the stylesheets, until the file's modtime changes
+func newStylesheetCache() *filecache.Files {
+	return filecache.NewFiles(func(bytes []byte) (interface{}, error) {
+		return parseCSS(string(bytes)), nil
+	})
+}
+
+// CriticalCSSAround is a router.AroundHandler that calls InlineCriticalCSS on the HTML responses, if Settings.CriticalCSS
+// and the context is a router.ResponseContext.
+// Add it with app.App.RouteAround, so it's only when generating, not when hosting.
+func (w *Webpack) CriticalCSSAround(ctx router.Context, handler router.ContextHandler) error {
+	if err := handler(ctx); err != nil {
+		return err
+	}
+	responseCtx, isResponseCtx := ctx.(router.ResponseContext)
+	if !w.settings.CriticalCSS || !isResponseCtx || !strings.HasPrefix(ctx.ContentType(), "text/html") {
+		return nil
+	}
+
+	inlined, err := w.InlineCriticalCSS(string(responseCtx.Response()))
+	if err != nil {
+		return err
+	}
+	ctx.Respond([]byte(inlined))
+	return nil
+}
+
+// InlineCriticalCSS replaces the <link rel="stylesheet"> tags of the manifest stylesheets with a <style> of the rules
+// that may apply to the page, the critical CSS. The stylesheet is deferred with a <link rel="preload"> that applies it on load,
+// along with the original <link> in a <noscript>.
+//
+// A rule may apply if the type, id and class selectors of one of its selectors are in the page. @font-face rules are kept,
+// along with the @keyframes of the animations used by the rules that may apply.
+// Stylesheets that can't be read give errors if Settings.Strict, otherwise they're logged and left as is.
+func (w *Webpack) InlineCriticalCSS(htmlString string) (string, error) {
+	page := newPageSelectors(htmlString)
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlString))
+	builder := &strings.Builder{}
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		// copy Raw, as it is lowercased in place by Token
+		raw := string(tokenizer.Raw())
+
+		if tokenType == html.StartTagToken || tokenType == html.SelfClosingTagToken {
+			token := tokenizer.Token()
+			deferred, err := w.deferStylesheet(&token, raw, page)
+			if err != nil {
+				return "", err
+			}
+			raw = deferred
+		}
+		builder.WriteString(raw)
+	}
+	return builder.String(), nil
+}
+
+// deferStylesheet returns the critical <style> and deferred stylesheet of the token if it's a manifest stylesheet,
+// otherwise the raw token
+func (w *Webpack) deferStylesheet(token *html.Token, raw string, page *pageSelectors) (string, error) {
+	if token.Data != "link" || !strings.EqualFold(attrValue(token, "rel"), "stylesheet") {
+		return raw, nil
+	}
+	filePath, err := w.stylesheetPath(attrValue(token, "href"))
+	if filePath == "" {
+		return raw, err
+	}
+
+	rules, err := w.stylesheets.Get(filePath)
+	if err != nil {
+		return raw, w.strictError(fmt.Errorf("error reading stylesheet for critical CSS - %v", err))
+	}
+	critical := page.criticalCSS(rules.([]*cssRule))
+	if critical == "" {
+		return raw, nil
+	}
+
+	preload := *token
+	preload.Attr = []html.Attribute{{Key: "rel", Val: "preload"}, {Key: "as", Val: "style"}}
+	for _, attr := range token.Attr {
+		if attr.Key != "rel" && attr.Key != "as" && attr.Key != "onload" {
+			preload.Attr = append(preload.Attr, attr)
+		}
+	}
+	preload.Attr = append(preload.Attr, html.Attribute{Key: "onload", Val: preloadSwap})
+
+	style := "<style>" + strings.Replace(critical, "</style", `<\/style`, -1) + "</style>"
+	return style + preload.String() + "<noscript>" + raw + "</noscript>", nil
+}
+
+// stylesheetPath returns the generated file path of the stylesheet href, empty if it's not a manifest stylesheet
+func (w *Webpack) stylesheetPath(href string) (string, error) {
+	if href == "" {
+		return "", nil
+	}
+	manifestMap, err := w.manifest.getManifestMap()
+	if err != nil {
+		return "", w.strictError(fmt.Errorf("error reading webpack manifest - %v", err))
+	}
+	for _, value := range manifestMap {
+		if strings.HasSuffix(value, ".css") && w.manifest.assetURL(value) == href {
+			return filepath.Join(w.GeneratedAssetsPath(), filepath.FromSlash(value)), nil
+		}
+	}
+	return "", nil
+}
+
+func attrValue(token *html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
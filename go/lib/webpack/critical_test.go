@@ -0,0 +1,115 @@
+package webpack
+
+import (
+	"testing"
+
+	"github.com/s12chung/gostatic/go/lib/router"
+	"github.com/s12chung/gostatic/go/test"
+)
+
+const criticalStylesheet = `<link rel="stylesheet" href="assets/critical-4e5f6a7b8c9d0e1f2a3b.css" media="all">`
+const criticalHTML = `<html><head>` + criticalStylesheet + `</head>` +
+	`<body><div class="header"></div><div id="main"><p class="lead">x</p></div></body></html>`
+
+const criticalCSS = `html,body{margin: 0;}.header{color: red;}#main > p.lead:first-child::before{content: "}";}` +
+	`@media (min-width: 600px){.header{font-size: 2em;}}@font-face{font-family: "Inter"; src: url(fonts/inter.woff2);}`
+const criticalDeferred = `<style>` + criticalCSS + `</style>` +
+	`<link rel="preload" as="style" href="assets/critical-4e5f6a7b8c9d0e1f2a3b.css" media="all" onload="this.onload=null;this.rel=&#39;stylesheet&#39;">` +
+	`<noscript>` + criticalStylesheet + `</noscript>`
+
+func TestWebpack_InlineCriticalCSS(t *testing.T) {
+	vendorStylesheet := `<link rel="stylesheet" href="assets/vendor-32267303b2484ed8b3aa.css">`
+	testCases := []struct {
+		html string
+		exp  string
+	}{
+		{criticalHTML, `<html><head>` + criticalDeferred + `</head>` +
+			`<body><div class="header"></div><div id="main"><p class="lead">x</p></div></body></html>`},
+		{`<head>` + criticalStylesheet + `</head><body><span>x</span></body>`,
+			`<head><style>body{margin: 0;}@font-face{font-family: "Inter"; src: url(fonts/inter.woff2);}</style>` +
+				`<link rel="preload" as="style" href="assets/critical-4e5f6a7b8c9d0e1f2a3b.css" media="all" onload="this.onload=null;this.rel=&#39;stylesheet&#39;">` +
+				`<noscript>` + criticalStylesheet + `</noscript></head><body><span>x</span></body>`},
+		{`<head><link rel="stylesheet" href="https://example.com/style.css"></head>`, `<head><link rel="stylesheet" href="https://example.com/style.css"></head>`},
+		{`<head><link rel="preload" href="assets/critical-4e5f6a7b8c9d0e1f2a3b.css"></head>`, `<head><link rel="preload" href="assets/critical-4e5f6a7b8c9d0e1f2a3b.css"></head>`},
+		{`<head>` + vendorStylesheet + `</head><body><p>x</p></body>`, `<head>` + vendorStylesheet + `</head><body><p>x</p></body>`},
+		{`<p>no stylesheet</p>`, `<p>no stylesheet</p>`},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"html":  tc.html,
+		})
+
+		webpack, hook := defaultWebpack()
+		got, err := webpack.InlineCriticalCSS(tc.html)
+		context.AssertError(err, "webpack.InlineCriticalCSS")
+		context.Assert("result", got, tc.exp)
+		context.Assert("test.SafeLogEntries(hook)", test.SafeLogEntries(hook), true)
+	}
+}
+
+func TestWebpack_CriticalCSSAround(t *testing.T) {
+	testCases := []struct {
+		criticalCSS bool
+		url         string
+		exp         string
+	}{
+		{true, "/", `<html><head>` + criticalDeferred + `</head>` +
+			`<body><div class="header"></div><div id="main"><p class="lead">x</p></div></body></html>`},
+		{false, "/", criticalHTML},
+		{true, "/style.css", criticalHTML},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":       testCaseIndex,
+			"criticalCSS": tc.criticalCSS,
+			"url":         tc.url,
+		})
+
+		webpack, hook := defaultWebpack()
+		webpack.settings.CriticalCSS = tc.criticalCSS
+
+		r := router.NewGenerateRouter(webpack.log)
+		r.Around(webpack.CriticalCSSAround)
+		r.GetRootHTML(func(ctx router.Context) error {
+			ctx.Respond([]byte(criticalHTML))
+			return nil
+		})
+		r.Get("/style.css", func(ctx router.Context) error {
+			ctx.SetContentType("text/css")
+			ctx.Respond([]byte(criticalHTML))
+			return nil
+		})
+
+		response, err := r.Requester().Get(tc.url)
+		context.AssertError(err, "Requester.Get")
+		context.Assert("result", string(response.Body), tc.exp)
+		context.Assert("test.SafeLogEntries(hook)", test.SafeLogEntries(hook), true)
+	}
+}
+
+// contextOnly hides the Response of the router.ResponseContext, like a Context implemented outside of the router
+type contextOnly struct {
+	router.Context
+}
+
+func TestWebpack_CriticalCSSAround_ContextOnly(t *testing.T) {
+	webpack, hook := defaultWebpack()
+	webpack.settings.CriticalCSS = true
+
+	r := router.NewGenerateRouter(webpack.log)
+	r.Around(func(ctx router.Context, handler router.ContextHandler) error {
+		return webpack.CriticalCSSAround(contextOnly{ctx}, handler)
+	})
+	r.GetRootHTML(func(ctx router.Context) error {
+		ctx.Respond([]byte(criticalHTML))
+		return nil
+	})
+
+	response, err := r.Requester().Get(router.RootURL)
+	test.AssertError(t, err, "Requester.Get")
+	test.AssertLabel(t, "result", string(response.Body), criticalHTML)
+	test.AssertLabel(t, "test.SafeLogEntries(hook)", test.SafeLogEntries(hook), true)
+}
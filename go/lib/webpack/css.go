@@ -0,0 +1,403 @@
+package webpack
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// groupingAtRules are the at-rules with blocks of rules, instead of declarations
+var groupingAtRules = []string{"@media", "@supports", "@layer", "@container", "@document"}
+
+// cssRule is a rule of a stylesheet, a grouping at-rule has rules instead of a block of declarations
+type cssRule struct {
+	prelude  string
+	block    string
+	grouping bool
+	rules    []*cssRule
+}
+
+// parseCSS returns the rules of the stylesheet, without the comments and statement at-rules (ex. @import, @charset)
+func parseCSS(css string) []*cssRule {
+	parser := &cssParser{stripCSSComments(css), 0}
+	var rules []*cssRule
+	for parser.i < len(parser.src) {
+		rules = append(rules, parser.parseRules()...)
+		// skip the unmatched '}'
+		parser.i++
+	}
+	return rules
+}
+
+type cssParser struct {
+	src string
+	i   int
+}
+
+// parseRules parses the rules until the end of the src or the '}' of the current block, which is not consumed
+func (p *cssParser) parseRules() []*cssRule {
+	var rules []*cssRule
+	for {
+		p.i += len(p.src[p.i:]) - len(strings.TrimLeft(p.src[p.i:], " \t\r\n\f"))
+		if p.i >= len(p.src) || p.src[p.i] == '}' {
+			return rules
+		}
+
+		end := p.indexAny("{;}")
+		if end == -1 {
+			p.i = len(p.src)
+			return rules
+		}
+		prelude := strings.TrimSpace(p.src[p.i:end])
+		p.i = end + 1
+		switch p.src[end] {
+		case ';':
+			continue
+		case '}':
+			p.i = end
+			return rules
+		}
+
+		rule := &cssRule{prelude: prelude, grouping: isGroupingAtRule(prelude)}
+		if rule.grouping {
+			rule.rules = p.parseRules()
+		} else {
+			blockStart := p.i
+			p.i = p.blockEnd()
+			rule.block = strings.TrimSpace(p.src[blockStart:p.i])
+		}
+		p.i++
+		rules = append(rules, rule)
+	}
+}
+
+// indexAny returns the index of the first of the chars from i, outside of strings
+func (p *cssParser) indexAny(chars string) int {
+	for i := p.i; i < len(p.src); i++ {
+		c := p.src[i]
+		if c == '"' || c == '\'' {
+			i = stringEnd(p.src, i)
+		} else if strings.IndexByte(chars, c) != -1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// blockEnd returns the index of the '}' closing the block starting at i, outside of strings and nested blocks
+func (p *cssParser) blockEnd() int {
+	depth := 0
+	for i := p.i; i < len(p.src); i++ {
+		switch c := p.src[i]; {
+		case c == '"' || c == '\'':
+			i = stringEnd(p.src, i)
+		case c == '{':
+			depth++
+		case c == '}' && depth == 0:
+			return i
+		case c == '}':
+			depth--
+		}
+	}
+	return len(p.src)
+}
+
+// stringEnd returns the index of the quote closing the string starting at i
+func stringEnd(s string, i int) int {
+	quote := s[i]
+	for i++; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == quote {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func stripCSSComments(css string) string {
+	builder := &strings.Builder{}
+	for i := 0; i < len(css); i++ {
+		switch {
+		case css[i] == '"' || css[i] == '\'':
+			end := stringEnd(css, i)
+			if end >= len(css) {
+				end = len(css) - 1
+			}
+			builder.WriteString(css[i : end+1])
+			i = end
+		case strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end == -1 {
+				return builder.String()
+			}
+			i += end + 3
+		default:
+			builder.WriteByte(css[i])
+		}
+	}
+	return builder.String()
+}
+
+func isGroupingAtRule(prelude string) bool {
+	for _, atRule := range groupingAtRules {
+		if prelude == atRule || strings.HasPrefix(prelude, atRule+" ") || strings.HasPrefix(prelude, atRule+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// pageSelectors are the tags, ids and classes used in a HTML page
+type pageSelectors struct {
+	tags    map[string]bool
+	ids     map[string]bool
+	classes map[string]bool
+}
+
+func newPageSelectors(htmlString string) *pageSelectors {
+	page := &pageSelectors{map[string]bool{}, map[string]bool{}, map[string]bool{}}
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlString))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return page
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := tokenizer.Token()
+		page.tags[token.Data] = true
+		for _, attr := range token.Attr {
+			switch attr.Key {
+			case "id":
+				page.ids[attr.Val] = true
+			case "class":
+				for _, class := range strings.Fields(attr.Val) {
+					page.classes[class] = true
+				}
+			}
+		}
+	}
+}
+
+// criticalCSS returns the CSS of the rules that may apply to the page, with the @font-face rules
+// and the @keyframes of the animations used by the rules
+func (page *pageSelectors) criticalCSS(rules []*cssRule) string {
+	animationNames := map[string]bool{}
+	page.addAnimationNames(rules, animationNames)
+	return page.writeCriticalCSS(rules, animationNames)
+}
+
+func (page *pageSelectors) writeCriticalCSS(rules []*cssRule, animationNames map[string]bool) string {
+	builder := &strings.Builder{}
+	for _, rule := range rules {
+		switch {
+		case rule.grouping:
+			if inner := page.writeCriticalCSS(rule.rules, animationNames); inner != "" {
+				builder.WriteString(rule.prelude + "{" + inner + "}")
+			}
+		case strings.HasPrefix(rule.prelude, "@font-face"), animationNames[keyframesName(rule.prelude)]:
+			builder.WriteString(rule.prelude + "{" + rule.block + "}")
+		case strings.HasPrefix(rule.prelude, "@"):
+			continue
+		default:
+			if selectors := page.matchingSelectors(rule.prelude); len(selectors) > 0 {
+				builder.WriteString(strings.Join(selectors, ",") + "{" + rule.block + "}")
+			}
+		}
+	}
+	return builder.String()
+}
+
+// addAnimationNames adds the animation names used by the rules that may apply to the page
+func (page *pageSelectors) addAnimationNames(rules []*cssRule, animationNames map[string]bool) {
+	for _, rule := range rules {
+		switch {
+		case rule.grouping:
+			page.addAnimationNames(rule.rules, animationNames)
+		case strings.HasPrefix(rule.prelude, "@"):
+			continue
+		case len(page.matchingSelectors(rule.prelude)) > 0:
+			addBlockAnimationNames(rule.block, animationNames)
+		}
+	}
+}
+
+// addBlockAnimationNames adds the values of the animation and animation-name declarations of the block.
+// All values of the animation shorthand are added, as the names can't be told apart from the keywords
+// without the @keyframes.
+func addBlockAnimationNames(block string, animationNames map[string]bool) {
+	for _, declaration := range splitDeclarations(block) {
+		colon := strings.IndexByte(declaration, ':')
+		if colon == -1 {
+			continue
+		}
+		property := "-" + strings.ToLower(strings.TrimSpace(declaration[:colon]))
+		if !strings.HasSuffix(property, "-animation") && !strings.HasSuffix(property, "-animation-name") {
+			continue
+		}
+		for _, value := range strings.FieldsFunc(declaration[colon+1:], isAnimationValueSeparator) {
+			if name := strings.Trim(value, `"'`); name != "" {
+				animationNames[name] = true
+			}
+		}
+	}
+}
+
+func isAnimationValueSeparator(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
+
+// splitDeclarations splits the declarations of the block by the semicolons outside of strings
+func splitDeclarations(block string) []string {
+	var declarations []string
+	start := 0
+	for i := 0; i < len(block); i++ {
+		switch block[i] {
+		case '"', '\'':
+			i = stringEnd(block, i)
+		case ';':
+			declarations = append(declarations, block[start:i])
+			start = i + 1
+		}
+	}
+	return append(declarations, block[start:])
+}
+
+// keyframesName returns the name of the @keyframes (or vendor prefixed @keyframes) rule, empty for other rules
+func keyframesName(prelude string) string {
+	fields := strings.Fields(prelude)
+	if len(fields) != 2 || !strings.HasPrefix(fields[0], "@") || !strings.HasSuffix(fields[0], "keyframes") {
+		return ""
+	}
+	return strings.Trim(fields[1], `"'`)
+}
+
+// matchingSelectors returns the selectors of the selector list that may apply to the page
+func (page *pageSelectors) matchingSelectors(selectorList string) []string {
+	var selectors []string
+	for _, selector := range splitSelectorList(selectorList) {
+		if page.matches(selector) {
+			selectors = append(selectors, selector)
+		}
+	}
+	return selectors
+}
+
+// matches returns true if the type, id and class selectors of the selector are all in the page,
+// ignoring the pseudo-classes, pseudo-elements and attribute selectors, so the selector may apply to the page
+func (page *pageSelectors) matches(selector string) bool {
+	for _, simpleSelector := range simpleSelectors(selector) {
+		var has bool
+		switch simpleSelector[0] {
+		case '.':
+			has = page.classes[simpleSelector[1:]]
+		case '#':
+			has = page.ids[simpleSelector[1:]]
+		default:
+			has = page.tags[simpleSelector]
+		}
+		if !has {
+			return false
+		}
+	}
+	return true
+}
+
+// splitSelectorList splits the selector list by the commas outside of parentheses, brackets and strings
+func splitSelectorList(selectorList string) []string {
+	var selectors []string
+	depth, start := 0, 0
+	for i := 0; i < len(selectorList); i++ {
+		switch c := selectorList[i]; c {
+		case '"', '\'':
+			i = stringEnd(selectorList, i)
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				selectors = append(selectors, strings.TrimSpace(selectorList[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(selectors, strings.TrimSpace(selectorList[start:]))
+}
+
+// simpleSelectors returns the type (ex. "div"), id (ex. "#id") and class (ex. ".class") selectors of the selector
+func simpleSelectors(selector string) []string {
+	scanner := &selectorScanner{}
+	for i := 0; i < len(selector); i++ {
+		c := selector[i]
+		switch {
+		case c == '\\' && i+1 < len(selector):
+			i++
+			scanner.current = append(scanner.current, selector[i])
+		case c == '[' || c == '(' || c == '"' || c == '\'':
+			scanner.flush()
+			i = skipSelectorBlock(selector, i)
+		case c == ':':
+			scanner.flush()
+			for i+1 < len(selector) && (selector[i+1] == ':' || isSelectorIdentChar(selector[i+1])) {
+				i++
+			}
+		case c == '.' || c == '#':
+			scanner.flush()
+			scanner.current = []byte{c}
+		case isSelectorIdentChar(c):
+			scanner.current = append(scanner.current, c)
+		default:
+			scanner.flush()
+		}
+	}
+	scanner.flush()
+	return scanner.selectors
+}
+
+type selectorScanner struct {
+	current   []byte
+	selectors []string
+}
+
+func (s *selectorScanner) flush() {
+	current := string(s.current)
+	s.current = nil
+	if current == "" || current == "." || current == "#" {
+		return
+	}
+	if current[0] != '.' && current[0] != '#' {
+		current = strings.ToLower(current)
+	}
+	s.selectors = append(s.selectors, current)
+}
+
+// skipSelectorBlock returns the index of the end of the brackets, parentheses or string starting at i
+func skipSelectorBlock(selector string, i int) int {
+	if selector[i] == '"' || selector[i] == '\'' {
+		return stringEnd(selector, i)
+	}
+	depth := 0
+	for ; i < len(selector); i++ {
+		switch c := selector[i]; c {
+		case '"', '\'':
+			i = stringEnd(selector, i)
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(selector)
+}
+
+func isSelectorIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,96 @@
+package webpack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestParseCSS(t *testing.T) {
+	css := `@charset "UTF-8"; /* a { } */ a { color: red; }
+@media (min-width: 1px) { b { x: "}"; } @supports (display: grid) { c { y: z } } }
+d { e { nested: 1 } } } f{}`
+	exp := []string{
+		"a{color: red;}",
+		`@media (min-width: 1px)[b{x: "}";} @supports (display: grid)[c{y: z}]]`,
+		"d{e { nested: 1 }}",
+		"f{}",
+	}
+	test.AssertArray(t, "result", cssRuleStrings(parseCSS(css)), exp)
+}
+
+// cssRuleStrings returns the rules as strings, with the rules of grouping at-rules in []
+func cssRuleStrings(rules []*cssRule) []string {
+	ruleStrings := make([]string, len(rules))
+	for i, rule := range rules {
+		if rule.grouping {
+			ruleStrings[i] = rule.prelude + "[" + strings.Join(cssRuleStrings(rule.rules), " ") + "]"
+		} else {
+			ruleStrings[i] = rule.prelude + "{" + rule.block + "}"
+		}
+	}
+	return ruleStrings
+}
+
+func TestSimpleSelectors(t *testing.T) {
+	testCases := []struct {
+		selector string
+		exp      []string
+	}{
+		{"*", nil},
+		{"DIV", []string{"div"}},
+		{"div.a.b#c", []string{"div", ".a", ".b", "#c"}},
+		{"ul > li + li ~ a", []string{"ul", "li", "li", "a"}},
+		{"a:hover::before", []string{"a"}},
+		{"a:not(.b) .c", []string{"a", ".c"}},
+		{`input[type="text"].d`, []string{"input", ".d"}},
+		{`.md\:flex`, []string{".md:flex"}},
+		{":root", nil},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"selector": tc.selector,
+		})
+		context.AssertArray("result", simpleSelectors(tc.selector), tc.exp)
+	}
+}
+
+func TestSplitSelectorList(t *testing.T) {
+	got := splitSelectorList(`a, b:is(c, d) , e[f=","]`)
+	test.AssertArray(t, "result", got, []string{"a", "b:is(c, d)", `e[f=","]`})
+}
+
+func TestPageSelectors_CriticalCSS(t *testing.T) {
+	page := newPageSelectors(`<html><body><div id="main" class="a  b"><p class="lead">x</p><IMG src="a.png"/></div></body></html>`)
+	testCases := []struct {
+		css string
+		exp string
+	}{
+		{"div { a: b }", "div{a: b}"},
+		{"img { a: b }", "img{a: b}"},
+		{"span { a: b }", ""},
+		{".a.b, .c, #main { a: b }", ".a.b,#main{a: b}"},
+		{"#main > p.lead:first-child::before { a: b }", "#main > p.lead:first-child::before{a: b}"},
+		{"@media print { .c { a: b } }", ""},
+		{"@media print { .c { a: b } .a { c: d } }", "@media print{.a{c: d}}"},
+		{"@font-face { font-family: x }", "@font-face{font-family: x}"},
+		{"@keyframes x { from { a: b } }", ""},
+		{`@keyframes fade { from { a: b } } @keyframes spin { to { c: d } } .a { animation: 1s ease-in "fade" }`,
+			`@keyframes fade{from { a: b }}.a{animation: 1s ease-in "fade"}`},
+		{"@-webkit-keyframes spin { to { c: d } } @media print { @keyframes x { to { c: d } } } .c { animation: x } " +
+			"@media screen { #main { content: ';'; -webkit-animation-name: none, spin } }",
+			"@-webkit-keyframes spin{to { c: d }}@media screen{#main{content: ';'; -webkit-animation-name: none, spin}}"},
+		{`@keyframes x { to { c: d } } .a { animation-name: "" }`, ".a{animation-name: \"\"}"},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"css":   tc.css,
+		})
+		context.Assert("result", page.criticalCSS(parseCSS(tc.css)), tc.exp)
+	}
+}
@@ -0,0 +1,428 @@
+package webpack
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/pool"
+	"github.com/s12chung/gostatic/go/lib/utils"
+)
+
+// ImageSettings is the settings of the ImageGenerator
+type ImageSettings struct {
+	// SourcePath is the directory of the source images, an empty SourcePath disables the ImageGenerator.
+	// The paths relative to it are the originalSrc of GetResponsiveImage.
+	SourcePath string `json:"source_path,omitempty"`
+	// Widths are the widths to resize to, widths larger than the source image are skipped
+	Widths      []int `json:"widths,omitempty"`
+	JPEGQuality int   `json:"jpeg_quality,omitempty"`
+	// PlaceholderWidth is the width of the base64 placeholder image, 0 for no placeholder
+	PlaceholderWidth int `json:"placeholder_width,omitempty"`
+}
+
+// DefaultImageSettings returns the default ImageSettings, which has the ImageGenerator disabled
+func DefaultImageSettings() *ImageSettings {
+	return &ImageSettings{
+		"",
+		[]int{325, 750, 1440},
+		85,
+		16,
+	}
+}
+
+// Validate returns an error if the settings are invalid
+func (s *ImageSettings) Validate() error {
+	if s.SourcePath == "" {
+		return nil
+	}
+	if len(s.Widths) == 0 {
+		return fmt.Errorf("widths are required")
+	}
+	for _, width := range s.Widths {
+		if width <= 0 {
+			return fmt.Errorf("widths must be greater than 0: %v", width)
+		}
+	}
+	if s.JPEGQuality < 1 || s.JPEGQuality > 100 {
+		return fmt.Errorf("jpeg_quality must be between 1 and 100: %v", s.JPEGQuality)
+	}
+	if s.PlaceholderWidth < 0 {
+		return fmt.Errorf("placeholder_width must be 0 or greater: %v", s.PlaceholderWidth)
+	}
+	return nil
+}
+
+// GeneratedImage is a resized image of a GeneratedResponsiveImage
+type GeneratedImage struct {
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// GeneratedResponsiveImage is the JSON file of a responsive image in the `responsive` folder,
+// the same format given by the Webpack loader
+type GeneratedResponsiveImage struct {
+	SrcSet string            `json:"srcSet"`
+	Images []*GeneratedImage `json:"images"`
+	Src    string            `json:"src"`
+	Width  int               `json:"width"`
+	Height int               `json:"height"`
+
+	DominantColor string `json:"dominantColor,omitempty"`
+	Placeholder   string `json:"placeholder,omitempty"`
+
+	// Settings are the ImageSettings that the images were generated with, the images are regenerated when they change
+	Settings *GeneratedImageSettings `json:"settings,omitempty"`
+}
+
+// GeneratedImageSettings are the ImageSettings that change the generated images of a GeneratedResponsiveImage
+type GeneratedImageSettings struct {
+	Widths           []int `json:"widths"`
+	JPEGQuality      int   `json:"jpegQuality"`
+	PlaceholderWidth int   `json:"placeholderWidth"`
+}
+
+func (s *GeneratedImageSettings) equal(other *GeneratedImageSettings) bool {
+	if other == nil || s.JPEGQuality != other.JPEGQuality || s.PlaceholderWidth != other.PlaceholderWidth ||
+		len(s.Widths) != len(other.Widths) {
+		return false
+	}
+	for i, width := range s.Widths {
+		if width != other.Widths[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// generatorExtensions are the extensions of the images that the ImageGenerator can decode
+var generatorExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+}
+
+// ImageGenerator resizes the PNG and JPEG images of ImageSettings.SourcePath into the assets folder,
+// writing the images with hashed filenames and their responsive JSON, so Responsive works without Webpack.
+//
+// The images are kept in their format, as the standard library has no WebP or AVIF encoders, so the responsive JSON
+// has no sources and ResponsiveImage.Sources are empty. Use the Webpack loader for the other formats.
+type ImageGenerator struct {
+	generatedPath string
+	assetsFolder  string
+	settings      *ImageSettings
+	log           logrus.FieldLogger
+}
+
+// NewImageGenerator returns a new instance of ImageGenerator
+func NewImageGenerator(generatedPath, assetsFolder string, settings *ImageSettings, log logrus.FieldLogger) *ImageGenerator {
+	return &ImageGenerator{
+		generatedPath,
+		assetsFolder,
+		settings,
+		log,
+	}
+}
+
+// Enabled returns true if ImageSettings.SourcePath is set
+func (g *ImageGenerator) Enabled() bool {
+	return g.settings != nil && g.settings.SourcePath != ""
+}
+
+// GenerateAll generates the responsive images of all the source images concurrently,
+// skipping the images with responsive JSON newer than the source
+func (g *ImageGenerator) GenerateAll() error {
+	if !g.Enabled() {
+		return nil
+	}
+
+	originalSrcs, err := g.sourceImages()
+	if err != nil {
+		return err
+	}
+
+	tasks := make([]*pool.Task, len(originalSrcs))
+	for i, originalSrc := range originalSrcs {
+		originalSrc := originalSrc
+		tasks[i] = pool.NewTask(g.log.WithField("image", originalSrc), func() error {
+			_, err := g.Generate(originalSrc)
+			return err
+		})
+	}
+
+	p := pool.NewPool(tasks, runtime.NumCPU())
+	p.Run()
+	failedCount := 0
+	p.EachError(func(task *pool.Task) {
+		failedCount++
+		task.Log.Errorf("Error generating image - %v", task.Error)
+	})
+	if failedCount > 0 {
+		return fmt.Errorf("%v images failed to generate", failedCount)
+	}
+	return nil
+}
+
+func (g *ImageGenerator) sourceImages() ([]string, error) {
+	var originalSrcs []string
+	err := filepath.Walk(g.settings.SourcePath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !generatorExtensions[strings.ToLower(filepath.Ext(filePath))] {
+			return nil
+		}
+		originalSrc, err := filepath.Rel(g.settings.SourcePath, filePath)
+		if err != nil {
+			return err
+		}
+		originalSrcs = append(originalSrcs, filepath.ToSlash(originalSrc))
+		return nil
+	})
+	return originalSrcs, err
+}
+
+// Generate resizes the source image of the originalSrc and writes the images and responsive JSON,
+// unless the responsive JSON is newer than the source image and has the same GeneratedImageSettings
+func (g *ImageGenerator) Generate(originalSrc string) (*GeneratedResponsiveImage, error) {
+	sourcePath := filepath.Join(g.settings.SourcePath, originalSrc)
+	if responsiveImage := g.upToDateJSON(originalSrc, sourcePath); responsiveImage != nil {
+		return responsiveImage, nil
+	}
+
+	sourceBytes, err := ioutil.ReadFile(filepath.Clean(sourcePath))
+	if err != nil {
+		return nil, err
+	}
+	src, format, err := image.Decode(bytes.NewReader(sourceBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %v - %v", sourcePath, err)
+	}
+
+	responsiveImage := &GeneratedResponsiveImage{Settings: g.generatedSettings()}
+	for _, width := range g.widths(src.Bounds()) {
+		generatedImage, resizeErr := g.writeResized(originalSrc, src, format, width)
+		if resizeErr != nil {
+			return nil, resizeErr
+		}
+		responsiveImage.Images = append(responsiveImage.Images, generatedImage)
+	}
+	responsiveImage.setSrc()
+	responsiveImage.DominantColor = dominantColor(src)
+	if responsiveImage.Placeholder, err = g.placeholder(src, format); err != nil {
+		return nil, err
+	}
+
+	g.log.Infof("Generated %v responsive images for %v", len(responsiveImage.Images), originalSrc)
+	return responsiveImage, g.writeJSON(originalSrc, responsiveImage)
+}
+
+// widths returns the widths of ImageSettings.Widths that fit the bounds, or the bounds' width if none fit
+func (g *ImageGenerator) widths(bounds image.Rectangle) []int {
+	var widths []int
+	for _, width := range g.settings.Widths {
+		if width <= bounds.Dx() {
+			widths = append(widths, width)
+		}
+	}
+	if len(widths) == 0 {
+		widths = []int{bounds.Dx()}
+	}
+	return widths
+}
+
+// generatedSettings returns the GeneratedImageSettings of the ImageSettings
+func (g *ImageGenerator) generatedSettings() *GeneratedImageSettings {
+	return &GeneratedImageSettings{
+		g.settings.Widths,
+		g.settings.JPEGQuality,
+		g.settings.PlaceholderWidth,
+	}
+}
+
+func (g *ImageGenerator) writeResized(originalSrc string, src image.Image, format string, width int) (*GeneratedImage, error) {
+	height, err := resizedHeight(src.Bounds(), width)
+	if err != nil {
+		return nil, err
+	}
+	imageBytes, err := g.encode(resizeImage(src, width, height), format)
+	if err != nil {
+		return nil, err
+	}
+
+	imagePath := resizedPath(originalSrc, imageBytes, width)
+	if err = g.writeAsset(imagePath, imageBytes); err != nil {
+		return nil, err
+	}
+	return &GeneratedImage{imagePath, width, height}, nil
+}
+
+func (g *ImageGenerator) encode(img image.Image, format string) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	var err error
+	if format == "png" {
+		err = png.Encode(buffer, img)
+	} else {
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: g.settings.JPEGQuality})
+	}
+	return buffer.Bytes(), err
+}
+
+// placeholder returns the base64 data URI of the image resized to ImageSettings.PlaceholderWidth
+func (g *ImageGenerator) placeholder(src image.Image, format string) (string, error) {
+	width := g.settings.PlaceholderWidth
+	if width <= 0 {
+		return "", nil
+	}
+	if width > src.Bounds().Dx() {
+		width = src.Bounds().Dx()
+	}
+
+	height, err := resizedHeight(src.Bounds(), width)
+	if err != nil {
+		return "", err
+	}
+	imageBytes, err := g.encode(resizeImage(src, width, height), format)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:image/%v;base64,%v", format, base64.StdEncoding.EncodeToString(imageBytes)), nil
+}
+
+// dominantColor returns the average color of the image as a CSS hex color, weighted by alpha
+func dominantColor(src image.Image) string {
+	var r, g, b, a uint64
+	bounds := src.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixelR, pixelG, pixelB, pixelA := src.At(x, y).RGBA()
+			r, g, b, a = r+uint64(pixelR), g+uint64(pixelG), b+uint64(pixelB), a+uint64(pixelA)
+		}
+	}
+	if a == 0 {
+		return ""
+	}
+	unpremultiply := func(c uint64) uint64 {
+		return (c*255 + a/2) / a
+	}
+	return fmt.Sprintf("#%02x%02x%02x", unpremultiply(r), unpremultiply(g), unpremultiply(b))
+}
+
+// resizedPath returns the path of the resized image: dir/name-hash-width.ext
+func resizedPath(originalSrc string, imageBytes []byte, width int) string {
+	ext := path.Ext(originalSrc)
+	hash := md5.Sum(imageBytes)
+	return fmt.Sprintf("%v-%v-%v%v", strings.TrimSuffix(originalSrc, ext), hex.EncodeToString(hash[:]), width, ext)
+}
+
+func (r *GeneratedResponsiveImage) setSrc() {
+	srcSet := make([]string, len(r.Images))
+	for i, generatedImage := range r.Images {
+		srcSet[i] = fmt.Sprintf("%v %vw", generatedImage.Path, generatedImage.Width)
+	}
+	r.SrcSet = strings.Join(srcSet, ",")
+
+	first := r.Images[0]
+	r.Src, r.Width, r.Height = first.Path, first.Width, first.Height
+}
+
+func (g *ImageGenerator) assetsPath(filePath string) string {
+	return filepath.Join(g.generatedPath, g.assetsFolder, filepath.FromSlash(filePath))
+}
+
+func (g *ImageGenerator) writeAsset(filePath string, fileBytes []byte) error {
+	assetPath := g.assetsPath(filePath)
+	if err := utils.MkdirAll(filepath.Dir(assetPath)); err != nil {
+		return err
+	}
+	return utils.WriteFile(assetPath, fileBytes)
+}
+
+func jsonPath(originalSrc string) string {
+	return path.Join(path.Dir(originalSrc), responsiveFolder, path.Base(originalSrc)+".json")
+}
+
+func (g *ImageGenerator) writeJSON(originalSrc string, responsiveImage *GeneratedResponsiveImage) error {
+	jsonBytes, err := json.MarshalIndent(responsiveImage, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.writeAsset(jsonPath(originalSrc), jsonBytes)
+}
+
+// upToDateJSON returns the responsive JSON of the originalSrc if it's newer than the source,
+// it was generated with the current GeneratedImageSettings and it has all its images of the current widths, otherwise nil
+func (g *ImageGenerator) upToDateJSON(originalSrc, sourcePath string) *GeneratedResponsiveImage {
+	responsiveJSONPath := g.assetsPath(jsonPath(originalSrc))
+	jsonInfo, err := os.Stat(responsiveJSONPath)
+	if err != nil {
+		return nil
+	}
+	sourceInfo, err := os.Stat(sourcePath)
+	if err != nil || sourceInfo.ModTime().After(jsonInfo.ModTime()) {
+		return nil
+	}
+
+	responsiveImage, err := readGeneratedResponsiveImage(responsiveJSONPath)
+	if err != nil || !g.generatedSettings().equal(responsiveImage.Settings) || !g.hasImages(sourcePath, responsiveImage.Images) {
+		return nil
+	}
+	return responsiveImage
+}
+
+// hasImages returns true if the generated images exist and they are of the current widths of the source image
+func (g *ImageGenerator) hasImages(sourcePath string, generatedImages []*GeneratedImage) bool {
+	widths, err := g.sourceWidths(sourcePath)
+	if err != nil || len(widths) != len(generatedImages) {
+		return false
+	}
+	for i, generatedImage := range generatedImages {
+		if generatedImage.Width != widths[i] {
+			return false
+		}
+		if _, err = os.Stat(g.assetsPath(generatedImage.Path)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func readGeneratedResponsiveImage(filePath string) (*GeneratedResponsiveImage, error) {
+	jsonBytes, err := ioutil.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		return nil, err
+	}
+	responsiveImage := &GeneratedResponsiveImage{}
+	return responsiveImage, json.Unmarshal(jsonBytes, responsiveImage)
+}
+
+// sourceWidths returns the widths to resize the source image to, only decoding its header
+func (g *ImageGenerator) sourceWidths(sourcePath string) ([]int, error) {
+	file, err := os.Open(filepath.Clean(sourcePath))
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g.widths(image.Rect(0, 0, config.Width, config.Height)), nil
+}
@@ -0,0 +1,215 @@
+package webpack
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/utils"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func writeSourceImage(t *testing.T, filePath, format string, width, height int) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 100, 255})
+		}
+	}
+
+	if err := utils.MkdirAll(filepath.Dir(filePath)); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if format == "png" {
+		err = png.Encode(file, img)
+	} else {
+		err = jpeg.Encode(file, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func defaultImageGenerator(t *testing.T) (*ImageGenerator, *logTest.Hook, func()) {
+	dir, clean := testfile.SandboxDir(t, "images")
+	settings := DefaultImageSettings()
+	settings.SourcePath = filepath.Join(dir, "source")
+	settings.Widths = []int{100, 300, 1000}
+
+	writeSourceImage(t, filepath.Join(settings.SourcePath, "content/images/wide.png"), "png", 400, 200)
+	writeSourceImage(t, filepath.Join(settings.SourcePath, "content/images/small.jpg"), "jpeg", 50, 30)
+	writeSourceImage(t, filepath.Join(settings.SourcePath, "content/images/skipped.gif"), "png", 10, 10)
+
+	log, hook := logTest.NewNullLogger()
+	return NewImageGenerator(filepath.Join(dir, "generated"), "assets", settings, log), hook, clean
+}
+
+func TestImageSettings_Validate(t *testing.T) {
+	testCases := []struct {
+		sourcePath       string
+		widths           []int
+		jpegQuality      int
+		placeholderWidth int
+		err              bool
+	}{
+		{"", nil, 0, 0, false},
+		{"images", []int{100}, 85, 0, false},
+		{"images", []int{100}, 85, 16, false},
+		{"images", nil, 85, 16, true},
+		{"images", []int{0}, 85, 16, true},
+		{"images", []int{100}, 0, 16, true},
+		{"images", []int{100}, 101, 16, true},
+		{"images", []int{100}, 85, -1, true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":            testCaseIndex,
+			"sourcePath":       tc.sourcePath,
+			"widths":           tc.widths,
+			"jpegQuality":      tc.jpegQuality,
+			"placeholderWidth": tc.placeholderWidth,
+		})
+		settings := &ImageSettings{tc.sourcePath, tc.widths, tc.jpegQuality, tc.placeholderWidth}
+		context.Assert("err != nil", settings.Validate() != nil, tc.err)
+	}
+}
+
+func TestImageGenerator_GenerateAll(t *testing.T) {
+	generator, _, clean := defaultImageGenerator(t)
+	defer clean()
+
+	test.AssertError(t, generator.GenerateAll(), "generator.GenerateAll")
+
+	testCases := []struct {
+		originalSrc string
+		widths      []int
+		heights     []int
+	}{
+		{"content/images/wide.png", []int{100, 300}, []int{50, 150}},
+		{"content/images/small.jpg", []int{50}, []int{30}},
+	}
+
+	responsive := NewResponsive(generator.generatedPath, generator.assetsFolder, generator.log)
+	pathRegex := regexp.MustCompile(`^content/images/\w+-[0-9a-f]{32}-\d+\.(png|jpg)$`)
+	colorRegex := regexp.MustCompile(`^#[0-9a-f]{6}$`)
+	placeholderRegex := regexp.MustCompile(`^data:image/(png|jpeg);base64,[A-Za-z0-9+/=]+$`)
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":       testCaseIndex,
+			"originalSrc": tc.originalSrc,
+		})
+
+		responsiveImage, err := readGeneratedResponsiveImage(generator.assetsPath(jsonPath(tc.originalSrc)))
+		context.AssertError(err, "readGeneratedResponsiveImage")
+		context.Assert("len(Images)", len(responsiveImage.Images), len(tc.widths))
+		for i, generatedImage := range responsiveImage.Images {
+			context.Assert("Path", pathRegex.MatchString(generatedImage.Path), true)
+			context.Assert("Width", generatedImage.Width, tc.widths[i])
+			context.Assert("Height", generatedImage.Height, tc.heights[i])
+
+			file, err := os.Open(generator.assetsPath(generatedImage.Path))
+			context.AssertError(err, "os.Open")
+			config, _, err := image.DecodeConfig(file)
+			context.AssertError(err, "image.DecodeConfig")
+			context.AssertError(file.Close(), "file.Close")
+			context.Assert("config.Width", config.Width, tc.widths[i])
+			context.Assert("config.Height", config.Height, tc.heights[i])
+		}
+		context.Assert("Src", responsiveImage.Src, responsiveImage.Images[0].Path)
+		context.Assert("DominantColor", colorRegex.MatchString(responsiveImage.DominantColor), true)
+		context.Assert("Placeholder", placeholderRegex.MatchString(responsiveImage.Placeholder), true)
+
+		got := responsive.GetResponsiveImage(tc.originalSrc)
+		context.Assert("GetResponsiveImage.Src", got.Src, "assets/"+responsiveImage.Src)
+	}
+
+	_, err := os.Stat(generator.assetsPath("content/images/responsive/skipped.gif.json"))
+	test.AssertLabel(t, "gif skipped", os.IsNotExist(err), true)
+}
+
+func TestImageGenerator_Generate_UpToDate(t *testing.T) {
+	generator, hook, clean := defaultImageGenerator(t)
+	defer clean()
+
+	originalSrc := "content/images/wide.png"
+	exp, err := generator.Generate(originalSrc)
+	test.AssertError(t, err, "generator.Generate")
+	test.AssertLabel(t, "generated log count", len(hook.AllEntries()), 1)
+
+	got, err := generator.Generate(originalSrc)
+	test.AssertError(t, err, "generator.Generate")
+	test.AssertArray(t, "result", got, exp)
+	test.AssertLabel(t, "up to date log count", len(hook.AllEntries()), 1)
+
+	generator.settings.Widths = []int{200}
+	got, err = generator.Generate(originalSrc)
+	test.AssertError(t, err, "generator.Generate")
+	test.AssertLabel(t, "changed widths log count", len(hook.AllEntries()), 2)
+	test.AssertLabel(t, "changed widths", got.Width, 200)
+
+	generator.settings.JPEGQuality = 50
+	_, err = generator.Generate(originalSrc)
+	test.AssertError(t, err, "generator.Generate")
+	test.AssertLabel(t, "changed jpeg quality log count", len(hook.AllEntries()), 3)
+
+	generator.settings.PlaceholderWidth = 0
+	got, err = generator.Generate(originalSrc)
+	test.AssertError(t, err, "generator.Generate")
+	test.AssertLabel(t, "changed placeholder width log count", len(hook.AllEntries()), 4)
+	test.AssertLabel(t, "changed placeholder width", got.Placeholder, "")
+
+	got, err = generator.Generate(originalSrc)
+	test.AssertError(t, err, "generator.Generate")
+	test.AssertLabel(t, "unchanged log count", len(hook.AllEntries()), 4)
+	test.AssertArray(t, "Settings", got.Settings, &GeneratedImageSettings{[]int{200}, 50, 0})
+}
+
+func TestImageGenerator_GenerateAll_Disabled(t *testing.T) {
+	log, _ := logTest.NewNullLogger()
+	generator := NewImageGenerator(generatedPath, "assets", DefaultImageSettings(), log)
+	test.AssertLabel(t, "Enabled", generator.Enabled(), false)
+	test.AssertError(t, generator.GenerateAll(), "generator.GenerateAll")
+}
+
+func TestDominantColor(t *testing.T) {
+	testCases := []struct {
+		colors []color.Color
+		exp    string
+	}{
+		{[]color.Color{color.NRGBA{255, 0, 0, 255}, color.NRGBA{255, 0, 0, 255}}, "#ff0000"},
+		{[]color.Color{color.NRGBA{200, 100, 0, 255}, color.NRGBA{0, 100, 200, 255}}, "#646464"},
+		{[]color.Color{color.NRGBA{200, 100, 0, 255}, color.NRGBA{0, 0, 0, 0}}, "#c86400"},
+		{[]color.Color{color.NRGBA{0, 0, 0, 0}, color.NRGBA{0, 0, 0, 0}}, ""},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"colors": tc.colors,
+		})
+
+		img := image.NewNRGBA(image.Rect(0, 0, len(tc.colors), 1))
+		for x, c := range tc.colors {
+			img.Set(x, 0, c)
+		}
+		context.Assert("result", dominantColor(img), tc.exp)
+	}
+}
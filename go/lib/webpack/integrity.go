@@ -0,0 +1,39 @@
+package webpack
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"path/filepath"
+
+	"github.com/s12chung/gostatic/go/lib/filecache"
+)
+
+// integrityPrefix is the prefix of the Subresource Integrity (SRI) hashes
+const integrityPrefix = "sha384-"
+
+// Integrity computes the Subresource Integrity (SRI) hashes of the generated asset files.
+// The hashes are cached per build, so they're computed again when the file's modtime changes (ex. assets rebuilt while hosting).
+type Integrity struct {
+	generatedAssetsPath string
+	hashes              *filecache.Files
+}
+
+// NewIntegrity returns a new instance of Integrity
+func NewIntegrity(generatedAssetsPath string) *Integrity {
+	return &Integrity{
+		generatedAssetsPath,
+		filecache.NewFiles(func(bytes []byte) (interface{}, error) {
+			sum := sha512.Sum384(bytes)
+			return integrityPrefix + base64.StdEncoding.EncodeToString(sum[:]), nil
+		}),
+	}
+}
+
+// Hash returns the integrity attr value (ex. "sha384-...") of the file path, relative to the generated assets path
+func (i *Integrity) Hash(filePath string) (string, error) {
+	hash, err := i.hashes.Get(filepath.Join(i.generatedAssetsPath, filepath.FromSlash(filePath)))
+	if err != nil {
+		return "", err
+	}
+	return hash.(string), nil
+}
@@ -0,0 +1,63 @@
+package webpack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/s12chung/gostatic/go/lib/utils"
+	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
+)
+
+func TestIntegrity_Hash(t *testing.T) {
+	testCases := []struct {
+		filePath string
+		exp      string
+	}{
+		{"vendor-32267303b2484ed8b3aa.css", "sha384-WB/q3J/afHwzaDqIadENWJtsF80Y8uFQx01ITApnl3nWi455Y762kZBgmn1sScvL"},
+		{"fonts/inter-1f2e3d4c5b6a7f8e9d0c.woff2", "sha384-S3fIiVERLVzV27O4ScbL7BjI3j+V5ESj0MvZIGneB6cGwCyZ67elbQHGFlBCEAR9"},
+		{"nofile-9f8e7d6c5b4a39281706.js", ""},
+	}
+
+	integrity := NewIntegrity(filepath.Join(generatedPath, "assets"))
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":    testCaseIndex,
+			"filePath": tc.filePath,
+		})
+
+		got, err := integrity.Hash(tc.filePath)
+		context.Assert("err != nil", err != nil, tc.exp == "")
+		context.Assert("result", got, tc.exp)
+	}
+}
+
+func TestIntegrity_Hash_Changed(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "integrity")
+	defer clean()
+	test.AssertError(t, utils.MkdirAll(dir), "utils.MkdirAll")
+
+	filePath := filepath.Join(dir, "main.js")
+	writeFile := func(contents string, modTime time.Time) {
+		test.AssertError(t, utils.WriteFile(filePath, []byte(contents)), "utils.WriteFile")
+		test.AssertError(t, os.Chtimes(filePath, modTime, modTime), "os.Chtimes")
+	}
+	modTime := time.Now().Add(-time.Hour)
+	integrity := NewIntegrity(dir)
+
+	writeFile("a", modTime)
+	first, err := integrity.Hash("main.js")
+	test.AssertError(t, err, "integrity.Hash")
+
+	writeFile("b", modTime)
+	cached, err := integrity.Hash("main.js")
+	test.AssertError(t, err, "integrity.Hash")
+	test.AssertLabel(t, "cached", cached, first)
+
+	writeFile("b", modTime.Add(time.Minute))
+	changed, err := integrity.Hash("main.js")
+	test.AssertError(t, err, "integrity.Hash")
+	test.AssertLabel(t, "changed", changed != first, true)
+}
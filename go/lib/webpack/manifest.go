@@ -2,66 +2,92 @@ package webpack
 
 import (
 	"encoding/json"
-	"io/ioutil"
+	"fmt"
 	"path/filepath"
-	"sync"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/s12chung/gostatic/go/lib/filecache"
+	"github.com/s12chung/gostatic/go/lib/urls"
 )
 
 const manifestPath = "manifest.json"
 
-// Manifest represents a Manifest file
+// Manifest represents a Manifest file, which is reloaded when its modtime changes (ex. assets rebuilt while hosting)
 type Manifest struct {
-	generatedPath    string
-	assetsFolder     string
-	manifestMap      map[string]string
-	manifestMapMutex *sync.RWMutex
-	log              logrus.FieldLogger
+	generatedPath string
+	assetsFolder  string
+	urls          *urls.URLs
+	file          *filecache.File
+	log           logrus.FieldLogger
 }
 
-// NewManifest returns a new instance of Manifest
-func NewManifest(generatedPath, assetsFolder string, log logrus.FieldLogger) *Manifest {
+// NewManifest returns a new instance of Manifest, the urls are applied to the URLs of ManifestURL
+func NewManifest(generatedPath, assetsFolder string, u *urls.URLs, log logrus.FieldLogger) *Manifest {
+	filePath := filepath.Join(generatedPath, assetsFolder, manifestPath)
+	// loaded is only used by the parse func, which is called with the lock of the file
+	loaded := false
 	return &Manifest{
 		generatedPath,
 		assetsFolder,
-		map[string]string{},
-		&sync.RWMutex{},
+		u,
+		filecache.NewFile(filePath, func(bytes []byte) (interface{}, error) {
+			manifestMap := map[string]string{}
+			if err := json.Unmarshal(bytes, &manifestMap); err != nil {
+				return nil, err
+			}
+			if loaded {
+				log.Infof("Reloaded changed webpack manifest %v", filePath)
+			}
+			loaded = true
+			return manifestMap, nil
+		}),
 		log,
 	}
 }
 
-// ManifestURL returns the manifest URL of the file (so it returns hashed file paths that exist), given a file path key.
+// ManifestURL returns the manifest URL of the file (so it returns hashed file paths that exist), given a file path key,
+// with the asset host and base path of the urls. If the key is not found, the error is logged and the key is used in the URL.
 func (w *Manifest) ManifestURL(key string) string {
-	return w.assetsFolder + "/" + w.manifestValue(key)
-}
-
-func (w *Manifest) manifestValue(key string) string {
-	w.manifestMapMutex.Lock()
-	if len(w.manifestMap) == 0 {
-		err := w.readManifest()
-		if err != nil {
-			w.log.Errorf("readManifest error: %v", err)
-			return key
-		}
+	value, err := w.ManifestValue(key)
+	if err != nil {
+		w.log.Error(err)
+		value = key
 	}
-	w.manifestMapMutex.Unlock()
+	return w.assetURL(value)
+}
 
-	w.manifestMapMutex.RLock()
-	value := w.manifestMap[key]
-	w.manifestMapMutex.RUnlock()
+// assetURL returns the URL of the file path in the assets folder, with the asset host and base path of the urls
+func (w *Manifest) assetURL(value string) string {
+	return w.urls.AssetURL(w.assetsFolder + "/" + value)
+}
 
-	if value == "" {
-		w.log.Errorf("webpack manifestValue not found for key: %v", key)
-		return key
+// ManifestValue returns the value of the key in the manifest file, an error is returned if
+// the manifest can't be read or the key is not found
+func (w *Manifest) ManifestValue(key string) (string, error) {
+	manifestMap, err := w.getManifestMap()
+	if err != nil {
+		return "", fmt.Errorf("error reading webpack manifest - %v", err)
+	}
+	value, has := manifestMap[key]
+	if !has || value == "" {
+		return "", fmt.Errorf("webpack manifest value not found for key: %v", key)
 	}
-	return value
+	return value, nil
+}
+
+// HasKey returns true if the key is in the manifest, without logging
+func (w *Manifest) HasKey(key string) bool {
+	_, err := w.ManifestValue(key)
+	return err == nil
 }
 
-func (w *Manifest) readManifest() error {
-	bytes, err := ioutil.ReadFile(filepath.Join(w.generatedPath, w.assetsFolder, manifestPath))
+// getManifestMap returns the manifest map, reading the file if it has not been read or its modtime changed.
+// The map is replaced, not modified, so it's safe to use after returning.
+func (w *Manifest) getManifestMap() (map[string]string, error) {
+	manifestMap, err := w.file.Get()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return json.Unmarshal(bytes, &w.manifestMap)
+	return manifestMap.(map[string]string), nil
 }
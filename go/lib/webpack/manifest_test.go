@@ -1,16 +1,23 @@
 package webpack
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	logTest "github.com/sirupsen/logrus/hooks/test"
 
+	"github.com/s12chung/gostatic/go/lib/urls"
+	"github.com/s12chung/gostatic/go/lib/utils"
 	"github.com/s12chung/gostatic/go/test"
+	"github.com/s12chung/gostatic/go/test/testfile"
 )
 
-func defaultManifest() (*Manifest, *logTest.Hook) {
+func defaultManifest(assetsFolder string) (*Manifest, *logTest.Hook) {
 	log, hook := logTest.NewNullLogger()
-	return NewManifest(generatedPath, "assets", log), hook
+	return NewManifest(generatedPath, assetsFolder, urls.NewURLs(nil), log), hook
 }
 
 func TestManifest_ManifestUrl(t *testing.T) {
@@ -34,9 +41,78 @@ func TestManifest_ManifestUrl(t *testing.T) {
 			"key":          tc.key,
 		})
 
-		manifest, hook := defaultManifest()
-		manifest.assetsFolder = tc.assetsFolder
+		manifest, hook := defaultManifest(tc.assetsFolder)
 		context.Assert("Result", manifest.ManifestURL(tc.key), tc.exp)
 		context.Assert("test.SafeLogEntries(hook)", test.SafeLogEntries(hook), tc.safeLog)
 	}
 }
+
+func TestManifest_ManifestValue(t *testing.T) {
+	testCases := []struct {
+		assetsFolder string
+		key          string
+		exp          string
+		err          bool
+	}{
+		{"assets", "vendor.css", "vendor-32267303b2484ed8b3aa.css", false},
+		{"assets", "does_not_exist.gif", "", true},
+		{"does_not_exist", "test.gif", "", true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":        testCaseIndex,
+			"assetsFolder": tc.assetsFolder,
+			"key":          tc.key,
+		})
+
+		manifest, hook := defaultManifest(tc.assetsFolder)
+		for i := 0; i < 2; i++ {
+			got, err := manifest.ManifestValue(tc.key)
+			context.Assert("err != nil", err != nil, tc.err)
+			context.Assert("Result", got, tc.exp)
+			context.Assert("HasKey", manifest.HasKey(tc.key), !tc.err)
+		}
+		context.Assert("len(hook.AllEntries())", len(hook.AllEntries()), 0)
+	}
+}
+
+func TestManifest_ManifestValue_Reload(t *testing.T) {
+	dir, clean := testfile.SandboxDir(t, "manifest")
+	defer clean()
+
+	log, hook := logTest.NewNullLogger()
+	manifest := NewManifest(dir, "assets", urls.NewURLs(nil), log)
+	manifestFilePath := filepath.Join(dir, "assets", manifestPath)
+	test.AssertError(t, utils.MkdirAll(filepath.Dir(manifestFilePath)), "utils.MkdirAll")
+
+	writeManifest := func(manifestJSON string, modTime time.Time) {
+		test.AssertError(t, utils.WriteFile(manifestFilePath, []byte(manifestJSON)), "utils.WriteFile")
+		test.AssertError(t, os.Chtimes(manifestFilePath, modTime, modTime), "os.Chtimes")
+	}
+	modTime := time.Now().Add(-time.Hour)
+
+	writeManifest(`{"main.js": "main-1.js"}`, modTime)
+	got, err := manifest.ManifestValue("main.js")
+	test.AssertError(t, err, "manifest.ManifestValue")
+	test.AssertLabel(t, "first read", got, "main-1.js")
+
+	writeManifest(`{"main.js": "main-2.js"}`, modTime)
+	got, err = manifest.ManifestValue("main.js")
+	test.AssertError(t, err, "manifest.ManifestValue")
+	test.AssertLabel(t, "same modtime", got, "main-1.js")
+
+	writeManifest(`{"main.js": "main-3.js"}`, modTime.Add(time.Minute))
+	got, err = manifest.ManifestValue("main.js")
+	test.AssertError(t, err, "manifest.ManifestValue")
+	test.AssertLabel(t, "changed modtime", got, "main-3.js")
+	test.AssertArray(t, "levels", test.LogEntryLevels(hook), []logrus.Level{logrus.InfoLevel})
+
+	writeManifest(`{`, modTime.Add(2*time.Minute))
+	_, err = manifest.ManifestValue("main.js")
+	test.AssertLabel(t, "malformed err != nil", err != nil, true)
+	writeManifest(`{"main.js": "main-4.js"}`, modTime.Add(3*time.Minute))
+	got, err = manifest.ManifestValue("main.js")
+	test.AssertError(t, err, "manifest.ManifestValue")
+	test.AssertLabel(t, "after malformed", got, "main-4.js")
+}
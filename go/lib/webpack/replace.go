@@ -0,0 +1,199 @@
+package webpack
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// skipTags are the tags with contents that are left as is, as they show code
+var skipTags = map[string]bool{
+	"code": true,
+	"pre":  true,
+}
+
+var cssURLRegex = regexp.MustCompile(`url\(\s*(?:'([^']*)'|"([^"]*)"|([^'")\s]*))\s*\)`)
+
+// ReplaceResponsiveAttrs tokenizes the HTML and replaces the image URLs with their responsive or manifest URLs.
+// It takes the existing URLs as the originalSrc to call webpack.GetResponsiveImage:
+//
+// - <img src> becomes the responsive img.src and img.srcset attrs, unless the <img> already has a srcset
+// - <source src> and each URL of <source srcset>
+// - <video poster>
+// - url() of the style attrs, ex. background images
+//
+// Other attributes are kept. Tags within comments, <code> and <pre> are left as is, along with external URLs.
+// A warning is logged for images without a responsive image or manifest entry.
+//
+// You may add a srcPrefix to the URLs, so webpack.GetResponsiveImage can work.
+func (w *Webpack) ReplaceResponsiveAttrs(srcPrefix, htmlString string) string {
+	replaced, err := w.replaceResponsiveAttrs(srcPrefix, htmlString)
+	if err != nil {
+		w.log.Error(err)
+	}
+	return replaced
+}
+
+// replaceResponsiveAttrs is ReplaceResponsiveAttrs, returning the first missing image error if Settings.Strict
+func (w *Webpack) replaceResponsiveAttrs(srcPrefix, htmlString string) (string, error) {
+	replacer := &attrReplacer{w, srcPrefix, nil}
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlString))
+	builder := &strings.Builder{}
+	skipDepth := 0
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		// copy Raw, as it is lowercased in place by Token
+		raw := string(tokenizer.Raw())
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if tokenType == html.StartTagToken && skipTags[token.Data] {
+				skipDepth++
+			}
+			if skipDepth == 0 && replacer.replaceTagAttrs(&token) {
+				raw = token.String()
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); skipTags[string(name)] && skipDepth > 0 {
+				skipDepth--
+			}
+		}
+		builder.WriteString(raw)
+	}
+	return builder.String(), replacer.err
+}
+
+// attrReplacer replaces the image URLs of tag attrs, keeping the first error
+type attrReplacer struct {
+	w         *Webpack
+	srcPrefix string
+	err       error
+}
+
+// replaceTagAttrs replaces the image URLs of the token's attrs, returning true if the token changed
+func (r *attrReplacer) replaceTagAttrs(token *html.Token) bool {
+	hasSrcSet := hasAttr(token, "srcset")
+
+	changed := false
+	var attrs []html.Attribute
+	for _, attr := range token.Attr {
+		newAttrs := r.replaceAttr(token.Data, attr, hasSrcSet)
+		if len(newAttrs) != 1 || newAttrs[0] != attr {
+			changed = true
+		}
+		attrs = append(attrs, newAttrs...)
+	}
+	token.Attr = attrs
+	return changed
+}
+
+func (r *attrReplacer) replaceAttr(tagName string, attr html.Attribute, hasSrcSet bool) []html.Attribute {
+	switch {
+	case tagName == "img" && attr.Key == "src" && !hasSrcSet:
+		responsiveImage := r.replaceImage(tagName, attr.Val)
+		if responsiveImage == nil {
+			return []html.Attribute{attr}
+		}
+		attrs := []html.Attribute{{Key: "src", Val: responsiveImage.Src}}
+		if responsiveImage.SrcSet != "" {
+			attrs = append(attrs, html.Attribute{Key: "srcset", Val: responsiveImage.SrcSet})
+		}
+		return attrs
+	case (tagName == "source" && attr.Key == "src") || (tagName == "video" && attr.Key == "poster"):
+		attr.Val = r.replaceURL(tagName, attr.Val)
+	case tagName == "source" && attr.Key == "srcset":
+		attr.Val = r.replaceSrcSet(tagName, attr.Val)
+	case attr.Key == "style":
+		attr.Val = cssURLRegex.ReplaceAllStringFunc(attr.Val, func(cssURL string) string {
+			matches := cssURLRegex.FindStringSubmatch(cssURL)
+			originalSrc := matches[1] + matches[2] + matches[3]
+			return fmt.Sprintf("url('%v')", r.replaceURL(tagName, originalSrc))
+		})
+	}
+	return []html.Attribute{attr}
+}
+
+// replaceImage returns the *ResponsiveImage of the src, or nil if it's external
+func (r *attrReplacer) replaceImage(tagName, src string) *ResponsiveImage {
+	if src == "" || isExternalURL(src) {
+		return nil
+	}
+
+	w := r.w
+	originalSrc := path.Join(r.srcPrefix, src)
+	if HasResponsiveExt(originalSrc) {
+		responsiveImage, err := w.responsive.getResponsiveImage(originalSrc)
+		if err == nil {
+			return responsiveImage
+		}
+	}
+	if !w.manifest.HasKey(originalSrc) {
+		err := fmt.Errorf("no responsive image or manifest entry for <%v> URL: %v", tagName, originalSrc)
+		if w.settings.Strict {
+			if r.err == nil {
+				r.err = err
+			}
+		} else {
+			w.log.Warn(err)
+		}
+		return &ResponsiveImage{Src: w.manifest.assetURL(originalSrc)}
+	}
+	responsiveImage, _ := w.manifestImage(originalSrc)
+	return responsiveImage
+}
+
+func (r *attrReplacer) replaceURL(tagName, src string) string {
+	responsiveImage := r.replaceImage(tagName, src)
+	if responsiveImage == nil {
+		return src
+	}
+	return responsiveImage.Src
+}
+
+// replaceSrcSet replaces each URL of the srcSet, a single URL without a descriptor is replaced by its responsive srcset
+func (r *attrReplacer) replaceSrcSet(tagName, srcSet string) string {
+	candidates := strings.Split(srcSet, ",")
+	if len(candidates) == 1 && len(strings.Fields(srcSet)) == 1 {
+		responsiveImage := r.replaceImage(tagName, strings.TrimSpace(srcSet))
+		if responsiveImage == nil {
+			return srcSet
+		}
+		if responsiveImage.SrcSet != "" {
+			return responsiveImage.SrcSet
+		}
+		return responsiveImage.Src
+	}
+
+	var newCandidates []string
+	for _, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = r.replaceURL(tagName, fields[0])
+		newCandidates = append(newCandidates, strings.Join(fields, " "))
+	}
+	return strings.Join(newCandidates, ", ")
+}
+
+func hasAttr(token *html.Token, key string) bool {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func isExternalURL(src string) bool {
+	u, err := url.Parse(src)
+	return err == nil && (u.Scheme != "" || u.Host != "")
+}
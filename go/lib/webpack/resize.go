@@ -0,0 +1,29 @@
+package webpack
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// resizedHeight returns the height of the image resized to the width, keeping the aspect ratio,
+// or an error if the image or width is empty
+func resizedHeight(bounds image.Rectangle, width int) (int, error) {
+	if bounds.Empty() || width <= 0 {
+		return 0, fmt.Errorf("can't resize image of bounds %v to width %v", bounds, width)
+	}
+	height := int(math.Round(float64(bounds.Dy()) * float64(width) / float64(bounds.Dx())))
+	if height < 1 {
+		return 1, nil
+	}
+	return height, nil
+}
+
+// resizeImage resizes the image with the Catmull-Rom filter of golang.org/x/image/draw, like the favicon package
+func resizeImage(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	return dst
+}
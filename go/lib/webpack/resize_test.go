@@ -0,0 +1,96 @@
+package webpack
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/s12chung/gostatic/go/test"
+)
+
+func TestResizedHeight(t *testing.T) {
+	testCases := []struct {
+		width, height int
+		resizeWidth   int
+		exp           int
+		err           bool
+	}{
+		{400, 200, 100, 50, false},
+		{300, 100, 100, 33, false},
+		{300, 200, 100, 67, false},
+		{1000, 1, 10, 1, false},
+		{0, 200, 100, 0, true},
+		{400, 0, 100, 0, true},
+		{0, 0, 100, 0, true},
+		{400, 200, 0, 0, true},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":       testCaseIndex,
+			"width":       tc.width,
+			"height":      tc.height,
+			"resizeWidth": tc.resizeWidth,
+		})
+		got, err := resizedHeight(image.Rect(0, 0, tc.width, tc.height), tc.resizeWidth)
+		context.Assert("err != nil", err != nil, tc.err)
+		context.Assert("result", got, tc.exp)
+	}
+}
+
+func halvesImage() *image.NRGBA {
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 2))
+	for x := 0; x < 8; x++ {
+		c := color.NRGBA{0, 0, 0, 255}
+		if x >= 4 {
+			c = color.NRGBA{255, 255, 255, 255}
+		}
+		src.Set(x, 0, c)
+		src.Set(x, 1, c)
+	}
+	return src
+}
+
+func TestResizeImage(t *testing.T) {
+	translucent := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 4; y++ {
+			translucent.Set(x, y, color.NRGBA{200, 100, 50, 128})
+		}
+	}
+	gray := func(value uint8) color.RGBA {
+		return color.RGBA{value, value, value, 255}
+	}
+
+	testCases := []struct {
+		name   string
+		src    image.Image
+		width  int
+		height int
+		exp    []color.RGBA
+	}{
+		// premultiplied alpha, without dark edges
+		{"translucent", translucent, 2, 1, []color.RGBA{{100, 50, 25, 128}, {100, 50, 25, 128}}},
+		{"halves", halvesImage(), 4, 1, []color.RGBA{gray(0), gray(16), gray(239), gray(255)}},
+		{"halves", halvesImage(), 2, 1, []color.RGBA{gray(24), gray(231)}},
+		// the bounds don't start at 0, 0
+		{"sub image", halvesImage().SubImage(image.Rect(4, 0, 8, 2)), 2, 1, []color.RGBA{gray(255), gray(255)}},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":  testCaseIndex,
+			"name":   tc.name,
+			"width":  tc.width,
+			"height": tc.height,
+		})
+
+		got := resizeImage(tc.src, tc.width, tc.height)
+		context.Assert("bounds", got.Bounds(), image.Rect(0, 0, tc.width, tc.height))
+		pixels := make([]color.RGBA, tc.width)
+		for x := range pixels {
+			pixels[x] = got.RGBAAt(x, 0)
+		}
+		context.AssertArray("pixels", pixels, tc.exp)
+	}
+}
@@ -13,8 +13,11 @@ import (
 const responsiveFolder = "responsive"
 
 var responsiveExtensions = map[string]bool{
-	".png": true,
-	".jpg": true,
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".webp": true,
+	".avif": true,
 }
 
 // Responsive handles the overview logic of responsive images.
@@ -2,6 +2,7 @@ package webpack
 
 import (
 	"fmt"
+	"html/template"
 	"path"
 	"regexp"
 	"strings"
@@ -15,28 +16,61 @@ import (
 type ResponsiveImage struct {
 	Src    string `json:"src"`
 	SrcSet string `json:"srcSet"`
+
+	// Width and Height are the dimensions of Src, used as the <img> width and height attrs to prevent layout shift
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Sources are the other formats of the image (ex. WebP, AVIF), in order of preference.
+	// They are only given by the responsive JSON of the Webpack loader, not the ImageGenerator.
+	Sources []*ResponsiveSource `json:"sources,omitempty"`
+
+	// DominantColor is a CSS color shown while the image loads
+	DominantColor string `json:"dominantColor,omitempty"`
+	// Placeholder is a tiny (base64 data URI) version of the image shown while the image loads
+	Placeholder string `json:"placeholder,omitempty"`
+}
+
+// ResponsiveSource is a format of a ResponsiveImage, a <source> of a <picture>
+type ResponsiveSource struct {
+	Type   string `json:"type"`
+	SrcSet string `json:"srcSet"`
 }
 
 var spacesRegex = regexp.MustCompile(`\s+`)
 
-// PrependSrcPath prepends the given prefix to the Src and SrcSet of the ResponsiveImage
+// PrependSrcPath prepends the given prefix to the Src and SrcSet of the ResponsiveImage and its Sources
 func (r *ResponsiveImage) PrependSrcPath(prefix string, log logrus.FieldLogger) {
-	r.Src = prependSrcPath(prefix, r.Src)
-	if r.SrcSet == "" {
-		return
+	r.MapSrcs(func(src string) string {
+		return prependSrcPath(prefix, src)
+	}, log)
+}
+
+// MapSrcs replaces the URLs of the Src and SrcSet of the ResponsiveImage and its Sources with the result of mapSrc
+func (r *ResponsiveImage) MapSrcs(mapSrc func(src string) string, log logrus.FieldLogger) {
+	if r.Src != "" {
+		r.Src = mapSrc(r.Src)
+	}
+	r.SrcSet = mapSrcSet(mapSrc, r.SrcSet, r.Src, log)
+	for _, source := range r.Sources {
+		source.SrcSet = mapSrcSet(mapSrc, source.SrcSet, r.Src, log)
+	}
+}
+
+func mapSrcSet(mapSrc func(src string) string, srcSet, src string, log logrus.FieldLogger) string {
+	if srcSet == "" {
+		return ""
 	}
 
 	var newSrcSet []string
-	for _, srcWidth := range strings.Split(r.SrcSet, ",") {
+	for _, srcWidth := range strings.Split(srcSet, ",") {
 		srcWidthSplit := spacesRegex.Split(strings.Trim(srcWidth, " "), -1)
 		if len(srcWidthSplit) != 2 {
-			log.Warn("skipping, srcSet is not formatted correctly with '%v' for img src='%v'", srcWidth, r.Src)
+			log.Warn("skipping, srcSet is not formatted correctly with '%v' for img src='%v'", srcWidth, src)
 			continue
 		}
-		newSrcSet = append(newSrcSet, fmt.Sprintf("%v %v", prependSrcPath(prefix, srcWidthSplit[0]), srcWidthSplit[1]))
+		newSrcSet = append(newSrcSet, fmt.Sprintf("%v %v", mapSrc(srcWidthSplit[0]), srcWidthSplit[1]))
 	}
-
-	r.SrcSet = strings.Join(newSrcSet, ", ")
+	return strings.Join(newSrcSet, ", ")
 }
 
 // HTMLAttrs returns the HTML attributes of the ResponsiveImage for the <img> tag
@@ -51,6 +85,47 @@ func (r *ResponsiveImage) HTMLAttrs() string {
 	return strings.Join(htmlAttrs, " ")
 }
 
+// PictureHTML returns the <picture> tag of the ResponsiveImage, with a <source> for each of the Sources
+// and an <img> with the width, height and placeholder background, along with the given attrs as key value pairs (ex. "alt", "A cat")
+func (r *ResponsiveImage) PictureHTML(attrs ...string) (string, error) {
+	if len(attrs)%2 != 0 {
+		return "", fmt.Errorf("attrs need to match keys with values: %v", attrs)
+	}
+
+	lines := []string{"<picture>"}
+	for _, source := range r.Sources {
+		lines = append(lines, fmt.Sprintf(`<source type="%v" srcset="%v">`, escapeAttr(source.Type), escapeAttr(source.SrcSet)))
+	}
+
+	imgAttrs := []string{r.HTMLAttrs()}
+	if r.Width > 0 && r.Height > 0 {
+		imgAttrs = append(imgAttrs, fmt.Sprintf(`width="%v" height="%v"`, r.Width, r.Height))
+	}
+	if style := r.placeholderStyle(); style != "" {
+		imgAttrs = append(imgAttrs, fmt.Sprintf(`style="%v"`, escapeAttr(style)))
+	}
+	for i := 0; i < len(attrs); i += 2 {
+		imgAttrs = append(imgAttrs, fmt.Sprintf(`%v="%v"`, escapeAttr(attrs[i]), escapeAttr(attrs[i+1])))
+	}
+	lines = append(lines, fmt.Sprintf("<img %v>", strings.Join(imgAttrs, " ")), "</picture>")
+	return strings.Join(lines, "\n"), nil
+}
+
+func (r *ResponsiveImage) placeholderStyle() string {
+	var styles []string
+	if r.DominantColor != "" {
+		styles = append(styles, "background-color: "+r.DominantColor)
+	}
+	if r.Placeholder != "" {
+		styles = append(styles, fmt.Sprintf("background-image: url(%v); background-size: cover", r.Placeholder))
+	}
+	return strings.Join(styles, "; ")
+}
+
+func escapeAttr(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
 func prependSrcPath(prefix, src string) string {
 	if src == "" {
 		return ""
@@ -25,57 +25,57 @@ func TestResponsiveImage_ChangeSrcPrefix(t *testing.T) {
 		safeLog bool
 	}{
 		{
-			&ResponsiveImage{"", ""},
-			&ResponsiveImage{"", ""},
+			&ResponsiveImage{Src: "", SrcSet: ""},
+			&ResponsiveImage{Src: "", SrcSet: ""},
 			true,
 		},
 		{
-			&ResponsiveImage{"blah.png", ""},
-			&ResponsiveImage{placeholder + "blah.png", ""},
+			&ResponsiveImage{Src: "blah.png", SrcSet: ""},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: ""},
 			true,
 		},
 		{
-			&ResponsiveImage{"blah.png", "blah-125.png 125w"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w"},
+			&ResponsiveImage{Src: "blah.png", SrcSet: "blah-125.png 125w"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w"},
 			true,
 		},
 		{
-			&ResponsiveImage{"blah.png", "blah-125.png 125w, blah-125.png 250w, blah-125.png 125w, blah-500.png 500w"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
+			&ResponsiveImage{Src: "blah.png", SrcSet: "blah-125.png 125w, blah-125.png 250w, blah-125.png 125w, blah-500.png 500w"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
 			true,
 		},
 		{
-			&ResponsiveImage{"content/images/blah.png", ""},
-			&ResponsiveImage{placeholder + "blah.png", ""},
+			&ResponsiveImage{Src: "content/images/blah.png", SrcSet: ""},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: ""},
 			true,
 		},
 		{
-			&ResponsiveImage{"content/images/blah.png", "content/images/blah-125.png 125w"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w"},
+			&ResponsiveImage{Src: "content/images/blah.png", SrcSet: "content/images/blah-125.png 125w"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w"},
 			true,
 		},
 		{
-			&ResponsiveImage{"content/images/blah.png", "content/images/blah-125.png 125w, content/images/blah-125.png 250w, content/images/blah-125.png 125w, content/images/blah-500.png 500w"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
+			&ResponsiveImage{Src: "content/images/blah.png", SrcSet: "content/images/blah-125.png 125w, content/images/blah-125.png 250w, content/images/blah-125.png 125w, content/images/blah-500.png 500w"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
 			true,
 		},
 		{
-			&ResponsiveImage{"content/images/blah.png", "content/images/blah-125.png 125w,"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w"},
+			&ResponsiveImage{Src: "content/images/blah.png", SrcSet: "content/images/blah-125.png 125w,"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w"},
 			false,
 		}, {
-			&ResponsiveImage{"content/images/blah.png", ",content/images/blah-125.png 125w,,"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w"},
+			&ResponsiveImage{Src: "content/images/blah.png", SrcSet: ",content/images/blah-125.png 125w,,"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w"},
 			false,
 		},
 		{
-			&ResponsiveImage{"content/images/blah.png", "content/images/blah-125.png 125w, content/images/blah-125.png 250w, content/images/blah-125.png 125w, content/images/blah-500.png 500w,"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
+			&ResponsiveImage{Src: "content/images/blah.png", SrcSet: "content/images/blah-125.png 125w, content/images/blah-125.png 250w, content/images/blah-125.png 125w, content/images/blah-500.png 500w,"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
 			false,
 		},
 		{
-			&ResponsiveImage{"content/images/blah.png", "content/images/blah-125.png 125w, , content/images/blah-125.png 250w, , content/images/blah-125.png 125w, content/images/blah-500.png 500w,"},
-			&ResponsiveImage{placeholder + "blah.png", placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
+			&ResponsiveImage{Src: "content/images/blah.png", SrcSet: "content/images/blah-125.png 125w, , content/images/blah-125.png 250w, , content/images/blah-125.png 125w, content/images/blah-500.png 500w,"},
+			&ResponsiveImage{Src: placeholder + "blah.png", SrcSet: placeholder + "blah-125.png 125w, " + placeholder + "blah-125.png 250w, " + placeholder + "blah-125.png 125w, " + placeholder + "blah-500.png 500w"},
 			false,
 		},
 	}
@@ -124,24 +124,24 @@ func TestResponsiveImage_HtmlAttrs(t *testing.T) {
 		exp string
 	}{
 		{
-			&ResponsiveImage{"", ""},
+			&ResponsiveImage{Src: "", SrcSet: ""},
 			"",
 		},
 		{
-			&ResponsiveImage{"blah.png", ""},
+			&ResponsiveImage{Src: "blah.png", SrcSet: ""},
 			`src="blah.png"`,
 		},
 
 		{
-			&ResponsiveImage{"blah.png", "blah-125.png 125w"},
+			&ResponsiveImage{Src: "blah.png", SrcSet: "blah-125.png 125w"},
 			`src="blah.png" srcset="blah-125.png 125w"`,
 		},
 		{
-			&ResponsiveImage{"", "blah-125.png 125w"},
+			&ResponsiveImage{Src: "", SrcSet: "blah-125.png 125w"},
 			`srcset="blah-125.png 125w"`,
 		},
 		{
-			&ResponsiveImage{"blah.png", "blah-125.png 125w, blah-125.png 250w, blah-125.png 125w, blah-500.png 500w"},
+			&ResponsiveImage{Src: "blah.png", SrcSet: "blah-125.png 125w, blah-125.png 250w, blah-125.png 125w, blah-500.png 500w"},
 			`src="blah.png" srcset="blah-125.png 125w, blah-125.png 250w, blah-125.png 125w, blah-500.png 500w"`,
 		},
 	}
@@ -154,3 +154,84 @@ func TestResponsiveImage_HtmlAttrs(t *testing.T) {
 		context.Assert("Result", tc.img.HTMLAttrs(), tc.exp)
 	}
 }
+
+func TestResponsiveImage_PrependSrcPath_Sources(t *testing.T) {
+	log, hook := logTest.NewNullLogger()
+	img := &ResponsiveImage{
+		Src:    "blah.png",
+		SrcSet: "blah-125.png 125w",
+		Sources: []*ResponsiveSource{
+			{"image/webp", "blah-125.webp 125w, blah-250.webp 250w"},
+			{"image/avif", "blah-125.avif 125w"},
+		},
+	}
+	img.PrependSrcPath("assets", log)
+
+	test.AssertArray(t, "result", img, &ResponsiveImage{
+		Src:    "assets/blah.png",
+		SrcSet: "assets/blah-125.png 125w",
+		Sources: []*ResponsiveSource{
+			{"image/webp", "assets/blah-125.webp 125w, assets/blah-250.webp 250w"},
+			{"image/avif", "assets/blah-125.avif 125w"},
+		},
+	})
+	test.AssertLabel(t, "test.SafeLogEntries(hook)", test.SafeLogEntries(hook), true)
+}
+
+func TestResponsiveImage_PictureHTML(t *testing.T) {
+	testCases := []struct {
+		img   *ResponsiveImage
+		attrs []string
+		exp   string
+		err   bool
+	}{
+		{
+			&ResponsiveImage{Src: "blah.png"},
+			nil,
+			"<picture>\n<img src=\"blah.png\">\n</picture>",
+			false,
+		},
+		{
+			&ResponsiveImage{Src: "blah.png", SrcSet: "blah-125.png 125w", Width: 125, Height: 50},
+			[]string{"alt", `A "cat"`, "class", "hero"},
+			"<picture>\n" +
+				`<img src="blah.png" srcset="blah-125.png 125w" width="125" height="50" alt="A &#34;cat&#34;" class="hero">` +
+				"\n</picture>",
+			false,
+		},
+		{
+			&ResponsiveImage{
+				Src:           "blah.png",
+				SrcSet:        "blah-125.png 125w",
+				Sources:       []*ResponsiveSource{{"image/avif", "blah-125.avif 125w"}, {"image/webp", "blah-125.webp 125w"}},
+				DominantColor: "#aabbcc",
+				Placeholder:   "data:image/png;base64,AAAA",
+			},
+			nil,
+			"<picture>\n" +
+				`<source type="image/avif" srcset="blah-125.avif 125w">` + "\n" +
+				`<source type="image/webp" srcset="blah-125.webp 125w">` + "\n" +
+				`<img src="blah.png" srcset="blah-125.png 125w" style="background-color: #aabbcc; background-image: url(data:image/png;base64,AAAA); background-size: cover">` +
+				"\n</picture>",
+			false,
+		},
+		{
+			&ResponsiveImage{Src: "blah.png"},
+			[]string{"alt"},
+			"",
+			true,
+		},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"img":   tc.img,
+			"attrs": tc.attrs,
+		})
+
+		got, err := tc.img.PictureHTML(tc.attrs...)
+		context.Assert("err != nil", err != nil, tc.err)
+		context.Assert("result", got, tc.exp)
+	}
+}
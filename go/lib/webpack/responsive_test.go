@@ -20,6 +20,9 @@ func TestHasResponsiveExt(t *testing.T) {
 	}{
 		{"test.jpg", true},
 		{"test.png", true},
+		{"test.jpeg", true},
+		{"test.webp", true},
+		{"test.avif", true},
 		{"test.gif", false},
 		{"test.svg", false},
 	}
@@ -1,21 +1,47 @@
 package webpack
 
 import (
-	"os"
+	"fmt"
 )
 
 // Settings is the settings of this package
 type Settings struct {
-	AssetsPath string `json:"assets_path,omitempty"`
+	AssetsPath string `json:"assets_path,omitempty" env:"ASSETS_PATH"`
+	// Images generates the responsive images in Go, for sites without the Webpack image loader
+	Images *ImageSettings `json:"images,omitempty"`
+	// Strict makes the template functions return errors for asset keys not in the manifest, failing the render,
+	// instead of logging the error and using the key
+	Strict bool `json:"strict,omitempty"`
+	// CrossOrigin is the crossorigin attr of the tags with integrity, "anonymous" or "use-credentials".
+	// If empty, it's omitted for the assets of the same origin, while the assets of another origin (ex. an asset host)
+	// and the fonts are given "anonymous", as browsers refuse to run them with integrity otherwise.
+	CrossOrigin string `json:"crossorigin,omitempty"`
+	// Preload are the manifest keys of the critical assets, given <link rel="preload"> tags by `webpackPreloads`
+	Preload []string `json:"preload,omitempty"`
+	// CriticalCSS inlines the critical CSS of the manifest stylesheets into the generated HTML pages, see Webpack.CriticalCSSAround
+	CriticalCSS bool `json:"critical_css,omitempty"`
 }
 
-// DefaultSettings returns the default settings of this package
-func DefaultSettings() *Settings {
-	assetsPath := os.Getenv("ASSETS_PATH")
-	if assetsPath == "" {
-		assetsPath = "assets"
+// Validate returns an error if the settings are invalid
+func (s *Settings) Validate() error {
+	if s.AssetsPath == "" {
+		return fmt.Errorf("assets_path is required")
+	}
+	if s.CrossOrigin != "" && s.CrossOrigin != "anonymous" && s.CrossOrigin != "use-credentials" {
+		return fmt.Errorf("crossorigin must be anonymous, use-credentials or empty: %v", s.CrossOrigin)
 	}
+	return nil
+}
+
+// DefaultSettings returns the default settings of this package.
+// The ASSETS_PATH environment variable is read by app.LoadSettings and app.SettingsFromFile, not here.
+func DefaultSettings() *Settings {
 	return &Settings{
-		assetsPath,
+		"assets",
+		DefaultImageSettings(),
+		false,
+		"anonymous",
+		nil,
+		false,
 	}
 }
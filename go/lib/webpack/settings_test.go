@@ -1,13 +1,17 @@
 package webpack
 
 import (
+	"os"
 	"testing"
 
-	"github.com/s12chung/gostatic/go/test/settings"
+	"github.com/s12chung/gostatic/go/test"
 )
 
 func TestDefaultSettings(t *testing.T) {
-	settings.EnvSetting(t, "ASSETS_PATH", "assets", func() string {
-		return DefaultSettings().AssetsPath
-	})
+	test.AssertError(t, os.Setenv("ASSETS_PATH", "env_assets"), "os.Setenv")
+	defer func() {
+		test.AssertError(t, os.Unsetenv("ASSETS_PATH"), "os.Unsetenv")
+	}()
+	// the environment is read by app.LoadSettings and app.SettingsFromFile
+	test.AssertLabel(t, "AssetsPath", DefaultSettings().AssetsPath, "assets")
 }
@@ -0,0 +1,171 @@
+package webpack
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// preloadAs maps the extensions to the `as` attr of <link rel="preload">
+var preloadAs = map[string]string{
+	".js":    "script",
+	".mjs":   "script",
+	".css":   "style",
+	".woff2": "font",
+	".woff":  "font",
+	".ttf":   "font",
+	".otf":   "font",
+	".png":   "image",
+	".jpg":   "image",
+	".jpeg":  "image",
+	".gif":   "image",
+	".svg":   "image",
+	".webp":  "image",
+	".avif":  "image",
+}
+
+// Integrity returns the Subresource Integrity (SRI) hash (ex. "sha384-...") of the generated file of the manifest key.
+// The error is always returned, as the hash can't fallback.
+func (w *Webpack) Integrity(key string) (string, error) {
+	value, err := w.manifest.ManifestValue(key)
+	if err != nil {
+		return "", err
+	}
+	return w.integrity.Hash(value)
+}
+
+// ScriptTag returns the <script> tag of the manifest key with the integrity and crossorigin attrs,
+// along with the given attrs as key value pairs (ex. "defer", ""), which override the attrs of the same key.
+// The errors are returned if Settings.Strict, otherwise they're logged and the attrs are skipped.
+func (w *Webpack) ScriptTag(key string, attrs ...string) (template.HTML, error) {
+	assetAttrs, err := w.assetAttrs(key, "src", false)
+	if err != nil {
+		return "", err
+	}
+	html, err := tagHTML("script", assetAttrs, attrs)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(html + "</script>"), nil
+}
+
+// StylesheetTag returns the <link rel="stylesheet"> tag of the manifest key, see ScriptTag
+func (w *Webpack) StylesheetTag(key string, attrs ...string) (template.HTML, error) {
+	assetAttrs, err := w.assetAttrs(key, "href", false)
+	if err != nil {
+		return "", err
+	}
+	html, err := tagHTML("link", append([]string{"rel", "stylesheet"}, assetAttrs...), attrs)
+	return template.HTML(html), err
+}
+
+// PreloadTag returns the <link rel="preload"> tag of the manifest key, with the `as` attr from the extension, see ScriptTag.
+// Fonts are always given the crossorigin attr, as browsers fetch them in CORS mode.
+func (w *Webpack) PreloadTag(key string, attrs ...string) (template.HTML, error) {
+	as, has := preloadAs[strings.ToLower(path.Ext(key))]
+	if !has && !hasAttrKey(attrs, "as") {
+		return "", fmt.Errorf("no preload `as` attr for the extension of %v, give it in the attrs", key)
+	}
+
+	assetAttrs, err := w.assetAttrs(key, "href", as == "font")
+	if err != nil {
+		return "", err
+	}
+	html, err := tagHTML("link", append([]string{"rel", "preload", "as", as}, assetAttrs...), attrs)
+	return template.HTML(html), err
+}
+
+// PreloadTags returns the PreloadTag of each Settings.Preload key, place them early in the <head>
+func (w *Webpack) PreloadTags() (template.HTML, error) {
+	tags := make([]string, len(w.settings.Preload))
+	for i, key := range w.settings.Preload {
+		tag, err := w.PreloadTag(key)
+		if err != nil {
+			return "", err
+		}
+		tags[i] = string(tag)
+	}
+	return template.HTML(strings.Join(tags, "\n")), nil
+}
+
+// assetAttrs returns the URL, integrity and crossorigin attrs of the key as key value pairs,
+// skipping the integrity and crossorigin if the manifest or file can't be read and not Settings.Strict.
+// The integrity errors of URLs of another origin are always returned, as the tags would silently skip the integrity check.
+func (w *Webpack) assetAttrs(key, urlAttr string, forceCrossOrigin bool) ([]string, error) {
+	value, err := w.manifest.ManifestValue(key)
+	if err != nil {
+		return []string{urlAttr, w.manifest.assetURL(key)}, w.strictError(err)
+	}
+	assetURL := w.manifest.assetURL(value)
+	attrs := []string{urlAttr, assetURL}
+	crossOriginURL := isCrossOriginURL(assetURL)
+
+	integrity, err := w.integrity.Hash(value)
+	if err != nil {
+		err = fmt.Errorf("error computing integrity of %v - %v", key, err)
+		if crossOriginURL {
+			return attrs, err
+		}
+		return attrs, w.strictError(err)
+	}
+	attrs = append(attrs, "integrity", integrity)
+
+	if crossOrigin := w.crossOrigin(forceCrossOrigin || crossOriginURL); crossOrigin != "" {
+		attrs = append(attrs, "crossorigin", crossOrigin)
+	}
+	return attrs, nil
+}
+
+// crossOrigin returns Settings.CrossOrigin, or "anonymous" if it's empty and forced
+func (w *Webpack) crossOrigin(force bool) string {
+	if w.settings.CrossOrigin == "" && force {
+		return "anonymous"
+	}
+	return w.settings.CrossOrigin
+}
+
+// isCrossOriginURL returns true if the URL has a host (ex. "https://cdn.example.com/a.js"), as the asset host
+// of the urls is the only way the asset URLs get one
+func isCrossOriginURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Host != ""
+}
+
+// tagHTML returns the opening tag with the attrs, both key value pairs, where the overrides replace the attrs of the same key
+func tagHTML(tag string, attrs, overrides []string) (string, error) {
+	if len(overrides)%2 != 0 {
+		return "", fmt.Errorf("attrs need to match keys with values: %v", overrides)
+	}
+
+	overrideMap := map[string]string{}
+	for i := 0; i < len(overrides); i += 2 {
+		overrideMap[overrides[i]] = overrides[i+1]
+	}
+
+	htmlAttrs := []string{tag}
+	for i := 0; i < len(attrs); i += 2 {
+		value, has := overrideMap[attrs[i]]
+		if !has {
+			value = attrs[i+1]
+		}
+		htmlAttrs = append(htmlAttrs, fmt.Sprintf(`%v="%v"`, escapeAttr(attrs[i]), escapeAttr(value)))
+	}
+	for i := 0; i < len(overrides); i += 2 {
+		if !hasAttrKey(attrs, overrides[i]) {
+			htmlAttrs = append(htmlAttrs, fmt.Sprintf(`%v="%v"`, escapeAttr(overrides[i]), escapeAttr(overrides[i+1])))
+		}
+	}
+	return "<" + strings.Join(htmlAttrs, " ") + ">", nil
+}
+
+// hasAttrKey returns true if the key value pairs have the key
+func hasAttrKey(attrs []string, key string) bool {
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i] == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package webpack
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	logTest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/s12chung/gostatic/go/lib/urls"
+	"github.com/s12chung/gostatic/go/test"
+)
+
+const vendorIntegrity = "sha384-WB/q3J/afHwzaDqIadENWJtsF80Y8uFQx01ITApnl3nWi455Y762kZBgmn1sScvL"
+const mainIntegrity = "sha384-YLZ7hFcT7PbOCzXieymmlorgaVLtggYiChPljI46oXxrgkZqICvgIxbogRLS5t6p"
+const fontIntegrity = "sha384-S3fIiVERLVzV27O4ScbL7BjI3j+V5ESj0MvZIGneB6cGwCyZ67elbQHGFlBCEAR9"
+
+func TestWebpack_Integrity(t *testing.T) {
+	webpack, _ := defaultWebpack()
+	got, err := webpack.Integrity("vendor.css")
+	test.AssertError(t, err, "webpack.Integrity")
+	test.AssertLabel(t, "result", got, vendorIntegrity)
+
+	_, err = webpack.Integrity("missing.css")
+	test.AssertLabel(t, "missing err != nil", err != nil, true)
+	_, err = webpack.Integrity("nofile.js")
+	test.AssertLabel(t, "nofile err != nil", err != nil, true)
+}
+
+func TestWebpack_Tags(t *testing.T) {
+	testCases := []struct {
+		tag         string
+		key         string
+		attrs       []string
+		crossOrigin string
+		exp         string
+	}{
+		{"script", "main.js", nil, "anonymous",
+			`<script src="assets/main-0a1b2c3d4e5f6a7b8c9d.js" integrity="` + mainIntegrity + `" crossorigin="anonymous"></script>`},
+		{"script", "main.js", []string{"defer", "", "crossorigin", "use-credentials"}, "anonymous",
+			`<script src="assets/main-0a1b2c3d4e5f6a7b8c9d.js" integrity="` + mainIntegrity + `" crossorigin="use-credentials" defer=""></script>`},
+		{"script", "main.js", nil, "",
+			`<script src="assets/main-0a1b2c3d4e5f6a7b8c9d.js" integrity="` + mainIntegrity + `"></script>`},
+		{"stylesheet", "vendor.css", []string{"media", "print"}, "anonymous",
+			`<link rel="stylesheet" href="assets/vendor-32267303b2484ed8b3aa.css" integrity="` + vendorIntegrity + `" crossorigin="anonymous" media="print">`},
+		{"preload", "vendor.css", nil, "anonymous",
+			`<link rel="preload" as="style" href="assets/vendor-32267303b2484ed8b3aa.css" integrity="` + vendorIntegrity + `" crossorigin="anonymous">`},
+		{"preload", "fonts/inter.woff2", []string{"type", "font/woff2"}, "",
+			`<link rel="preload" as="font" href="assets/fonts/inter-1f2e3d4c5b6a7f8e9d0c.woff2" integrity="` + fontIntegrity + `" crossorigin="anonymous" type="font/woff2">`},
+		{"preload", "main.js", []string{"as", "fetch"}, "",
+			`<link rel="preload" as="fetch" href="assets/main-0a1b2c3d4e5f6a7b8c9d.js" integrity="` + mainIntegrity + `">`},
+		{"script", "main.js", []string{"defer"}, "", ""},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"tag":   tc.tag,
+			"key":   tc.key,
+			"attrs": tc.attrs,
+		})
+
+		webpack, hook := defaultWebpack()
+		webpack.settings.CrossOrigin = tc.crossOrigin
+		tagFunc := map[string]func(string, ...string) (template.HTML, error){
+			"script":     webpack.ScriptTag,
+			"stylesheet": webpack.StylesheetTag,
+			"preload":    webpack.PreloadTag,
+		}[tc.tag]
+
+		got, err := tagFunc(tc.key, tc.attrs...)
+		context.Assert("err != nil", err != nil, tc.exp == "")
+		context.Assert("result", string(got), tc.exp)
+		context.Assert("test.SafeLogEntries(hook)", test.SafeLogEntries(hook), true)
+	}
+}
+
+func TestWebpack_Tags_AssetHost(t *testing.T) {
+	cdn := "https://cdn.example.com/assets/"
+	testCases := []struct {
+		tag         string
+		key         string
+		crossOrigin string
+		exp         string
+	}{
+		{"script", "main.js", "",
+			`<script src="` + cdn + `main-0a1b2c3d4e5f6a7b8c9d.js" integrity="` + mainIntegrity + `" crossorigin="anonymous"></script>`},
+		{"script", "main.js", "use-credentials",
+			`<script src="` + cdn + `main-0a1b2c3d4e5f6a7b8c9d.js" integrity="` + mainIntegrity + `" crossorigin="use-credentials"></script>`},
+		{"stylesheet", "vendor.css", "",
+			`<link rel="stylesheet" href="` + cdn + `vendor-32267303b2484ed8b3aa.css" integrity="` + vendorIntegrity + `" crossorigin="anonymous">`},
+		{"preload", "vendor.css", "",
+			`<link rel="preload" as="style" href="` + cdn + `vendor-32267303b2484ed8b3aa.css" integrity="` + vendorIntegrity + `" crossorigin="anonymous">`},
+		// the integrity can't be skipped, even if not Settings.Strict
+		{"script", "nofile.js", "", ""},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index":       testCaseIndex,
+			"tag":         tc.tag,
+			"key":         tc.key,
+			"crossOrigin": tc.crossOrigin,
+		})
+
+		log, hook := logTest.NewNullLogger()
+		settings := DefaultSettings()
+		settings.CrossOrigin = tc.crossOrigin
+		webpack := NewWebpack(generatedPath, settings, urls.NewURLs(&urls.Settings{AssetHost: "https://cdn.example.com"}), log)
+		tagFunc := map[string]func(string, ...string) (template.HTML, error){
+			"script":     webpack.ScriptTag,
+			"stylesheet": webpack.StylesheetTag,
+			"preload":    webpack.PreloadTag,
+		}[tc.tag]
+
+		got, err := tagFunc(tc.key)
+		context.Assert("err != nil", err != nil, tc.exp == "")
+		context.Assert("result", string(got), tc.exp)
+		context.Assert("len(hook.AllEntries())", len(hook.AllEntries()), 0)
+	}
+}
+
+func TestWebpack_PreloadTag_UnknownExt(t *testing.T) {
+	webpack, _ := defaultWebpack()
+	_, err := webpack.PreloadTag("data.bin")
+	test.AssertLabel(t, "err != nil", err != nil, true)
+}
+
+func TestWebpack_PreloadTags(t *testing.T) {
+	webpack, _ := defaultWebpack()
+	webpack.settings.Preload = []string{"vendor.css", "main.js"}
+
+	got, err := webpack.PreloadTags()
+	test.AssertError(t, err, "webpack.PreloadTags")
+	exp := strings.Join([]string{
+		`<link rel="preload" as="style" href="assets/vendor-32267303b2484ed8b3aa.css" integrity="` + vendorIntegrity + `" crossorigin="anonymous">`,
+		`<link rel="preload" as="script" href="assets/main-0a1b2c3d4e5f6a7b8c9d.js" integrity="` + mainIntegrity + `" crossorigin="anonymous">`,
+	}, "\n")
+	test.AssertLabel(t, "result", string(got), exp)
+}
+
+func TestWebpack_Tags_Strict(t *testing.T) {
+	testCases := []struct {
+		key string
+		exp string
+	}{
+		{"missing.js", `<script src="assets/missing.js"></script>`},
+		{"nofile.js", `<script src="assets/nofile-9f8e7d6c5b4a39281706.js"></script>`},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		for _, strict := range []bool{false, true} {
+			context := test.NewContext(t).SetFields(test.ContextFields{
+				"index":  testCaseIndex,
+				"key":    tc.key,
+				"strict": strict,
+			})
+
+			webpack, hook := defaultWebpack()
+			webpack.settings.Strict = strict
+			got, err := webpack.ScriptTag(tc.key)
+			if strict {
+				context.Assert("err != nil", err != nil, true)
+				context.Assert("len(hook.AllEntries())", len(hook.AllEntries()), 0)
+				continue
+			}
+			context.AssertError(err, "webpack.ScriptTag")
+			context.Assert("result", string(got), tc.exp)
+			context.Assert("test.SafeLogEntries(hook)", test.SafeLogEntries(hook), false)
+		}
+	}
+}
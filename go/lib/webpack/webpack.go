@@ -1,5 +1,9 @@
 /*
 Package webpack lets Go see into the generated asset paths, `Manifest.json`, and `images/responsive` folder of JSON files from Webpack.
+The responsive images can also be generated in Go by the ImageGenerator, for sites without Webpack.
+The ImageGenerator keeps the format of the source images, so the other formats of the ResponsiveImage Sources
+(ex. WebP, AVIF) only come from the responsive JSON of the Webpack loader.
+The asset tags are given Subresource Integrity (SRI) hashes of the generated files by Integrity.
 
 Webpack struct implements github.com/s12chung/gostatic/go/lib/router/html.Plugin
 */
@@ -9,37 +13,44 @@ import (
 	"fmt"
 	"html/template"
 	"net/url"
-	"path"
 	"path/filepath"
-	"regexp"
-	"strings"
 
 	"github.com/sirupsen/logrus"
-)
 
-var imgRegex = regexp.MustCompile(`<img[^>]+(src="([^"]*)")`)
+	"github.com/s12chung/gostatic/go/lib/filecache"
+	"github.com/s12chung/gostatic/go/lib/urls"
+)
 
 // Webpack represents of a webpack generated setup
 type Webpack struct {
 	generatedPath string
 	settings      *Settings
+	urls          *urls.URLs
 	manifest      *Manifest
 	responsive    *Responsive
+	images        *ImageGenerator
+	integrity     *Integrity
+	stylesheets   *filecache.Files
 	log           logrus.FieldLogger
 }
 
-// NewWebpack returns a new instance of Webpack
-func NewWebpack(generatedPath string, settings *Settings, log logrus.FieldLogger) *Webpack {
+// NewWebpack returns a new instance of Webpack, the urls give the asset host and base path of the asset URLs
+func NewWebpack(generatedPath string, settings *Settings, u *urls.URLs, log logrus.FieldLogger) *Webpack {
 	return &Webpack{
 		generatedPath,
 		settings,
-		NewManifest(generatedPath, settings.AssetsPath, log),
+		u,
+		NewManifest(generatedPath, settings.AssetsPath, u, log),
 		NewResponsive(generatedPath, settings.AssetsPath, log),
+		NewImageGenerator(generatedPath, settings.AssetsPath, settings.Images, log),
+		NewIntegrity(filepath.Join(generatedPath, settings.AssetsPath)),
+		newStylesheetCache(),
 		log,
 	}
 }
 
-// AssetsURL returns the URL path prefix of all your assets
+// AssetsURL returns the URL path prefix of all your assets, the pattern for router.WebRouter.FileServe.
+// It's without the base path, which router.WebRouter.SetBasePath strips from the request URLs.
 func (w *Webpack) AssetsURL() string {
 	return fmt.Sprintf("/%v/", w.settings.AssetsPath)
 }
@@ -49,13 +60,39 @@ func (w *Webpack) GeneratedAssetsPath() string {
 	return filepath.Join(w.generatedPath, w.settings.AssetsPath)
 }
 
-// ManifestURL calls Manifest.ManifestURL
+// ManifestURL returns the manifest URL of the key, see Manifest.ManifestURL
 func (w *Webpack) ManifestURL(key string) string {
 	return w.manifest.ManifestURL(key)
 }
 
-func (w *Webpack) manifestImage(originalSrc string) *ResponsiveImage {
-	return &ResponsiveImage{Src: w.ManifestURL(originalSrc)}
+// manifestURL returns the manifest URL of the key, the error is returned if Settings.Strict, otherwise it's logged.
+// The URL of the key is always returned.
+func (w *Webpack) manifestURL(key string) (string, error) {
+	value, err := w.manifest.ManifestValue(key)
+	if err != nil {
+		value = key
+	}
+	return w.manifest.assetURL(value), w.strictError(err)
+}
+
+// strictError returns the err if Settings.Strict, otherwise it's logged and nil is returned
+func (w *Webpack) strictError(err error) error {
+	if err == nil || w.settings.Strict {
+		return err
+	}
+	w.log.Error(err)
+	return nil
+}
+
+func (w *Webpack) manifestImage(originalSrc string) (*ResponsiveImage, error) {
+	src, err := w.manifestURL(originalSrc)
+	return &ResponsiveImage{Src: src}, err
+}
+
+// GenerateImages calls ImageGenerator.GenerateAll, so the responsive images exist without the Webpack image loader.
+// It does nothing if ImageSettings.SourcePath is not set. Call it before generating or hosting.
+func (w *Webpack) GenerateImages() error {
+	return w.images.GenerateAll()
 }
 
 // GetResponsiveImage returns the struct representation of a *ResponsiveImage given a originalSrc.
@@ -66,9 +103,18 @@ func (w *Webpack) manifestImage(originalSrc string) *ResponsiveImage {
 // - the result of using originalSrc as a key for webpack.ManifestURL
 // - the given originalSrc
 func (w *Webpack) GetResponsiveImage(originalSrc string) *ResponsiveImage {
+	responsiveImage, err := w.getResponsiveImage(originalSrc)
+	if err != nil {
+		w.log.Error(err)
+	}
+	return responsiveImage
+}
+
+// getResponsiveImage is GetResponsiveImage, returning the manifest error if Settings.Strict
+func (w *Webpack) getResponsiveImage(originalSrc string) (*ResponsiveImage, error) {
 	u, err := url.Parse(originalSrc)
 	if err == nil && u.Hostname() != "" {
-		return &ResponsiveImage{Src: originalSrc}
+		return &ResponsiveImage{Src: originalSrc}, nil
 	}
 
 	if !HasResponsiveExt(originalSrc) {
@@ -78,27 +124,8 @@ func (w *Webpack) GetResponsiveImage(originalSrc string) *ResponsiveImage {
 	if responsiveImage == nil {
 		return w.manifestImage(originalSrc)
 	}
-	return responsiveImage
-}
-
-// ReplaceResponsiveAttrs replaces the img.src HTML attrs, to responsive img.src and img.srcset attrs
-// within the HTML string. It takes the existing img.src values as the originalSrc to
-// call webpack.GetResponsiveImage.
-//
-// You may add a srcPrefix to the img.src, so webpack.GetResponsiveImage can work.
-func (w *Webpack) ReplaceResponsiveAttrs(srcPrefix, html string) string {
-	return imgRegex.ReplaceAllStringFunc(html, func(imgTag string) string {
-		matches := imgRegex.FindStringSubmatch(imgTag)
-		originalSrc := matches[2]
-
-		u, err := url.Parse(originalSrc)
-		if err == nil && u.Hostname() != "" {
-			return imgTag
-		}
-
-		responsiveImage := w.GetResponsiveImage(path.Join(srcPrefix, originalSrc))
-		return strings.Replace(imgTag, matches[1], responsiveImage.HTMLAttrs(), 1)
-	})
+	responsiveImage.MapSrcs(w.urls.AssetURL, w.log)
+	return responsiveImage, nil
 }
 
 // ResponsiveHTMLAttrs calls GetResponsiveImage and returns the html attr (img.src and img.srcset) representation of the *ResponsiveImage
@@ -107,11 +134,38 @@ func (w *Webpack) ResponsiveHTMLAttrs(originalSrc string) template.HTMLAttr {
 	return template.HTMLAttr(responsiveImage.HTMLAttrs())
 }
 
-// TemplateFuncs implements github.com/s12chung/gostatic/go/lib/router/html.Plugin
+func (w *Webpack) responsiveHTMLAttrs(originalSrc string) (template.HTMLAttr, error) {
+	responsiveImage, err := w.getResponsiveImage(originalSrc)
+	if err != nil {
+		return "", err
+	}
+	return template.HTMLAttr(responsiveImage.HTMLAttrs()), nil
+}
+
+// ResponsivePicture calls GetResponsiveImage and returns the <picture> HTML of the *ResponsiveImage,
+// with the attrs as key value pairs for the <img>, see ResponsiveImage.PictureHTML.
+// The manifest error is returned if Settings.Strict.
+func (w *Webpack) ResponsivePicture(originalSrc string, attrs ...string) (template.HTML, error) {
+	responsiveImage, err := w.getResponsiveImage(originalSrc)
+	if err != nil {
+		return "", err
+	}
+	html, err := responsiveImage.PictureHTML(attrs...)
+	return template.HTML(html), err
+}
+
+// TemplateFuncs implements github.com/s12chung/gostatic/go/lib/router/html.Plugin,
+// with Settings.Strict, the functions return the errors of asset keys not in the manifest, failing the render
 func (w *Webpack) TemplateFuncs() template.FuncMap {
 	return template.FuncMap{
-		"webpackURL":             w.ManifestURL,
-		"responsiveAttrs":        w.ResponsiveHTMLAttrs,
-		"replaceResponsiveAttrs": w.ReplaceResponsiveAttrs,
+		"webpackURL":             w.manifestURL,
+		"responsiveAttrs":        w.responsiveHTMLAttrs,
+		"replaceResponsiveAttrs": w.replaceResponsiveAttrs,
+		"responsivePicture":      w.ResponsivePicture,
+		"webpackIntegrity":       w.Integrity,
+		"webpackScript":          w.ScriptTag,
+		"webpackStylesheet":      w.StylesheetTag,
+		"webpackPreload":         w.PreloadTag,
+		"webpackPreloads":        w.PreloadTags,
 	}
 }
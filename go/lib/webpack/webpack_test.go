@@ -2,12 +2,14 @@ package webpack
 
 import (
 	"fmt"
+	"html/template"
 	"path"
 	"strings"
 	"testing"
 
 	logTest "github.com/sirupsen/logrus/hooks/test"
 
+	"github.com/s12chung/gostatic/go/lib/urls"
 	"github.com/s12chung/gostatic/go/test"
 	"github.com/s12chung/gostatic/go/test/testfile"
 )
@@ -15,18 +17,22 @@ import (
 var generatedPath = path.Join(testfile.FixturePath, "generated")
 
 var jpgResponsiveImage = &ResponsiveImage{
-	"assets/content/images/test-37a65f446db3e9da33606b7eb48721bb-325.jpg",
-	"assets/content/images/test-37a65f446db3e9da33606b7eb48721bb-325.jpg 325w, assets/content/images/test-c9d1dad468456287c20a476ade8a4d3f-750.jpg 750w, assets/content/images/test-be268849aa760a62798817c27db7c430-1500.jpg 1500w, assets/content/images/test-38e5ee006bf91e6af6d508bce2a9da4c-3000.jpg 3000w, assets/content/images/test-84800b3286f76133d1592c9e68fa10be-4000.jpg 4000w",
+	Src:    "assets/content/images/test-37a65f446db3e9da33606b7eb48721bb-325.jpg",
+	SrcSet: "assets/content/images/test-37a65f446db3e9da33606b7eb48721bb-325.jpg 325w, assets/content/images/test-c9d1dad468456287c20a476ade8a4d3f-750.jpg 750w, assets/content/images/test-be268849aa760a62798817c27db7c430-1500.jpg 1500w, assets/content/images/test-38e5ee006bf91e6af6d508bce2a9da4c-3000.jpg 3000w, assets/content/images/test-84800b3286f76133d1592c9e68fa10be-4000.jpg 4000w",
+	Width:  325,
+	Height: 183,
 }
 var pngResponsiveImage = &ResponsiveImage{
-	"assets/content/images/test-afe607afeab81578d972f0ce9a92bdf4-325.png",
-	"assets/content/images/test-afe607afeab81578d972f0ce9a92bdf4-325.png 325w, assets/content/images/test-d31be3db558b4fe54b2c098abdd96306-750.png 750w, assets/content/images/test-e4b7c37523ea30081ad02f6191b299f6-1440.png 1440w",
+	Src:    "assets/content/images/test-afe607afeab81578d972f0ce9a92bdf4-325.png",
+	SrcSet: "assets/content/images/test-afe607afeab81578d972f0ce9a92bdf4-325.png 325w, assets/content/images/test-d31be3db558b4fe54b2c098abdd96306-750.png 750w, assets/content/images/test-e4b7c37523ea30081ad02f6191b299f6-1440.png 1440w",
+	Width:  325,
+	Height: 135,
 }
 
 func defaultWebpack() (*Webpack, *logTest.Hook) {
 	log, hook := logTest.NewNullLogger()
 	settings := DefaultSettings()
-	return NewWebpack(generatedPath, settings, log), hook
+	return NewWebpack(generatedPath, settings, urls.NewURLs(nil), log), hook
 }
 
 func TestWebpack_AssetsUrl(t *testing.T) {
@@ -49,6 +55,29 @@ func TestWebpack_ManifestUrl(t *testing.T) {
 	test.AssertLabel(t, "Result", got, path.Join(webpack.settings.AssetsPath, "vendor-32267303b2484ed8b3aa.css"))
 }
 
+func TestWebpack_URLs(t *testing.T) {
+	log, hook := logTest.NewNullLogger()
+	u := urls.NewURLs(&urls.Settings{BasePath: "/docs/", AssetHost: "https://cdn.example.com"})
+	webpack := NewWebpack(generatedPath, DefaultSettings(), u, log)
+	cdn := "https://cdn.example.com/docs/assets/"
+
+	test.AssertLabel(t, "AssetsURL", webpack.AssetsURL(), "/assets/")
+	test.AssertLabel(t, "ManifestURL", webpack.ManifestURL("vendor.css"), cdn+"vendor-32267303b2484ed8b3aa.css")
+
+	responsiveImage := webpack.GetResponsiveImage("content/images/test.png")
+	test.AssertLabel(t, "Src", responsiveImage.Src, cdn+"content/images/test-afe607afeab81578d972f0ce9a92bdf4-325.png")
+	test.AssertLabel(t, "SrcSet", responsiveImage.SrcSet, strings.Replace(pngResponsiveImage.SrcSet, "assets/", cdn, -1))
+	test.AssertLabel(t, "external", webpack.GetResponsiveImage("http://testy.com/test.png").Src, "http://testy.com/test.png")
+
+	tag, err := webpack.ScriptTag("main.js", "integrity", "")
+	test.AssertError(t, err, "ScriptTag")
+	test.AssertLabel(t, "ScriptTag", string(tag), `<script src="`+cdn+`main-0a1b2c3d4e5f6a7b8c9d.js" integrity="" crossorigin="anonymous"></script>`)
+
+	test.AssertLabel(t, "ReplaceResponsiveAttrs", webpack.ReplaceResponsiveAttrs("", `<img src="test.gif">`), `<img src="`+cdn+`test.gif">`)
+	test.PrintLogEntries(t, hook)
+	test.AssertLabel(t, "test.SafeLogEntries(hook)", test.SafeLogEntries(hook), true)
+}
+
 func TestWebpack_GetResponsiveImage(t *testing.T) {
 	webpack, hook := defaultWebpack()
 
@@ -93,6 +122,9 @@ func TestWebpack_ReplaceResponsiveAttrs(t *testing.T) {
 		{"test.jpg", "content/images", `<img src="SRC" class="haha"/>`, false, fmt.Sprintf(`<img %v class="haha"/>`, jpgResponsiveImage.HTMLAttrs())},
 		{"test.jpg", "content/images", `<img alt="blah" src="SRC"/>`, false, fmt.Sprintf(`<img alt="blah" %v/>`, jpgResponsiveImage.HTMLAttrs())},
 		{"test.jpg", "content/images", `<img alt="blah" src="SRC" class="haha"/>`, false, fmt.Sprintf(`<img alt="blah" %v class="haha"/>`, jpgResponsiveImage.HTMLAttrs())},
+		{"test.jpg", "content/images", `<img src='SRC'>`, false, fmt.Sprintf(`<img %v>`, jpgResponsiveImage.HTMLAttrs())},
+		{"test.jpg", "content/images", `<IMG Alt=blah Src=SRC>`, false, fmt.Sprintf(`<img alt="blah" %v>`, jpgResponsiveImage.HTMLAttrs())},
+		{"test.jpg", "content/images", `<img src="SRC" srcset="a.jpg 2x"/>`, true, `<img src="test.jpg" srcset="a.jpg 2x"/>`},
 		{"test_again.png", "content/images", `<img src="SRC"/>`, false, `<img src="assets/content/images/test_again-1440.png"/>`},
 		{"test.gif", "", `<img src="SRC"/>`, false, `<img src="assets/test.gif"/>`},
 		{"test.jpg", "doesnt_exist", `<img src="SRC"/>`, false, `<img src="assets/doesnt_exist/test.jpg"/>`},
@@ -139,3 +171,122 @@ func TestWebpack_ResponsiveHtmlAttrs(t *testing.T) {
 		t.Error(test.AssertLabelString("result", got, exp))
 	}
 }
+
+func TestWebpack_ResponsivePicture(t *testing.T) {
+	webpack, hook := defaultWebpack()
+
+	got, err := webpack.ResponsivePicture("content/images/picture.jpg", "alt", "A picture")
+	test.AssertError(t, err, "webpack.ResponsivePicture")
+	exp := strings.Join([]string{
+		"<picture>",
+		`<source type="image/avif" srcset="assets/content/images/picture-3-325.avif 325w, assets/content/images/picture-4-750.avif 750w">`,
+		`<source type="image/webp" srcset="assets/content/images/picture-5-325.webp 325w, assets/content/images/picture-6-750.webp 750w">`,
+		`<img src="assets/content/images/picture-1-325.jpg" srcset="assets/content/images/picture-1-325.jpg 325w, assets/content/images/picture-2-750.jpg 750w" ` +
+			`width="325" height="200" style="background-color: #aabbcc; background-image: url(data:image/jpeg;base64,AAAA); background-size: cover" alt="A picture">`,
+		"</picture>",
+	}, "\n")
+	test.AssertLabel(t, "result", got, template.HTML(exp))
+	test.AssertLabel(t, "test.SafeLogEntries(hook)", test.SafeLogEntries(hook), true)
+}
+
+func TestWebpack_ReplaceResponsiveAttrs_Tags(t *testing.T) {
+	testCases := []struct {
+		input   string
+		exp     string
+		warning bool
+	}{
+		{`<p>text</p>`, `<p>text</p>`, false},
+		{`<!-- <img src="content/images/test.png"> -->`, `<!-- <img src="content/images/test.png"> -->`, false},
+		{
+			`<pre><code><img src="content/images/test.png"></code></pre><img src="test.gif">`,
+			`<pre><code><img src="content/images/test.png"></code></pre><img src="assets/test.gif">`,
+			false,
+		},
+		{
+			`<script>var s = '<img src="test.gif">';</script>`,
+			`<script>var s = '<img src="test.gif">';</script>`,
+			false,
+		},
+		{
+			`<picture><source srcset="content/images/test.png" type="image/png"><img src="test.gif" alt="a"></picture>`,
+			`<picture><source srcset="` + strings.TrimPrefix(pngResponsiveImage.HTMLAttrs(), `src="`+pngResponsiveImage.Src+`" srcset="`) +
+				` type="image/png"><img src="assets/test.gif" alt="a"></picture>`,
+			false,
+		},
+		{
+			`<source srcset="test.gif 1x, content/images/test_again.png 2x">`,
+			`<source srcset="assets/test.gif 1x, assets/content/images/test_again-1440.png 2x">`,
+			false,
+		},
+		{
+			`<video poster="test.gif" controls><source src="test.gif"></video>`,
+			`<video poster="assets/test.gif" controls=""><source src="assets/test.gif"></video>`,
+			false,
+		},
+		{
+			`<div class="hero" style="color: red; background-image: url(test.gif)"></div>`,
+			`<div class="hero" style="color: red; background-image: url(&#39;assets/test.gif&#39;)"></div>`,
+			false,
+		},
+		{
+			`<div style='background: url("http://testy.com/a.png")'></div>`,
+			`<div style="background: url(&#39;http://testy.com/a.png&#39;)"></div>`,
+			false,
+		},
+		{`<img src="missing.gif">`, `<img src="assets/missing.gif">`, true},
+		{`<img src="data:image/gif;base64,AAAA">`, `<img src="data:image/gif;base64,AAAA">`, false},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		context := test.NewContext(t).SetFields(test.ContextFields{
+			"index": testCaseIndex,
+			"input": tc.input,
+		})
+
+		webpack, hook := defaultWebpack()
+		context.Assert("result", webpack.ReplaceResponsiveAttrs("", tc.input), tc.exp)
+		context.Assert("warning", len(hook.AllEntries()) == 1, tc.warning)
+	}
+}
+
+func TestWebpack_TemplateFuncs_Strict(t *testing.T) {
+	testCases := []struct {
+		template string
+		exp      string
+	}{
+		{`{{ webpackURL "vendor.css" }}`, "assets/vendor-32267303b2484ed8b3aa.css"},
+		{`{{ webpackURL "missing.css" }}`, "assets/missing.css"},
+		{`<img {{ responsiveAttrs "missing.gif" }}>`, `<img src="assets/missing.gif">`},
+		{`{{ responsivePicture "missing.gif" }}`, "<picture>\n<img src=\"assets/missing.gif\">\n</picture>"},
+		{`{{ replaceResponsiveAttrs "" "<img src=\"missing.gif\">" }}`, `&lt;img src=&#34;assets/missing.gif&#34;&gt;`},
+		{`{{ webpackScript "missing.js" }}`, `<script src="assets/missing.js"></script>`},
+		{`{{ webpackStylesheet "vendor.css" }}`, `<link rel="stylesheet" href="assets/vendor-32267303b2484ed8b3aa.css" integrity="` + vendorIntegrity + `" crossorigin="anonymous">`},
+	}
+
+	for testCaseIndex, tc := range testCases {
+		for _, strict := range []bool{false, true} {
+			context := test.NewContext(t).SetFields(test.ContextFields{
+				"index":    testCaseIndex,
+				"template": tc.template,
+				"strict":   strict,
+			})
+
+			webpack, hook := defaultWebpack()
+			webpack.settings.Strict = strict
+			tmpl, err := template.New("test").Funcs(webpack.TemplateFuncs()).Parse(tc.template)
+			context.AssertError(err, "template.Parse")
+
+			builder := &strings.Builder{}
+			err = tmpl.Execute(builder, nil)
+			missing := strings.Contains(tc.template, "missing")
+			if strict {
+				context.Assert("err != nil", err != nil, missing)
+				context.Assert("len(hook.AllEntries())", len(hook.AllEntries()), 0)
+				continue
+			}
+			context.AssertError(err, "tmpl.Execute")
+			context.Assert("result", builder.String(), tc.exp)
+			context.Assert("test.SafeLogEntries(hook)", test.SafeLogEntries(hook), !missing)
+		}
+	}
+}
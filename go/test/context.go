@@ -69,7 +69,7 @@ func (context *Context) AssertString(label string, got, exp interface{}) string
 
 // DiffString is String() for diffs
 func (context *Context) DiffString(label string, got, exp, diff interface{}) string {
-	return context.Stringf(DiffString(label, got, exp, diff))
+	return context.String(DiffString(label, got, exp, diff))
 }
 
 // Assert is String() for diffs
@@ -7,6 +7,7 @@ package mocks
 import (
 	gomock "github.com/golang/mock/gomock"
 	logrus "github.com/sirupsen/logrus"
+	io "io"
 	reflect "reflect"
 )
 
@@ -73,6 +74,20 @@ func (mr *MockAppMockRecorder) Generate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockApp)(nil).Generate))
 }
 
+// GenerateMatching mocks base method
+func (m *MockApp) GenerateMatching(arg0 func(string) bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateMatching", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GenerateMatching indicates an expected call of GenerateMatching
+func (mr *MockAppMockRecorder) GenerateMatching(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateMatching", reflect.TypeOf((*MockApp)(nil).GenerateMatching), arg0)
+}
+
 // GeneratedPath mocks base method
 func (m *MockApp) GeneratedPath() string {
 	m.ctrl.T.Helper()
@@ -115,6 +130,48 @@ func (mr *MockAppMockRecorder) Log() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Log", reflect.TypeOf((*MockApp)(nil).Log))
 }
 
+// PrintRoutes mocks base method
+func (m *MockApp) PrintRoutes(arg0 io.Writer, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrintRoutes", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrintRoutes indicates an expected call of PrintRoutes
+func (mr *MockAppMockRecorder) PrintRoutes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintRoutes", reflect.TypeOf((*MockApp)(nil).PrintRoutes), arg0, arg1)
+}
+
+// PrintSettings mocks base method
+func (m *MockApp) PrintSettings(arg0 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrintSettings", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrintSettings indicates an expected call of PrintSettings
+func (mr *MockAppMockRecorder) PrintSettings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintSettings", reflect.TypeOf((*MockApp)(nil).PrintSettings), arg0)
+}
+
+// PrintSettingsSchema mocks base method
+func (m *MockApp) PrintSettingsSchema(arg0 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrintSettingsSchema", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrintSettingsSchema indicates an expected call of PrintSettingsSchema
+func (mr *MockAppMockRecorder) PrintSettingsSchema(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrintSettingsSchema", reflect.TypeOf((*MockApp)(nil).PrintSettingsSchema), arg0)
+}
+
 // RunFileServer mocks base method
 func (m *MockApp) RunFileServer() error {
 	m.ctrl.T.Helper()
@@ -142,3 +199,15 @@ func (mr *MockAppMockRecorder) ServerPort() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServerPort", reflect.TypeOf((*MockApp)(nil).ServerPort))
 }
+
+// SetLogLevel mocks base method
+func (m *MockApp) SetLogLevel(arg0 logrus.Level) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLogLevel", arg0)
+}
+
+// SetLogLevel indicates an expected call of SetLogLevel
+func (mr *MockAppMockRecorder) SetLogLevel(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLogLevel", reflect.TypeOf((*MockApp)(nil).SetLogLevel), arg0)
+}